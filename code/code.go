@@ -19,7 +19,11 @@ const (
 	ActorSourceEqualTarget  int32 = 30 // source equal target
 	ActorPublishRemoteError int32 = 31 // actor publish remote error
 	ActorChildIDNotFound    int32 = 32 // actor child id not found
-
+	ActorMailboxFull        int32 = 33 // actor mailbox is full
+	ActorCallTimeout        int32 = 34 // actor call wait timeout
+	ActorCallCanceled       int32 = 35 // actor call wait canceled
+	ActorMigrateFail        int32 = 36 // actor migrate fail
+	RPCCircuitOpen          int32 = 37 // rpc target node circuit breaker is open
 )
 
 func IsOK(code int32) bool {
@@ -0,0 +1,139 @@
+package cherryGORM
+
+import (
+	"errors"
+
+	cactor "github.com/cherry-game/cherry/net/actor"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultStateTable   = "actor_state"
+	defaultJournalTable = "actor_journal"
+)
+
+type (
+	// StateStore 基于gorm的actor状态存储,实现了cherryActor.IStateStore及cherryActor.IJournalStore
+	StateStore struct {
+		db           *gorm.DB
+		stateTable   string
+		journalTable string
+	}
+
+	stateRow struct {
+		StateKey  string `gorm:"primaryKey;size:191"`
+		Data      []byte
+		UpdatedAt int64 `gorm:"autoUpdateTime:milli"`
+	}
+
+	journalRow struct {
+		StateKey string `gorm:"primaryKey;size:191"`
+		Seq      int64  `gorm:"primaryKey;autoIncrement:false"`
+		Name     string `gorm:"size:64"`
+		Data     []byte
+	}
+)
+
+// NewStateStore 创建状态存储,表名为空时使用默认表名(actor_state, actor_journal),并自动建表
+func NewStateStore(db *gorm.DB, tableNames ...string) (*StateStore, error) {
+	store := &StateStore{
+		db:           db,
+		stateTable:   defaultStateTable,
+		journalTable: defaultJournalTable,
+	}
+
+	if len(tableNames) > 0 && tableNames[0] != "" {
+		store.stateTable = tableNames[0]
+	}
+
+	if len(tableNames) > 1 && tableNames[1] != "" {
+		store.journalTable = tableNames[1]
+	}
+
+	if err := db.Table(store.stateTable).AutoMigrate(&stateRow{}); err != nil {
+		return nil, err
+	}
+
+	if err := db.Table(store.journalTable).AutoMigrate(&journalRow{}); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (p *StateStore) Load(key string) ([]byte, error) {
+	row := &stateRow{}
+	err := p.db.Table(p.stateTable).Where("state_key = ?", key).Take(row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return row.Data, err
+}
+
+func (p *StateStore) Save(key string, data []byte) error {
+	row := &stateRow{
+		StateKey: key,
+		Data:     data,
+	}
+
+	return p.db.Table(p.stateTable).Clauses(clause.OnConflict{UpdateAll: true}).Create(row).Error
+}
+
+func (p *StateStore) Delete(key string) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table(p.stateTable).Where("state_key = ?", key).Delete(&stateRow{}).Error; err != nil {
+			return err
+		}
+		return tx.Table(p.journalTable).Where("state_key = ?", key).Delete(&journalRow{}).Error
+	})
+}
+
+func (p *StateStore) Append(key string, entries ...cactor.JournalEntry) error {
+	if len(entries) < 1 {
+		return nil
+	}
+
+	rows := make([]*journalRow, 0, len(entries))
+	for _, entry := range entries {
+		rows = append(rows, &journalRow{
+			StateKey: key,
+			Seq:      entry.Seq,
+			Name:     entry.Name,
+			Data:     entry.Data,
+		})
+	}
+
+	return p.db.Table(p.journalTable).Create(rows).Error
+}
+
+func (p *StateStore) Replay(key string, fromSeq int64, fn func(entry cactor.JournalEntry) error) error {
+	var rows []*journalRow
+	err := p.db.Table(p.journalTable).
+		Where("state_key = ? AND seq > ?", key, fromSeq).
+		Order("seq").
+		Find(&rows).Error
+
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		entry := cactor.JournalEntry{
+			Seq:  row.Seq,
+			Name: row.Name,
+			Data: row.Data,
+		}
+
+		if err = fn(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *StateStore) Truncate(key string, toSeq int64) error {
+	return p.db.Table(p.journalTable).Where("state_key = ? AND seq <= ?", key, toSeq).Delete(&journalRow{}).Error
+}
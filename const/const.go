@@ -29,4 +29,5 @@ func Version() string {
 
 const (
 	DOT = "." //ActorPath的分隔符
+	AT  = "@" //按节点类型寻址的前缀,如 @web.auth 投递到任一web类型的节点
 )
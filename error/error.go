@@ -118,6 +118,7 @@ var (
 var (
 	ClusterRPCClientIsStop = Error("rpc client is stop")
 	ClusterNoImplement     = Error("no implement")
+	ClusterCircuitOpen     = Error("circuit breaker is open")
 	NodeTypeIsNil          = Error("node type is nil.")
 )
 
@@ -1,8 +1,18 @@
 package event
 
+import (
+	cfacade "github.com/cherry-game/cherry/facade"
+	cserializer "github.com/cherry-game/cherry/net/serializer"
+)
+
 var (
 	NoneEventKey    = "none_event"    // 未使用
 	PlayerLoginKey  = "player_login"  // 角色登陆事件
 	PlayerLogoutKey = "player_logout" // 角色登出事件
 	PlayerCreateKey = "player_create" // 角色创建事件
 )
+
+var (
+	// clusterSerializer 跨节点发布的事件使用json序列化(应用默认的protobuf序列化器只支持proto.Message)
+	clusterSerializer cfacade.ISerializer = cserializer.NewJSON()
+)
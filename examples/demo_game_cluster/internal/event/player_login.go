@@ -1,5 +1,9 @@
 package event
 
+import (
+	cfacade "github.com/cherry-game/cherry/facade"
+)
+
 type PlayerLogin struct {
 	ActorId         string // actor id
 	PlayerId        int64  // player id
@@ -23,3 +27,12 @@ func (PlayerLogin) Name() string {
 func (p PlayerLogin) UniqueId() int64 {
 	return p.PlayerId
 }
+
+// Scope 同时发布到center节点
+func (PlayerLogin) Scope() (cfacade.EventScope, string) {
+	return cfacade.NodeTypeEventScope, "center"
+}
+
+func (PlayerLogin) Serializer() cfacade.ISerializer {
+	return clusterSerializer
+}
@@ -1,5 +1,9 @@
 package event
 
+import (
+	cfacade "github.com/cherry-game/cherry/facade"
+)
+
 type PlayerLogout struct {
 	ActorId  string // actor id
 	PlayerId int64  // player id
@@ -20,3 +24,12 @@ func (PlayerLogout) Name() string {
 func (p PlayerLogout) UniqueId() int64 {
 	return p.PlayerId
 }
+
+// Scope 同时发布到center节点
+func (PlayerLogout) Scope() (cfacade.EventScope, string) {
+	return cfacade.NodeTypeEventScope, "center"
+}
+
+func (PlayerLogout) Serializer() cfacade.ISerializer {
+	return clusterSerializer
+}
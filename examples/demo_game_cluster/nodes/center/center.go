@@ -4,9 +4,11 @@ import (
 	"github.com/cherry-game/cherry"
 	cherryCron "github.com/cherry-game/cherry/components/cron"
 	"github.com/cherry-game/cherry/examples/demo_game_cluster/internal/data"
+	"github.com/cherry-game/cherry/examples/demo_game_cluster/internal/event"
 	"github.com/cherry-game/cherry/examples/demo_game_cluster/nodes/center/db"
 	"github.com/cherry-game/cherry/examples/demo_game_cluster/nodes/center/module/account"
 	"github.com/cherry-game/cherry/examples/demo_game_cluster/nodes/center/module/ops"
+	cfacade "github.com/cherry-game/cherry/facade"
 )
 
 func Run(profileFilePath, nodeId string) {
@@ -21,6 +23,14 @@ func Run(profileFilePath, nodeId string) {
 	app.Register(data.New())
 	app.Register(db.New())
 
+	// 接收game节点发布的玩家登录、登出事件
+	app.ActorSystem().RegisterEvent(func() cfacade.IEventData {
+		return &event.PlayerLogin{}
+	})
+	app.ActorSystem().RegisterEvent(func() cfacade.IEventData {
+		return &event.PlayerLogout{}
+	})
+
 	app.AddActors(
 		&account.ActorAccount{},
 		&ops.ActorOps{},
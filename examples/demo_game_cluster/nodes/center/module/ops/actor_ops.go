@@ -2,7 +2,10 @@ package ops
 
 import (
 	"github.com/cherry-game/cherry/examples/demo_game_cluster/internal/code"
+	"github.com/cherry-game/cherry/examples/demo_game_cluster/internal/event"
 	"github.com/cherry-game/cherry/examples/demo_game_cluster/internal/pb"
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
 	cactor "github.com/cherry-game/cherry/net/actor"
 )
 
@@ -23,9 +26,18 @@ func (p *ActorOps) AliasID() string {
 // OnInit 注册remote函数
 func (p *ActorOps) OnInit() {
 	p.Remote().Register("ping", p.ping)
+
+	// game节点发布的玩家登录、登出事件
+	p.Event().Register(event.PlayerLoginKey, p.onPlayerEvent)
+	p.Event().Register(event.PlayerLogoutKey, p.onPlayerEvent)
 }
 
 // ping 请求center是否响应
 func (p *ActorOps) ping() (*pb.Bool, int32) {
 	return pingReturn, code.OK
 }
+
+// onPlayerEvent 记录玩家登录、登出
+func (p *ActorOps) onPlayerEvent(e cfacade.IEventData) {
+	clog.Infof("[PlayerEvent] [name = %s, playerId = %d]", e.Name(), e.UniqueId())
+}
@@ -17,6 +17,7 @@ type FuncInfo struct {
 	InArgsLen  int
 	OutArgs    []reflect.Type
 	OutArgsLen int
+	Invoker    interface{} // 自定义调用器(如泛型注册的函数),为nil时使用反射调用
 }
 
 func GetFuncInfo(fn interface{}) (FuncInfo, error) {
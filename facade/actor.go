@@ -1,6 +1,7 @@
 package cherryFacade
 
 import (
+	"context"
 	"time"
 
 	creflect "github.com/cherry-game/cherry/extend/reflect"
@@ -10,11 +11,13 @@ type (
 	IActorSystem interface {
 		GetIActor(id string) (IActor, bool)
 		CreateActor(id string, handler IActorHandler) (IActor, error)
-		PostRemote(m *Message) bool
-		PostLocal(m *Message) bool
+		PostRemote(m *Message) int32
+		PostLocal(m *Message) int32
 		PostEvent(data IEventData)
+		RegisterEvent(newEvent func() IEventData)
 		Call(source, target, funcName string, arg interface{}) int32
 		CallWait(source, target, funcName string, arg interface{}, reply interface{}) int32
+		CallWaitContext(ctx context.Context, source, target, funcName string, arg interface{}, reply interface{}) int32
 		SetLocalInvoke(invoke InvokeFunc)
 		SetRemoteInvoke(invoke InvokeFunc)
 		SetCallTimeout(d time.Duration)
@@ -30,8 +33,9 @@ type (
 		Path() *ActorPath
 		Call(targetPath, funcName string, arg interface{}) int32
 		CallWait(targetPath, funcName string, arg interface{}, reply interface{}) int32
-		PostRemote(m *Message)
-		PostLocal(m *Message)
+		CallAsync(targetPath, funcName string, arg, reply interface{}, timeout time.Duration, fn func(reply interface{}, code int32))
+		PostRemote(m *Message) int32
+		PostLocal(m *Message) int32
 		LastAt() int64
 		Exit()
 	}
@@ -53,9 +57,29 @@ type (
 	}
 )
 
+const (
+	LocalEventScope    EventScope = 0 // 只在本节点发布
+	NodeTypeEventScope EventScope = 1 // 发布到本节点及指定类型的节点
+	ClusterEventScope  EventScope = 2 // 发布到集群所有节点
+)
+
 type (
 	IEventData interface {
 		Name() string    // 事件名
 		UniqueId() int64 // 唯一id
 	}
+
+	EventScope int
+
+	// IEventScope 事件的发布范围,未实现该接口的事件只在本节点发布
+	// 跨节点发布的事件需要在接收节点通过IActorSystem.RegisterEvent()注册
+	IEventScope interface {
+		Scope() (scope EventScope, nodeType string) // nodeType仅在NodeTypeEventScope时有效
+	}
+
+	// IEventSerializer 跨节点发布事件时使用的序列化器,未实现该接口则使用IApplication.Serializer()
+	// 应用使用protobuf序列化时,非proto.Message的事件需实现该接口(如返回json序列化器)
+	IEventSerializer interface {
+		Serializer() ISerializer
+	}
 )
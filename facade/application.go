@@ -12,7 +12,7 @@ type (
 		NodeId() string        // 节点id(全局唯一)
 		NodeType() string      // 节点类型
 		Address() string       // 对外网络监听地址(前端节点用)
-		RpcAddress() string    // rpc监听地址(未用)
+		RpcAddress() string    // rpc监听地址(tcp集群模式使用)
 		Settings() ProfileJSON // 节点配置参数
 		Enabled() bool         // 是否启用
 	}
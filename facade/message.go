@@ -23,7 +23,9 @@ type (
 		Err          error            // 返回的错误
 		ClusterReply IRespond         // 返回消息的接口
 		IsCluster    bool             // 是否为集群消息
-		ChanResult   chan interface{} //
+		ChanResult   chan interface{} // 同步调用的返回结果(需带缓冲,调用方超时后迟到的回复会被丢弃)
+		TraceParent  string           // W3C traceparent
+		MessageID    string           // 幂等键(可靠投递的集群消息),重复投递时不变
 	}
 
 	IRespond interface {
@@ -90,6 +92,11 @@ func (p *ActorPath) IsParent() bool {
 	return p.ChildID == ""
 }
 
+// NodeType 按节点类型寻址(如 @web.auth)时返回节点类型
+func (p *ActorPath) NodeType() (string, bool) {
+	return NodeTypeOf(p.NodeID)
+}
+
 // String
 func (p *ActorPath) String() string {
 	return NewChildPath(p.NodeID, p.ActorID, p.ChildID)
@@ -114,6 +121,19 @@ func NewPath(nodeID, actorID interface{}) string {
 	return cstring.ToString(nodeID) + cconst.DOT + cstring.ToString(actorID)
 }
 
+// NewTypePath 按节点类型寻址的路径,消息投递到该类型中任一存活的节点
+func NewTypePath(nodeType, actorID string) string {
+	return NewPath(cconst.AT+nodeType, actorID)
+}
+
+// NodeTypeOf nodeID为 @nodeType 形式时返回节点类型
+func NodeTypeOf(nodeID string) (string, bool) {
+	if len(nodeID) > 1 && strings.HasPrefix(nodeID, cconst.AT) {
+		return nodeID[1:], true
+	}
+	return "", false
+}
+
 func ToActorPath(path string) (*ActorPath, error) {
 	if path == "" {
 		return nil, cerr.ActorPathError
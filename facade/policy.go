@@ -0,0 +1,71 @@
+package cherryFacade
+
+import (
+	"context"
+	"time"
+
+	cproto "github.com/cherry-game/cherry/net/proto"
+)
+
+const (
+	AnyNodeType = "*" // 匹配所有节点类型的策略
+	AnyFunc     = "*" // 所有函数均为幂等函数
+)
+
+type (
+	// Policy 跨节点调用策略
+	Policy struct {
+		Retries          int           // RPCNetError(网络错误及超时)后的重试次数,仅对Idempotent中的函数生效
+		Backoff          time.Duration // 首次重试前的等待时间,之后每次翻倍
+		MaxBackoff       time.Duration // 重试等待时间的上限,0为不限制
+		Idempotent       []string      // 幂等函数名,可重试及对冲请求,AnyFunc表示所有函数
+		BreakerThreshold int           // 节点连续RPCNetError的次数达到该值时熔断,0为不熔断
+		BreakerCooldown  time.Duration // 熔断后的冷却时间,之后放行一个探测请求,成功则恢复
+		HedgeDelay       time.Duration // 幂等函数超过该时间未返回时,向同类型的另一个节点发送对冲请求,0为不开启
+		Stateless        []string      // 无状态的actorID,指定节点的请求可对冲到同类型的其他节点(有状态actor只在 @nodeType 寻址时对冲)
+	}
+
+	// Policies 调用策略,key:目标节点类型(AnyNodeType匹配所有类型)
+	Policies map[string]*Policy
+
+	// IPolicyCluster 支持按调用指定策略的集群,policies优先于集群配置的策略
+	IPolicyCluster interface {
+		PublishRemoteWithPolicy(policies Policies, nodeId string, packet *cproto.ClusterPacket) error
+		RequestRemoteWithPolicy(policies Policies, nodeId string, packet *cproto.ClusterPacket, timeout ...time.Duration) cproto.Response
+	}
+
+	// IAvailable 节点是否可用,发现服务随机选择节点时跳过不可用(熔断中)的节点
+	IAvailable interface {
+		Available(nodeId string) bool
+	}
+
+	policiesKey struct{}
+)
+
+// Get 获取目标节点类型的策略
+func (p Policies) Get(nodeType string) (*Policy, bool) {
+	if policy, found := p[nodeType]; found && policy != nil {
+		return policy, true
+	}
+
+	policy, found := p[AnyNodeType]
+	return policy, found && policy != nil
+}
+
+// WithPolicies 保存调用策略到ctx,优先于集群配置的策略
+func WithPolicies(ctx context.Context, policies Policies) context.Context {
+	if len(policies) < 1 {
+		return ctx
+	}
+	return context.WithValue(ctx, policiesKey{}, policies)
+}
+
+// PoliciesFromContext 获取ctx中的调用策略
+func PoliciesFromContext(ctx context.Context) Policies {
+	if ctx == nil {
+		return nil
+	}
+
+	policies, _ := ctx.Value(policiesKey{}).(Policies)
+	return policies
+}
@@ -1,9 +1,14 @@
 package cherryActor
 
 import (
+	"context"
+	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	ccode "github.com/cherry-game/cherry/code"
 	cutils "github.com/cherry-game/cherry/extend/utils"
 	cfacade "github.com/cherry-game/cherry/facade"
 	clog "github.com/cherry-game/cherry/logger"
@@ -14,6 +19,7 @@ import (
 - 每个Actor独立运行在一个goroutine中，所有的逻辑都是串行处理
 - Actor接收三种消息：本地消息(Local)、远程消息(Remote)、事件消息(Event)
 	- 三种消息都有自己的队列(Queue)，每个队列依据FIFO原则进行消费
+	- 内部函数等系统消息(System)有独立的队列，按通道优先级(默认System>Remote>Local>Event)处理
 	- 本地消息(Local)，用于接收游戏客户端发送过来的本地消息
 	- 远程消息(Remote)，用于Actor之间调用的远程消息
 	- 事件消息(Event)，通过订阅/发布进行的事件消息
@@ -22,8 +28,8 @@ import (
 - 通过cluster集群组件、discovery发现服务组件，进行跨节点的actor通信
 */
 
-var (
-	_nilActor = Actor{}
+const (
+	asyncReplyFuncName = "_asyncReply_"
 )
 
 var (
@@ -39,18 +45,38 @@ type (
 	Actor struct {
 		system           *System               // actor system
 		path             *cfacade.ActorPath    // actor path
-		state            State                 // actor state
+		state            int32                 // actor state(State,跨goroutine读取需使用State())
 		close            chan struct{}         // close flag
 		handler          cfacade.IActorHandler // actor handler
 		localMail        *mailbox              // local message mailbox
 		remoteMail       *mailbox              // remote message mailbox
+		systemMail       *mailbox              // system message mailbox
+		lanes            []Lane                // 消息通道优先级
+		currentLane      Lane                  // 正在处理的消息所属通道
+		systemFuncs      sync.Map              // 走系统通道的远程函数名
+		stash            []stashMessage        // 暂存的消息
+		unstashed        []stashMessage        // 已恢复待处理的暂存消息
+		behavior         *Behavior             // 切换后的执行函数
 		event            *actorEvent           // event
 		child            *actorChild           // child actor
 		timer            *actorTimer           // timer
-		lastAt           int64                 // last process time
+		supervisor       *actorSupervisor      // supervisor
+		lastAt           int64                 // last process time(ms)
+		migratedTo       atomic.Value          // 迁移后所在的节点id(string)
+		interceptors     []Interceptor         // 函数调用拦截器
+		typeName         string                // handler类型名(指标label)
+		traceParent      atomic.Value          // 正在处理的消息的traceparent(string)
+		messageID        string                // 正在处理的消息的幂等键
+		callPolicies     cfacade.Policies      // 跨节点调用策略(key:目标节点类型)
 		arrivalElapsed   int64                 // arrival elapsed for message
 		executionElapsed int64                 // execution elapsed for message
 	}
+
+	asyncReply struct {
+		fn    func(reply interface{}, code int32)
+		reply interface{}
+		code  int32
+	}
 )
 
 func (p *Actor) run() {
@@ -65,30 +91,29 @@ func (p *Actor) run() {
 }
 
 func (p *Actor) loop() bool {
-	if p.state == StopState {
-		if p.localMail.Count() < 1 &&
-			p.remoteMail.Count() < 1 &&
-			p.event.Count() < 1 {
-			return true
-		}
+	select {
+	case <-p.close:
+		p.setState(StopState)
+	default:
+	}
+
+	// 按通道优先级处理消息
+	if p.processNext() {
+		return false
+	}
+
+	if p.State() == StopState {
+		return true
 	}
 
 	select {
+	case <-p.systemMail.C:
 	case <-p.localMail.C:
-		{
-			p.processLocal()
-		}
 	case <-p.remoteMail.C:
-		{
-			p.processRemote()
-		}
 	case <-p.event.C:
-		{
-			p.processEvent()
-		}
 	case <-p.close:
 		{
-			p.state = StopState
+			p.setState(StopState)
 		}
 	}
 
@@ -101,7 +126,17 @@ func (p *Actor) processLocal() {
 		return
 	}
 
-	p.lastAt = time.Now().Unix()
+	p.receiveLocal(m)
+}
+
+func (p *Actor) receiveLocal(m *cfacade.Message) {
+	p.currentLane = LocalLane
+	p.touch(m.FuncName)
+
+	if p.MigratedTo() != "" {
+		p.forwardMigrated(m, true)
+		return
+	}
 
 	next, invoke := p.handler.OnLocalReceived(m)
 	if invoke {
@@ -118,8 +153,11 @@ func (p *Actor) processLocal() {
 		} else {
 			if childActor, foundChild := p.findChildActor(m); foundChild {
 				childActor.PostLocal(m)
+			} else if nodeID, forwarded := p.system.getForward(m.TargetPath()); forwarded {
+				p.system.forward(m, nodeID, true)
 			} else {
 				clog.Warnf("Child actor not found. path = %s", m.Target)
+				p.system.deadLetter(m, true, ChildNotFoundReason)
 			}
 		}
 	} else {
@@ -133,7 +171,27 @@ func (p *Actor) processRemote() {
 		return
 	}
 
-	p.lastAt = time.Now().Unix()
+	p.receiveRemote(m, RemoteLane)
+}
+
+// processSystem 系统通道的消息使用remote邮箱注册的函数执行
+func (p *Actor) processSystem() {
+	m := p.systemMail.Pop()
+	if m == nil {
+		return
+	}
+
+	p.receiveRemote(m, SystemLane)
+}
+
+func (p *Actor) receiveRemote(m *cfacade.Message, lane Lane) {
+	p.currentLane = lane
+	p.touch(m.FuncName)
+
+	if p.MigratedTo() != "" {
+		p.forwardMigrated(m, false)
+		return
+	}
 
 	next, invoke := p.handler.OnRemoteReceived(m)
 	if invoke {
@@ -150,8 +208,11 @@ func (p *Actor) processRemote() {
 		} else {
 			if childActor, foundChild := p.findChildActor(m); foundChild {
 				childActor.PostRemote(m)
+			} else if nodeID, forwarded := p.system.getForward(m.TargetPath()); forwarded {
+				p.system.forward(m, nodeID, false)
 			} else {
 				clog.Warnf("Child actor not found. path = %s", m.Target)
+				p.system.deadLetter(m, false, ChildNotFoundReason)
 			}
 		}
 	} else {
@@ -165,7 +226,9 @@ func (p *Actor) processEvent() {
 		return
 	}
 
-	p.lastAt = time.Now().Unix()
+	p.currentLane = EventLane
+	p.touch(eventData.Name())
+	p.system.metrics.Load().observeEvent(p)
 	p.event.funcInvoke(eventData)
 }
 
@@ -176,7 +239,7 @@ func (p *Actor) invokeFunc(
 	m *cfacade.Message,
 	actor cfacade.IActor,
 ) {
-	funcInfo, found := mb.funcMap[m.FuncName]
+	funcInfo, found := p.findFunc(mb, m.FuncName)
 	if !found {
 		clog.Warnf("[%s] Function not found. [source = %s, target = %s -> %s]",
 			mb.name,
@@ -184,11 +247,14 @@ func (p *Actor) invokeFunc(
 			m.Target,
 			m.FuncName,
 		)
+		p.system.deadLetter(m, mb.name == LocalName, FuncNotFoundReason)
 		return
 	}
 
-	p.arrivalElapsed = m.PostTime - m.BuildTime
-	if p.arrivalElapsed > p.system.arrivalTimeOut {
+	arrivalElapsed := m.PostTime - m.BuildTime
+	atomic.StoreInt64(&p.arrivalElapsed, arrivalElapsed)
+	p.system.metrics.Load().observeArrival(p, mb.name, m.FuncName, time.Duration(arrivalElapsed)*time.Millisecond)
+	if arrivalElapsed > p.system.arrivalTimeOut {
 		clog.Warnf("[%s] Invoke timeout.[path = %s -> %s -> %s, postTime = %d, buildTime = %d, arrival = %dms]",
 			mb.name,
 			m.Source,
@@ -196,36 +262,49 @@ func (p *Actor) invokeFunc(
 			m.FuncName,
 			m.PostTime,
 			m.BuildTime,
-			p.arrivalElapsed,
+			arrivalElapsed,
 		)
 	}
 
-	now := time.Now().UnixMilli()
+	span := p.startInvokeSpan(mb, m)
+	p.messageID = m.MessageID
+	begin := time.Now()
 
 	defer func() {
-		p.executionElapsed = time.Now().UnixMilli() - now
-		if p.executionElapsed > p.system.executionTimeout {
+		defer p.endInvokeSpan(span)
+		p.messageID = ""
+
+		elapsed := time.Since(begin)
+		executionElapsed := elapsed.Milliseconds()
+		atomic.StoreInt64(&p.executionElapsed, executionElapsed)
+		p.system.metrics.Load().observeExecution(p, mb.name, m.FuncName, elapsed)
+		if executionElapsed > p.system.executionTimeout {
 			clog.Warnf("[%s] Invoke timeout.[source = %s, target = %s->%s, execution = %dms]",
 				mb.name,
 				m.Source,
 				m.Target,
 				m.FuncName,
-				p.executionElapsed,
+				executionElapsed,
 			)
 		}
 
 		if rev := recover(); rev != nil {
-			clog.Errorf("[%s] Invoke error. [source = %s, target = %s->%s, type = %v]",
+			clog.Errorf("[%s] Invoke error. [source = %s, target = %s->%s, type = %v, err = %v]",
 				mb.name,
 				m.Source,
 				m.Target,
 				m.FuncName,
 				funcInfo.InArgs,
+				rev,
 			)
+
+			p.system.metrics.Load().observePanic(p)
+			span.SetError(fmt.Sprint(rev))
+			p.onFailure(rev)
 		}
 	}()
 
-	fn(app, funcInfo, m, actor)
+	p.invokeWithInterceptors(mb, app, fn, funcInfo, m, actor)
 }
 
 func (p *Actor) findChildActor(m *cfacade.Message) (*Actor, bool) {
@@ -241,6 +320,10 @@ func (p *Actor) findChildActor(m *cfacade.Message) (*Actor, bool) {
 	// 寻找childActor
 	childActor, found := p.child.Get(m.TargetPath().ChildID)
 	if !found {
+		// 子actor已迁移到其他节点
+		if _, forwarded := p.system.getForward(m.TargetPath()); forwarded {
+			return nil, false
+		}
 		childActor, found = p.handler.OnFindChild(m)
 	}
 
@@ -253,9 +336,29 @@ func (p *Actor) findChildActor(m *cfacade.Message) (*Actor, bool) {
 	return nil, false
 }
 
+// registerSystemFunc 注册actor内部使用的函数
+func (p *Actor) registerSystemFunc() {
+	p.remoteMail.Register(updateTimerFuncName, p.timer._updateTimer_)
+	p.remoteMail.Register(restartFuncName, p.supervisor._restart_)
+	p.remoteMail.Register(childFailedFuncName, p.supervisor._childFailed_)
+	p.remoteMail.Register(asyncReplyFuncName, p._asyncReply_)
+	p.remoteMail.Register(passivateFuncName, p._passivate_)
+	p.remoteMail.Register(rehomeFuncName, p._rehome_)
+	p.remoteMail.Register(migrateFuncName, p._migrate_)
+	p.remoteMail.Register(restoreFuncName, p._restore_)
+	p.remoteMail.Register(recoverFuncName, p._recover_)
+	p.remoteMail.Register(releaseFuncName, p._release_)
+}
+
+// _asyncReply_ 执行CallAsync的回调函数
+func (p *Actor) _asyncReply_(r *asyncReply) {
+	r.fn(r.reply, r.code)
+}
+
 func (p *Actor) onInit() {
-	p.state = WorkerState
+	p.setState(WorkerState)
 	p.handler.OnInit()
+	p.loadState()
 }
 
 func (p *Actor) onStop() {
@@ -272,10 +375,14 @@ func (p *Actor) onStop() {
 		}
 
 		p.handler.OnStop()
+		p.discardStash()
+		p.flushState()
+		p.supervisor.onStop()
 		p.timer.onStop()
 		p.event.onStop()
 		p.localMail.onStop()
 		p.remoteMail.onStop()
+		p.systemMail.onStop()
 	}, func(errString string) {
 		clog.Error(errString)
 	})
@@ -284,7 +391,17 @@ func (p *Actor) onStop() {
 }
 
 func (p *Actor) State() State {
-	return p.state
+	return State(atomic.LoadInt32(&p.state))
+}
+
+func (p *Actor) setState(state State) {
+	atomic.StoreInt32(&p.state, int32(state))
+}
+
+// canPost 是否可以投递消息(初始化中的actor先缓存消息,启动后处理)
+func (p *Actor) canPost() bool {
+	state := p.State()
+	return state == InitState || state == WorkerState
 }
 
 func (p *Actor) App() cfacade.IApplication {
@@ -308,16 +425,75 @@ func (p *Actor) PathString() string {
 }
 
 func (p *Actor) Call(targetPath, funcName string, arg interface{}) int32 {
-	return p.system.Call(p.path.String(), targetPath, funcName, arg)
+	return p.system.call(p.callContext(context.Background()), p.path.String(), targetPath, funcName, arg)
 }
 
 func (p *Actor) CallWait(targetPath, funcName string, arg interface{}, reply interface{}) int32 {
-	return p.system.CallWait(p.path.String(), targetPath, funcName, arg, reply)
+	return p.system.CallWaitContext(p.callContext(context.Background()), p.path.String(), targetPath, funcName, arg, reply)
+}
+
+func (p *Actor) CallWaitContext(ctx context.Context, targetPath, funcName string, arg interface{}, reply interface{}) int32 {
+	return p.system.CallWaitContext(p.callContext(ctx), p.path.String(), targetPath, funcName, arg, reply)
+}
+
+// CallAsync 发送远程消息(不阻塞当前actor)
+// 收到回复或超时后,fn会作为消息投递到当前actor的邮箱中串行执行
+// reply为接收回复数据的对象,timeout<=0时使用System的callTimeout
+func (p *Actor) CallAsync(targetPath, funcName string, arg, reply interface{}, timeout time.Duration, fn func(reply interface{}, code int32)) {
+	source := p.path.String()
+	callCtx := p.callContext(context.Background())
+
+	call := func() {
+		ctx, cancel := callCtx, func() {}
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+		}
+
+		code := p.system.CallWaitContext(ctx, source, targetPath, funcName, arg, reply)
+		cancel()
+
+		if fn == nil {
+			return
+		}
+
+		message := cfacade.GetMessage()
+		message.Source = source
+		message.Target = source
+		message.FuncName = asyncReplyFuncName
+		message.Args = &asyncReply{
+			fn:    fn,
+			reply: reply,
+			code:  code,
+		}
+
+		if rspCode := p.system.PostRemote(&message); ccode.IsFail(rspCode) {
+			clog.Warnf("[CallAsync] Post reply fail. [source = %s, target = %s -> %s, code = %d]",
+				source,
+				targetPath,
+				funcName,
+				rspCode,
+			)
+		}
+	}
+
+	// 手动驱动模式下同步等待,保证执行顺序确定
+	if p.system.manualRun {
+		call()
+		return
+	}
+
+	go call()
+}
+
+// MessageID 当前正在处理的消息的幂等键(仅可靠投递的集群消息携带),
+// 消息被重复投递时该值不变,可用于函数内去重
+func (p *Actor) MessageID() string {
+	return p.messageID
 }
 
 // LastAt second
 func (p *Actor) LastAt() int64 {
-	return p.lastAt
+	return atomic.LoadInt64(&p.lastAt) / 1000
 }
 
 func (p *Actor) Exit() {
@@ -352,35 +528,49 @@ func (p *Actor) Timer() ITimer {
 	return p.timer
 }
 
-func (p *Actor) PostRemote(m *cfacade.Message) {
-	p.remoteMail.Push(m)
+func (p *Actor) PostRemote(m *cfacade.Message) int32 {
+	// 系统消息不受邮箱容量限制
+	if p.isSystemMessage(m) {
+		return p.systemMail.Push(m)
+	}
+
+	code := p.remoteMail.Push(m)
+	if code == ccode.ActorMailboxFull {
+		p.system.deadLetter(m, false, MailboxFullReason)
+	}
+	return code
 }
 
-func (p *Actor) PostLocal(m *cfacade.Message) {
-	p.localMail.Push(m)
+func (p *Actor) PostLocal(m *cfacade.Message) int32 {
+	code := p.localMail.Push(m)
+	if code == ccode.ActorMailboxFull {
+		p.system.deadLetter(m, true, MailboxFullReason)
+	}
+	return code
 }
 
 func (p *Actor) PostEvent(data cfacade.IEventData) {
 	p.system.PostEvent(data)
 }
 
-func newActor(actorID, childID string, handler cfacade.IActorHandler, c *System) (Actor, error) {
+func newActor(actorID, childID string, handler cfacade.IActorHandler, c *System) (*Actor, error) {
 	if strings.TrimSpace(actorID) == "" {
 		clog.Error("[newActor] actor id is nil.")
-		return _nilActor, ErrActorIDIsNil
+		return nil, ErrActorIDIsNil
 	}
 
-	thisActor := Actor{
+	thisActor := &Actor{
 		path: &cfacade.ActorPath{
 			NodeID:  c.NodeId(),
 			ActorID: actorID,
 			ChildID: childID,
 		},
-		state:   InitState,
-		system:  c,
-		close:   make(chan struct{}, 1),
-		handler: handler,
-		lastAt:  time.Now().Unix(),
+		state:    int32(InitState),
+		system:   c,
+		close:    make(chan struct{}, 1),
+		handler:  handler,
+		lastAt:   time.Now().UnixMilli(),
+		typeName: actorTypeName(handler),
 	}
 
 	localMailbox := newMailbox(LocalName)
@@ -389,17 +579,42 @@ func newActor(actorID, childID string, handler cfacade.IActorHandler, c *System)
 	remoteMailbox := newMailbox(RemoteName)
 	thisActor.remoteMail = &remoteMailbox
 
-	event := newEvent(&thisActor)
+	systemMailbox := newMailbox(SystemName)
+	thisActor.systemMail = &systemMailbox
+
+	thisActor.lanes = c.lanes
+	if lanePriority, ok := handler.(IActorLanePriority); ok {
+		thisActor.lanes = normalizeLanes(lanePriority.LanePriority())
+	}
+
+	event := newEvent(thisActor)
 	thisActor.event = &event
 
-	child := newChild(&thisActor)
+	mailboxOptions := c.mailboxOptions
+	if actorOptions, ok := handler.(IActorMailboxOptions); ok {
+		mailboxOptions = actorOptions.MailboxOptions()
+	}
+	thisActor.localMail.setOptions(mailboxOptions)
+	thisActor.remoteMail.setOptions(mailboxOptions)
+	thisActor.localMail.onDrop = c.dropFunc(true)
+	thisActor.remoteMail.onDrop = c.dropFunc(false)
+	thisActor.event.setOptions(mailboxOptions)
+
+	if passivation, ok := handler.(IActorPassivation); ok && passivation.IdleTimeout() > 0 {
+		c.startIdleScan()
+	}
+
+	child := newChild(thisActor)
 	thisActor.child = &child
 
-	timer := newTimer(&thisActor)
+	timer := newTimer(thisActor)
 	thisActor.timer = &timer
 
-	// register update timer func
-	thisActor.remoteMail.Register(updateTimerFuncName, thisActor.timer._updateTimer_)
+	supervisor := newSupervisor(thisActor)
+	thisActor.supervisor = &supervisor
+
+	// register system func
+	thisActor.registerSystemFunc()
 
 	// spawn load!
 	actorLoad, ok := handler.(IActorLoader)
@@ -1,14 +1,16 @@
 package cherryActor
 
 import (
+	"time"
+
 	cfacade "github.com/cherry-game/cherry/facade"
 )
 
 type Base struct {
-	Actor
+	*Actor
 }
 
-func (p *Base) load(a Actor) {
+func (p *Base) load(a *Actor) {
 	p.Actor = a
 }
 
@@ -24,6 +26,20 @@ func (*Base) OnInit() {
 func (*Base) OnStop() {
 }
 
+// IdleTimeout Actor空闲超时时间,返回0则使用System的设置
+func (*Base) IdleTimeout() time.Duration {
+	return 0
+}
+
+// CanPassivate Actor空闲回收前触发该函数,返回false则本次不回收
+func (*Base) CanPassivate() bool {
+	return true
+}
+
+// OnPassivate Actor空闲回收前触发该函数,可在此保存数据
+func (*Base) OnPassivate() {
+}
+
 // OnLocalReceived Actor收到Local消息时触发该函数
 func (*Base) OnLocalReceived(_ *cfacade.Message) (next bool, invoke bool) {
 	next = true
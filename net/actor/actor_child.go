@@ -49,10 +49,10 @@ func (p *actorChild) Create(childID string, handler cfacade.IActorHandler) (cfac
 		return nil, err
 	}
 
-	p.childActors.Store(childID, &childActor)
-	go childActor.run()
+	p.childActors.Store(childID, childActor)
+	p.thisActor.system.spawn(childActor)
 
-	return &childActor, nil
+	return childActor, nil
 }
 
 func (p *actorChild) Get(childID string) (cfacade.IActor, bool) {
@@ -1,6 +1,8 @@
 package cherryActor
 
 import (
+	"sync"
+
 	cfacade "github.com/cherry-game/cherry/facade"
 	clog "github.com/cherry-game/cherry/logger"
 )
@@ -9,6 +11,7 @@ type actorEvent struct {
 	thisActor *Actor                  // parent
 	queue                             // queue
 	funcMap   map[string][]IEventFunc // register event func map
+	funcMu    sync.RWMutex            // 注册/注销事件时与Push()互斥(actor自身goroutine读取funcMap无需加锁)
 }
 
 func newEvent(thisActor *Actor) actorEvent {
@@ -23,6 +26,9 @@ func newEvent(thisActor *Actor) actorEvent {
 // name 事件名
 // fn 接收事件处理的函数
 func (p *actorEvent) Register(name string, fn IEventFunc) {
+	p.funcMu.Lock()
+	defer p.funcMu.Unlock()
+
 	funcList := p.funcMap[name]
 	funcList = append(funcList, fn)
 	p.funcMap[name] = funcList
@@ -37,23 +43,55 @@ func (p *actorEvent) Registers(names []string, fn IEventFunc) {
 // Unregister 注销事件
 // name 事件名
 func (p *actorEvent) Unregister(name string) {
+	p.funcMu.Lock()
+	defer p.funcMu.Unlock()
+
 	delete(p.funcMap, name)
 }
 
-func (p *actorEvent) Push(data cfacade.IEventData) {
-	if _, found := p.funcMap[data.Name()]; found {
-		p.queue.Push(data)
+// clearFunc 注销所有事件
+func (p *actorEvent) clearFunc() {
+	p.funcMu.Lock()
+	defer p.funcMu.Unlock()
+
+	p.funcMap = make(map[string][]IEventFunc)
+}
+
+// registered 事件是否已注册(在其他goroutine中调用)
+func (p *actorEvent) registered(name string) bool {
+	p.funcMu.RLock()
+	defer p.funcMu.RUnlock()
+
+	_, found := p.funcMap[name]
+	return found
+}
+
+// Push 提交事件,返回是否有actor注册了该事件
+func (p *actorEvent) Push(data cfacade.IEventData) bool {
+	accepted := false
+
+	if p.registered(data.Name()) {
+		accepted = true
+		if !p.queue.Push(data) {
+			clog.Warnf("[%s] Event queue is full. [name = %s, count = %d]",
+				p.thisActor.Path(),
+				data.Name(),
+				p.Count(),
+			)
+		}
 	}
 
 	if p.thisActor.Path().IsChild() {
-		return
+		return accepted
 	}
 
 	p.thisActor.Child().Each(func(iActor cfacade.IActor) {
-		if childActor, ok := iActor.(*Actor); ok {
-			childActor.event.Push(data)
+		if childActor, ok := iActor.(*Actor); ok && childActor.event.Push(data) {
+			accepted = true
 		}
 	})
+
+	return accepted
 }
 
 func (p *actorEvent) Pop() cfacade.IEventData {
@@ -78,15 +116,20 @@ func (p *actorEvent) funcInvoke(data cfacade.IEventData) {
 			p.thisActor.Path(),
 			data,
 		)
+		p.thisActor.system.deadEvent(data, p.thisActor.PathString())
 		return
 	}
 
 	defer func() {
 		if rev := recover(); rev != nil {
-			clog.Errorf("[%s] Event invoke error. [data = %+v]",
+			clog.Errorf("[%s] Event invoke error. [data = %+v, err = %v]",
 				p.thisActor.Path(),
 				data,
+				rev,
 			)
+
+			p.thisActor.system.metrics.Load().observePanic(p.thisActor)
+			p.thisActor.onFailure(rev)
 		}
 	}()
 
@@ -96,7 +139,10 @@ func (p *actorEvent) funcInvoke(data cfacade.IEventData) {
 }
 
 func (p *actorEvent) onStop() {
+	p.funcMu.Lock()
 	p.funcMap = nil
+	p.funcMu.Unlock()
+
 	p.queue.Destroy()
 	p.thisActor = nil
 }
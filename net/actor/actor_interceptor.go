@@ -0,0 +1,90 @@
+package cherryActor
+
+import (
+	"strings"
+
+	ccode "github.com/cherry-game/cherry/code"
+	creflect "github.com/cherry-game/cherry/extend/reflect"
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+)
+
+type (
+	// Interceptor 函数调用拦截器
+	// 调用next()继续执行后续的拦截器及函数,不调用next()则终止本次调用,
+	// 返回的错误码会通过ResponseCode(客户端请求)或调用方的返回结果(CallWait)返回(返回OK时只回复CallWait)
+	Interceptor func(m *cfacade.Message, fi *creflect.FuncInfo, actor cfacade.IActor, next func()) int32
+)
+
+// AddInterceptor 添加所有actor的函数调用拦截器(先于actor的拦截器执行)
+func (p *System) AddInterceptor(interceptors ...Interceptor) {
+	p.interceptors = append(p.interceptors, interceptors...)
+}
+
+// AddInterceptor 添加当前actor的函数调用拦截器
+func (p *Actor) AddInterceptor(interceptors ...Interceptor) {
+	p.interceptors = append(p.interceptors, interceptors...)
+}
+
+// invokeWithInterceptors 按顺序执行拦截器,最后调用fn
+func (p *Actor) invokeWithInterceptors(mb *mailbox, app cfacade.IApplication, fn cfacade.InvokeFunc, fi *creflect.FuncInfo, m *cfacade.Message, actor cfacade.IActor) {
+	// actor内部使用的函数不经过拦截器
+	if len(p.system.interceptors) < 1 && len(p.interceptors) < 1 || isInternalFunc(m.FuncName) {
+		fn(app, fi, m, actor)
+		return
+	}
+
+	chain := make([]Interceptor, 0, len(p.system.interceptors)+len(p.interceptors))
+	chain = append(chain, p.system.interceptors...)
+	chain = append(chain, p.interceptors...)
+
+	invoked := false
+	code := ccode.OK
+	var next func(index int)
+	next = func(index int) {
+		if index == len(chain) {
+			invoked = true
+			fn(app, fi, m, actor)
+			return
+		}
+
+		called := false
+		result := chain[index](m, fi, actor, func() {
+			called = true
+			next(index + 1)
+		})
+
+		// 未调用next()的拦截器终止了本次调用
+		if !called {
+			code = result
+		}
+	}
+
+	next(0)
+	if invoked {
+		return
+	}
+
+	if ccode.IsOK(code) {
+		// 调用方等待结果时需要回复,避免等待超时
+		if m.ChanResult != nil || m.IsReply() {
+			p.system.replyCode(m, mb.name == LocalName, code)
+		}
+		return
+	}
+
+	clog.Warnf("[%s] Invoke intercepted. [source = %s, target = %s -> %s, code = %d]",
+		mb.name,
+		m.Source,
+		m.Target,
+		m.FuncName,
+		code,
+	)
+
+	p.system.replyCode(m, mb.name == LocalName, code)
+}
+
+// isInternalFunc 是否为actor内部使用的函数(如 _updateTimer_ )
+func isInternalFunc(funcName string) bool {
+	return len(funcName) > 1 && strings.HasPrefix(funcName, "_") && strings.HasSuffix(funcName, "_")
+}
@@ -0,0 +1,74 @@
+package cherryActor
+
+import (
+	"testing"
+
+	ccode "github.com/cherry-game/cherry/code"
+	creflect "github.com/cherry-game/cherry/extend/reflect"
+	cfacade "github.com/cherry-game/cherry/facade"
+)
+
+type testInterceptActor struct {
+	Base
+	steps []string
+}
+
+func (p *testInterceptActor) OnInit() {
+	p.Remote().Register("allow", p.allow)
+	p.Remote().Register("deny", p.allow)
+	p.Remote().Register("skip", p.allow)
+	p.Remote().Register("nested", p.allow)
+	p.AddInterceptor(func(m *cfacade.Message, _ *creflect.FuncInfo, _ cfacade.IActor, next func()) int32 {
+		p.steps = append(p.steps, "actor")
+		if m.FuncName == "nested" {
+			return ccode.ActorFuncNameError
+		}
+		next()
+		return ccode.OK
+	})
+}
+
+func (p *testInterceptActor) allow() int32 {
+	p.steps = append(p.steps, "invoke")
+	return ccode.OK
+}
+
+func TestInterceptor(t *testing.T) {
+	system := NewSystem()
+	system.SetApp(&testApp{})
+	system.AddInterceptor(func(m *cfacade.Message, _ *creflect.FuncInfo, _ cfacade.IActor, next func()) int32 {
+		switch m.FuncName {
+		case "deny":
+			return ccode.ActorCallFail
+		case "skip":
+			return ccode.OK
+		}
+		next()
+		return ccode.OK
+	})
+
+	handler := &testInterceptActor{}
+	system.CreateActor("target", handler)
+
+	if code := system.CallWait(".source", ".target", "allow", nil, nil); code != ccode.OK {
+		t.Fatalf("allow code = %d", code)
+	}
+
+	if code := system.CallWait(".source", ".target", "deny", nil, nil); code != ccode.ActorCallFail {
+		t.Fatalf("deny code = %d, want %d", code, ccode.ActorCallFail)
+	}
+
+	// 拦截器未调用next()时,调用方不会等待超时
+	if code := system.CallWait(".source", ".target", "skip", nil, nil); code != ccode.OK {
+		t.Fatalf("skip code = %d", code)
+	}
+
+	// 内层拦截器终止调用时返回其错误码
+	if code := system.CallWait(".source", ".target", "nested", nil, nil); code != ccode.ActorFuncNameError {
+		t.Fatalf("nested code = %d, want %d", code, ccode.ActorFuncNameError)
+	}
+
+	if len(handler.steps) != 3 || handler.steps[0] != "actor" || handler.steps[1] != "invoke" || handler.steps[2] != "actor" {
+		t.Fatalf("steps = %v", handler.steps)
+	}
+}
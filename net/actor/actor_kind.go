@@ -0,0 +1,119 @@
+package cherryActor
+
+import (
+	"strings"
+
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	KindSeparator = ":" // 虚拟actor的kind与id的分隔符. ActorID = kind:id
+)
+
+type (
+	// KindFactory 虚拟actor的handler创建函数
+	KindFactory func() cfacade.IActorHandler
+
+	// kindActor 虚拟actor的宿主,收到 node.kind.id 消息时按需创建子actor
+	kindActor struct {
+		Base
+		kind    string
+		factory KindFactory
+	}
+)
+
+// RegisterKind 注册虚拟actor类型
+// 目标actor不存在时,首条消息会按需创建并初始化actor:
+// node.kind:id 创建ActorID为kind:id的actor
+// node.kind.id 创建ActorID为kind的宿主actor,并创建ChildID为id的子actor
+func (p *System) RegisterKind(kind string, factory KindFactory) {
+	if strings.TrimSpace(kind) == "" || factory == nil {
+		clog.Warnf("[RegisterKind] kind or factory is nil. [kind = %s]", kind)
+		return
+	}
+
+	p.kinds.Store(kind, factory)
+}
+
+// getKindFactory 根据ActorID获取虚拟actor的kind及创建函数
+func (p *System) getKindFactory(actorID string) (string, KindFactory, bool) {
+	kind := actorID
+	if index := strings.Index(actorID, KindSeparator); index > 0 {
+		kind = actorID[:index]
+	}
+
+	value, found := p.kinds.Load(kind)
+	if !found {
+		return "", nil, false
+	}
+
+	return kind, value.(KindFactory), true
+}
+
+// activate 按需创建虚拟actor
+func (p *System) activate(targetPath *cfacade.ActorPath) (*Actor, bool) {
+	if targetPath == nil {
+		return nil, false
+	}
+
+	kind, factory, found := p.getKindFactory(targetPath.ActorID)
+	if !found {
+		return nil, false
+	}
+
+	var handler cfacade.IActorHandler
+	if kind == targetPath.ActorID {
+		// node.kind.id
+		if targetPath.IsParent() {
+			return nil, false
+		}
+		handler = &kindActor{kind: kind, factory: factory}
+	} else {
+		// node.kind:id
+		handler = factory()
+	}
+
+	p.activateLock.Lock()
+	defer p.activateLock.Unlock()
+
+	if thisActor, found := p.GetActor(targetPath.ActorID); found {
+		return thisActor, true
+	}
+
+	iActor, err := p.CreateActor(targetPath.ActorID, handler)
+	if err != nil {
+		clog.Warnf("[activate] Create actor fail. [actorID = %s, err = %v]", targetPath.ActorID, err)
+		return nil, false
+	}
+
+	if clog.PrintLevel(zapcore.DebugLevel) {
+		clog.Debugf("[activate] Virtual actor created. [actorID = %s]", targetPath.ActorID)
+	}
+
+	thisActor, ok := iActor.(*Actor)
+	return thisActor, ok
+}
+
+// OnFindChild 子actor不存在时,通过factory创建
+func (p *kindActor) OnFindChild(m *cfacade.Message) (cfacade.IActor, bool) {
+	childID := m.TargetPath().ChildID
+
+	childActor, err := p.Child().Create(childID, p.factory())
+	if err != nil {
+		clog.Warnf("[kindActor] Create child actor fail. [kind = %s, childID = %s, err = %v]",
+			p.kind,
+			childID,
+			err,
+		)
+		return nil, false
+	}
+
+	return childActor, true
+}
+
+// CanPassivate 宿主actor不参与空闲回收,由子actor各自回收
+func (*kindActor) CanPassivate() bool {
+	return false
+}
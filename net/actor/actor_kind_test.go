@@ -0,0 +1,53 @@
+package cherryActor
+
+import (
+	"testing"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cfacade "github.com/cherry-game/cherry/facade"
+)
+
+type testPlayerActor struct {
+	Base
+}
+
+func (p *testPlayerActor) OnInit() {
+	p.Remote().Register("path", p.pathString)
+}
+
+func (p *testPlayerActor) pathString() (*string, int32) {
+	path := p.PathString()
+	return &path, ccode.OK
+}
+
+func TestVirtualActor(t *testing.T) {
+	system := NewSystem()
+	system.SetApp(&testApp{})
+	system.RegisterKind("player", func() cfacade.IActorHandler {
+		return &testPlayerActor{}
+	})
+
+	for _, target := range []string{".player:1", ".player.2"} {
+		var reply string
+		code := system.CallWait(".source", target, "path", nil, &reply)
+		if code != ccode.OK {
+			t.Fatalf("target = %s, code = %d", target, code)
+		}
+
+		if reply != target {
+			t.Fatalf("reply = %s, want %s", reply, target)
+		}
+	}
+
+	if _, found := system.GetActor("player:1"); !found {
+		t.Fatal("player:1 should be activated")
+	}
+
+	if _, found := system.GetChildActor("player", "2"); !found {
+		t.Fatal("player.2 should be activated")
+	}
+
+	if code := system.CallWait(".source", ".guild:1", "path", nil, nil); code == ccode.OK {
+		t.Fatal("unregistered kind should not be activated")
+	}
+}
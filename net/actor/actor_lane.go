@@ -0,0 +1,120 @@
+package cherryActor
+
+import (
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+)
+
+const (
+	SystemLane Lane = 1 // 系统消息(actor内部函数及SetSystemFunc指定的函数)
+	RemoteLane Lane = 2 // 远程消息
+	LocalLane  Lane = 3 // 本地消息
+	EventLane  Lane = 4 // 事件消息
+)
+
+var (
+	// DefaultLanePriority 默认的消息通道优先级
+	DefaultLanePriority = []Lane{SystemLane, RemoteLane, LocalLane, EventLane}
+)
+
+type (
+	// Lane 消息通道.actor每次处理消息时按优先级选取第一个非空的通道
+	Lane int
+)
+
+func (l Lane) String() string {
+	switch l {
+	case SystemLane:
+		return SystemName
+	case RemoteLane:
+		return RemoteName
+	case LocalLane:
+		return LocalName
+	case EventLane:
+		return EventName
+	}
+	return "unknown"
+}
+
+// SetLanePriority 设置actor默认的消息通道优先级,对之后创建的actor生效
+// 未指定的通道按默认顺序追加在最后
+func (p *System) SetLanePriority(lanes ...Lane) {
+	p.lanes = normalizeLanes(lanes)
+}
+
+// SetSystemFunc 指定走系统通道的远程函数,如gm/admin消息,优先于其他消息处理
+func (p *Actor) SetSystemFunc(funcNames ...string) {
+	for _, funcName := range funcNames {
+		p.systemFuncs.Store(funcName, struct{}{})
+	}
+}
+
+// isSystemMessage 是否投递到系统通道
+func (p *Actor) isSystemMessage(m *cfacade.Message) bool {
+	if isInternalFunc(m.FuncName) {
+		return true
+	}
+
+	_, found := p.systemFuncs.Load(m.FuncName)
+	return found
+}
+
+// processNext 优先处理已恢复的暂存消息,再按通道优先级处理一条消息.返回是否有处理
+func (p *Actor) processNext() bool {
+	if len(p.unstashed) > 0 {
+		p.processUnstashed()
+		return true
+	}
+
+	for _, lane := range p.lanes {
+		switch lane {
+		case SystemLane:
+			if p.systemMail.Count() > 0 {
+				p.processSystem()
+				return true
+			}
+		case RemoteLane:
+			if p.remoteMail.Count() > 0 {
+				p.processRemote()
+				return true
+			}
+		case LocalLane:
+			if p.localMail.Count() > 0 {
+				p.processLocal()
+				return true
+			}
+		case EventLane:
+			if p.event.Count() > 0 {
+				p.processEvent()
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// normalizeLanes 去除重复及未知的通道,并补全缺少的通道
+func normalizeLanes(lanes []Lane) []Lane {
+	var (
+		list  []Lane
+		exist = make(map[Lane]bool)
+	)
+
+	all := append(append([]Lane{}, lanes...), DefaultLanePriority...)
+	for _, lane := range all {
+		if lane < SystemLane || lane > EventLane {
+			clog.Warnf("[normalizeLanes] Unknown lane. [lane = %d]", lane)
+			continue
+		}
+
+		if exist[lane] {
+			continue
+		}
+
+		exist[lane] = true
+		list = append(list, lane)
+	}
+
+	return list
+}
@@ -1,8 +1,11 @@
 package cherryActor
 
 import (
+	"sort"
+	"sync"
 	"time"
 
+	ccode "github.com/cherry-game/cherry/code"
 	creflect "github.com/cherry-game/cherry/extend/reflect"
 	cfacade "github.com/cherry-game/cherry/facade"
 	clog "github.com/cherry-game/cherry/logger"
@@ -12,6 +15,7 @@ type mailbox struct {
 	queue                                 // queue
 	name    string                        // 邮箱名
 	funcMap map[string]*creflect.FuncInfo // 已注册的函数
+	funcMu  sync.RWMutex                  // 注册函数时与funcNames()互斥(actor自身goroutine读取funcMap无需加锁)
 }
 
 func newMailbox(name string) mailbox {
@@ -23,6 +27,11 @@ func newMailbox(name string) mailbox {
 }
 
 func (p *mailbox) Register(funcName string, fn interface{}) {
+	p.register(funcName, fn, nil)
+}
+
+// register 注册函数,invoker不为nil时由invoker调用函数(不使用反射)
+func (p *mailbox) register(funcName string, fn, invoker interface{}) {
 	if funcName == "" || len(funcName) < 1 {
 		clog.Errorf("[%s] Func name is empty.", fn)
 		return
@@ -39,7 +48,11 @@ func (p *mailbox) Register(funcName string, fn interface{}) {
 		return
 	}
 
+	funcInfo.Invoker = invoker
+
+	p.funcMu.Lock()
 	p.funcMap[funcName] = &funcInfo
+	p.funcMu.Unlock()
 }
 
 func (p *mailbox) GetFuncInfo(funcName string) (*creflect.FuncInfo, bool) {
@@ -62,17 +75,50 @@ func (p *mailbox) Pop() *cfacade.Message {
 	return msg
 }
 
-func (p *mailbox) Push(m *cfacade.Message) {
-	if m != nil {
-		m.PostTime = time.Now().UnixMilli()
-		p.queue.Push(m)
+func (p *mailbox) Push(m *cfacade.Message) int32 {
+	if m == nil {
+		return ccode.OK
+	}
+
+	m.PostTime = time.Now().UnixMilli()
+	if !p.queue.Push(m) {
+		clog.Warnf("[%s] Mailbox is full. [source = %s, target = %s -> %s, count = %d]",
+			p.name,
+			m.Source,
+			m.Target,
+			m.FuncName,
+			p.Count(),
+		)
+		return ccode.ActorMailboxFull
 	}
+
+	return ccode.OK
 }
 
-func (p *mailbox) onStop() {
+// funcNames 已注册的函数名(已排序)
+func (p *mailbox) funcNames() []string {
+	p.funcMu.RLock()
+	defer p.funcMu.RUnlock()
+
+	names := make([]string, 0, len(p.funcMap))
+	for name := range p.funcMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (p *mailbox) clearFunc() {
+	p.funcMu.Lock()
+	defer p.funcMu.Unlock()
+
 	for key := range p.funcMap {
 		delete(p.funcMap, key)
 	}
+}
 
+func (p *mailbox) onStop() {
+	p.clearFunc()
 	p.queue.Destroy()
 }
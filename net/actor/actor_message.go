@@ -8,10 +8,11 @@ import (
 )
 
 const (
-	ResponseFuncName = "response"
-	PushFuncName     = "push"
-	KickFuncName     = "kick"
-	BroadcastName    = "broadcast"
+	ResponseFuncName     = "response"
+	PushFuncName         = "push"
+	KickFuncName         = "kick"
+	BroadcastName        = "broadcast"
+	MigrateRouteFuncName = "migrateRoute"
 )
 
 func Response(iActor cfacade.IActor, agentPath, sid string, mid uint32, v interface{}) {
@@ -123,3 +124,14 @@ func Broadcast(iActor cfacade.IActor, agentPath string, uidList []int64, allUID
 
 	iActor.Call(agentPath, BroadcastName, rsp)
 }
+
+// MigrateRoute 通知网关更新session的路由节点
+func MigrateRoute(iActor cfacade.IActor, agentPath, sid, nodeType, nodeID string) {
+	rsp := &cproto.MigrateRoute{
+		Sid:      sid,
+		NodeType: nodeType,
+		NodeId:   nodeID,
+	}
+
+	iActor.Call(agentPath, MigrateRouteFuncName, rsp)
+}
@@ -0,0 +1,160 @@
+package cherryActor
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cmetrics "github.com/cherry-game/cherry/net/metrics"
+)
+
+type (
+	// actorMetrics actor系统的指标
+	actorMetrics struct {
+		arrival      *cmetrics.HistogramVec // 消息到达耗时
+		execution    *cmetrics.HistogramVec // 函数执行耗时
+		messages     *cmetrics.CounterVec   // 处理的消息数量
+		panics       *cmetrics.CounterVec   // panic数量
+		deadLetters  *cmetrics.CounterVec   // 死信数量
+		callWait     *cmetrics.HistogramVec // CallWait耗时
+		callTimeouts *cmetrics.CounterVec   // CallWait超时数量
+	}
+)
+
+// EnableMetrics 开启指标采集,registry为空时使用cherryMetrics.DefaultRegistry
+// 通常由cherryMetrics.Component在初始化时调用,开启前创建的actor也会开始采集
+func (p *System) EnableMetrics(registry *cmetrics.Registry) {
+	if registry == nil {
+		registry = cmetrics.DefaultRegistry
+	}
+
+	metrics := &actorMetrics{
+		arrival:      cmetrics.NewHistogramVec("cherry_actor_arrival_seconds", "Time from message build to post to the actor mailbox.", nil, "actor", "mailbox", "func"),
+		execution:    cmetrics.NewHistogramVec("cherry_actor_execution_seconds", "Time spent executing actor functions.", nil, "actor", "mailbox", "func"),
+		messages:     cmetrics.NewCounterVec("cherry_actor_messages_total", "Messages processed by actors.", "actor", "mailbox"),
+		panics:       cmetrics.NewCounterVec("cherry_actor_panics_total", "Panics recovered while processing actor messages.", "actor"),
+		deadLetters:  cmetrics.NewCounterVec("cherry_actor_dead_letters_total", "Messages that could not be delivered or executed.", "reason"),
+		callWait:     cmetrics.NewHistogramVec("cherry_actor_call_wait_seconds", "CallWait latency by result code.", nil, "func", "code"),
+		callTimeouts: cmetrics.NewCounterVec("cherry_actor_call_wait_timeouts_total", "CallWait requests that timed out.", "func"),
+	}
+
+	registry.Register(
+		metrics.arrival,
+		metrics.execution,
+		metrics.messages,
+		metrics.panics,
+		metrics.deadLetters,
+		metrics.callWait,
+		metrics.callTimeouts,
+		cmetrics.NewGaugeFunc("cherry_actor_mailbox_depth", "Messages waiting in actor mailboxes.", p.collectMailboxDepth, "actor", "mailbox"),
+		cmetrics.NewGaugeFunc("cherry_actors", "Actors by state.", p.collectActorStates, "state"),
+	)
+
+	p.metrics.Store(metrics)
+}
+
+// collectMailboxDepth 按actor类型统计邮箱深度
+func (p *System) collectMailboxDepth(report func(value float64, labelValues ...string)) {
+	p.eachActor(func(thisActor *Actor) {
+		report(float64(thisActor.localMail.Count()), thisActor.typeName, LocalName)
+		report(float64(thisActor.remoteMail.Count()), thisActor.typeName, RemoteName)
+		report(float64(thisActor.systemMail.Count()), thisActor.typeName, SystemName)
+		report(float64(thisActor.event.Count()), thisActor.typeName, EventName)
+	})
+}
+
+// collectActorStates 按状态统计actor数量
+func (p *System) collectActorStates(report func(value float64, labelValues ...string)) {
+	p.eachActor(func(thisActor *Actor) {
+		report(1, thisActor.State().String())
+	})
+}
+
+// eachActor 遍历所有actor(包括子actor)
+func (p *System) eachActor(fn func(thisActor *Actor)) {
+	p.actorMap.Range(func(_, value any) bool {
+		thisActor, ok := value.(*Actor)
+		if !ok {
+			return true
+		}
+
+		fn(thisActor)
+
+		if thisActor.path.IsParent() {
+			thisActor.child.childActors.Range(func(_, childValue any) bool {
+				if childActor, ok := childValue.(*Actor); ok {
+					fn(childActor)
+				}
+				return true
+			})
+		}
+
+		return true
+	})
+}
+
+func (p *actorMetrics) observeArrival(thisActor *Actor, mailbox, funcName string, elapsed time.Duration) {
+	if p == nil {
+		return
+	}
+
+	p.messages.Inc(thisActor.typeName, mailbox)
+	p.arrival.Observe(elapsed.Seconds(), thisActor.typeName, mailbox, funcName)
+}
+
+func (p *actorMetrics) observeExecution(thisActor *Actor, mailbox, funcName string, elapsed time.Duration) {
+	if p == nil {
+		return
+	}
+
+	p.execution.Observe(elapsed.Seconds(), thisActor.typeName, mailbox, funcName)
+}
+
+func (p *actorMetrics) observeEvent(thisActor *Actor) {
+	if p == nil {
+		return
+	}
+
+	p.messages.Inc(thisActor.typeName, EventName)
+}
+
+func (p *actorMetrics) observePanic(thisActor *Actor) {
+	if p == nil {
+		return
+	}
+
+	p.panics.Inc(thisActor.typeName)
+}
+
+func (p *actorMetrics) observeDeadLetter(reason DeadLetterReason) {
+	if p == nil {
+		return
+	}
+
+	p.deadLetters.Inc(reason.String())
+}
+
+func (p *actorMetrics) observeCallWait(funcName string, begin time.Time, code int32) {
+	if p == nil {
+		return
+	}
+
+	p.callWait.Observe(time.Since(begin).Seconds(), funcName, codeLabel(code))
+	if code == ccode.ActorCallTimeout {
+		p.callTimeouts.Inc(funcName)
+	}
+}
+
+// actorTypeName actor的类型名,用于指标的label
+func actorTypeName(handler interface{}) string {
+	if handler == nil {
+		return ""
+	}
+	return strings.TrimPrefix(reflect.TypeOf(handler).String(), "*")
+}
+
+func codeLabel(code int32) string {
+	return strconv.Itoa(int(code))
+}
@@ -0,0 +1,45 @@
+package cherryActor
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	cmetrics "github.com/cherry-game/cherry/net/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	registry := cmetrics.NewRegistry()
+
+	system := NewSystem()
+	system.SetApp(&testApp{})
+	system.SetDeadLetterResponse(true)
+	system.EnableMetrics(registry)
+	system.CreateActor("room", &testRoomActor{})
+
+	system.CallWait(".tester", ".room", "add", nil, nil)
+	system.CallWait(".tester", ".room", "unknown", nil, nil)
+
+	if count := system.metrics.Load().messages.Value("cherryActor.testRoomActor", RemoteName); count != 1 {
+		t.Fatalf("messages = %v, want 1", count)
+	}
+
+	if count := system.metrics.Load().execution.Count("cherryActor.testRoomActor", RemoteName, "add"); count != 1 {
+		t.Fatalf("execution count = %d, want 1", count)
+	}
+
+	if count := system.metrics.Load().deadLetters.Value(FuncNotFoundReason.String()); count != 1 {
+		t.Fatalf("dead letters = %v, want 1", count)
+	}
+
+	buf := &bytes.Buffer{}
+	registry.WriteText(buf)
+	if !strings.Contains(buf.String(), `cherry_actors{state="worker"} 1`) {
+		t.Fatalf("metrics text:\n%s", buf.String())
+	}
+
+	// 亚毫秒级的执行耗时不会被截断为0
+	if strings.Contains(buf.String(), `cherry_actor_execution_seconds_sum{actor="cherryActor.testRoomActor",mailbox="remote",func="add"} 0`+"\n") {
+		t.Fatalf("metrics text:\n%s", buf.String())
+	}
+}
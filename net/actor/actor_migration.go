@@ -0,0 +1,330 @@
+package cherryActor
+
+import (
+	"context"
+	"time"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+	cproto "github.com/cherry-game/cherry/net/proto"
+)
+
+const (
+	migrateFuncName = "_migrate_"
+	restoreFuncName = "_restore_"
+)
+
+const (
+	defaultForwardExpire = 10 * time.Minute // 默认的转发记录保留时间
+)
+
+type (
+	// MigratedListener actor迁移完成后的监听函数(在源节点触发)
+	MigratedListener func(path *cfacade.ActorPath, targetNodeID string)
+
+	// forwardEntry 已迁移actor的转发记录
+	forwardEntry struct {
+		nodeID   string // 迁移后所在的节点id
+		expireAt int64  // 过期时间(ms)
+	}
+)
+
+// Migrate 将本节点的actor迁移到目标节点,返回迁移结果
+// actorID格式为 actorID 或 actorID.childID,handler需实现IActorMigration
+// 目标节点需通过RegisterKind注册该actor的kind,由首条消息(快照)创建并恢复actor
+// 迁移期间actor暂停处理消息,迁移成功后缓存及后续的消息会转发到目标节点
+// 注意:不可在被迁移的actor中调用
+func (p *System) Migrate(actorID, targetNodeID string) int32 {
+	if p.app == nil || targetNodeID == "" || targetNodeID == p.NodeId() {
+		clog.Warnf("[Migrate] Target node error. [actorID = %s, targetNodeID = %s]", actorID, targetNodeID)
+		return ccode.ActorMigrateFail
+	}
+
+	if _, found := p.app.Discovery().GetMember(targetNodeID); !found {
+		clog.Warnf("[Migrate] Target node not found. [actorID = %s, targetNodeID = %s]", actorID, targetNodeID)
+		return ccode.ActorMigrateFail
+	}
+
+	path, err := cfacade.ToActorPath(cfacade.NewPath(p.NodeId(), actorID))
+	if err != nil {
+		return ccode.ActorConvertPathError
+	}
+
+	if _, found := p.getActorWithPath(path); !found {
+		clog.Warnf("[Migrate] Actor not found. [actorID = %s]", actorID)
+		return ccode.ActorCallFail
+	}
+
+	// 迁移过程中actor需要等待目标节点恢复完成
+	ctx, cancel := context.WithTimeout(context.Background(), 2*p.callTimeout)
+	defer cancel()
+
+	source := cfacade.NewPath(p.NodeId(), migrateFuncName)
+	return p.CallWaitContext(ctx, source, path.String(), migrateFuncName, targetNodeID, nil)
+}
+
+// OnMigrated 添加actor迁移完成的监听函数
+func (p *System) OnMigrated(listener MigratedListener) {
+	if listener == nil {
+		return
+	}
+
+	p.migratedListeners = append(p.migratedListeners, listener)
+}
+
+// SetForwardExpire 设置actor迁移后转发记录的保留时间(需在迁移前设置)
+// 过期或目标节点离线后不再转发,调用方应在此之前通过placement等方式获取actor的新节点
+func (p *System) SetForwardExpire(d time.Duration) {
+	if d > 0 {
+		p.forwardExpire = d
+	}
+}
+
+func (p *System) getActorWithPath(path *cfacade.ActorPath) (*Actor, bool) {
+	if path.IsChild() {
+		return p.GetChildActor(path.ActorID, path.ChildID)
+	}
+
+	return p.GetActor(path.ActorID)
+}
+
+// getForward 获取已迁移actor所在的节点
+func (p *System) getForward(path *cfacade.ActorPath) (string, bool) {
+	if path == nil {
+		return "", false
+	}
+
+	key := forwardKey(path)
+	value, found := p.forwards.Load(key)
+	if !found {
+		return "", false
+	}
+
+	entry := value.(*forwardEntry)
+	if entry.expireAt <= time.Now().UnixMilli() {
+		p.forwards.CompareAndDelete(key, entry)
+		return "", false
+	}
+
+	return entry.nodeID, true
+}
+
+// addForward 记录已迁移的actor,首次记录时启动过期清理及成员监听
+func (p *System) addForward(path *cfacade.ActorPath, nodeID string) {
+	p.forwards.Store(forwardKey(path), &forwardEntry{
+		nodeID:   nodeID,
+		expireAt: time.Now().Add(p.forwardExpire).UnixMilli(),
+	})
+
+	p.forwardOnce.Do(func() {
+		interval := p.forwardExpire / 2
+		p.forwardTimer = globalTimer.AddEveryFunc(globalTimer.NextId(), interval, p.sweepForwards, true)
+
+		if p.app != nil && p.app.Discovery() != nil {
+			p.app.Discovery().OnRemoveMember(func(member cfacade.IMember) {
+				p.removeForwards(member.GetNodeId())
+			})
+		}
+	})
+}
+
+// sweepForwards 清理过期的转发记录
+func (p *System) sweepForwards() {
+	now := time.Now().UnixMilli()
+	p.forwards.Range(func(key, value any) bool {
+		if value.(*forwardEntry).expireAt <= now {
+			p.forwards.CompareAndDelete(key, value)
+		}
+		return true
+	})
+}
+
+// removeForwards 目标节点离线时删除转发到该节点的记录
+func (p *System) removeForwards(nodeID string) {
+	p.forwards.Range(func(key, value any) bool {
+		if value.(*forwardEntry).nodeID == nodeID {
+			p.forwards.CompareAndDelete(key, value)
+		}
+		return true
+	})
+}
+
+func (p *System) stopForwardSweep() {
+	if p.forwardTimer != nil {
+		p.forwardTimer.Stop()
+	}
+}
+
+// forward 将消息转发到actor迁移后的节点
+func (p *System) forward(m *cfacade.Message, nodeID string, isLocal bool) {
+	targetPath := m.TargetPath()
+	target := cfacade.NewChildPath(nodeID, targetPath.ActorID, targetPath.ChildID)
+
+	packet := cproto.BuildClusterPacket(m.Source, target, m.FuncName)
+	packet.Session = m.Session
+	packet.TraceParent = m.TraceParent
+	packet.MessageId = m.MessageID
+
+	if m.Args != nil {
+		if argBytes, ok := m.Args.([]byte); ok {
+			packet.ArgBytes = argBytes
+		} else {
+			argBytes, err := p.app.Serializer().Marshal(m.Args)
+			if err != nil {
+				clog.Warnf("[forward] Marshal arg error. [target = %s -> %s, err = %v]", target, m.FuncName, err)
+				p.replyForward(m, &cproto.Response{Code: ccode.ActorMarshalError})
+				packet.Recycle()
+				return
+			}
+			packet.ArgBytes = argBytes
+		}
+	}
+
+	if m.ChanResult != nil || m.IsReply() {
+		go func() {
+			rsp := p.app.Cluster().RequestRemote(nodeID, packet, p.callTimeout)
+			p.replyForward(m, &rsp)
+		}()
+		return
+	}
+
+	var err error
+	if isLocal {
+		err = p.app.Cluster().PublishLocal(nodeID, packet)
+	} else {
+		err = p.app.Cluster().PublishRemote(nodeID, packet)
+	}
+
+	if err != nil {
+		clog.Warnf("[forward] Publish fail. [source = %s, target = %s -> %s, err = %v]", m.Source, target, m.FuncName, err)
+	}
+}
+
+func (p *System) replyForward(m *cfacade.Message, rsp *cproto.Response) {
+	if m.ChanResult != nil {
+		retChanResult(m.ChanResult, rsp)
+	} else {
+		retResponse(m.ClusterReply, rsp)
+	}
+}
+
+// forwardKey 转发表的key(不含节点id)
+func forwardKey(path *cfacade.ActorPath) string {
+	return cfacade.NewChildPath("", path.ActorID, path.ChildID)
+}
+
+// MigratedTo actor迁移后所在的节点,未迁移返回空
+func (p *Actor) MigratedTo() string {
+	nodeID, _ := p.migratedTo.Load().(string)
+	return nodeID
+}
+
+// forwardMigrated actor已迁移,将邮箱中剩余的消息转发到目标节点(内部消息直接丢弃)
+func (p *Actor) forwardMigrated(m *cfacade.Message, isLocal bool) {
+	if _, found := systemFuncNames[m.FuncName]; found {
+		return
+	}
+
+	p.system.forward(m, p.MigratedTo(), isLocal)
+}
+
+// _migrate_ 在actor的goroutine中执行迁移,期间暂停处理其他消息
+func (p *Actor) _migrate_(targetNodeID string) int32 {
+	if p.State() != WorkerState || p.MigratedTo() != "" {
+		return ccode.ActorMigrateFail
+	}
+
+	migration, ok := p.handler.(IActorMigration)
+	if !ok {
+		clog.Warnf("[migrate] Handler not implement IActorMigration. [path = %s]", p.path)
+		return ccode.ActorMigrateFail
+	}
+
+	hasChild := false
+	p.child.Each(func(_ cfacade.IActor) {
+		hasChild = true
+	})
+
+	if hasChild {
+		clog.Warnf("[migrate] Actor with child actors cannot be migrated. [path = %s]", p.path)
+		return ccode.ActorMigrateFail
+	}
+
+	data, err := migration.OnSnapshot()
+	if err != nil {
+		clog.Warnf("[migrate] Snapshot fail. [path = %s, err = %v]", p.path, err)
+		return ccode.ActorMigrateFail
+	}
+
+	snapshot := &cproto.MigrateSnapshot{
+		SourceNodeId: p.system.NodeId(),
+		Data:         data,
+	}
+
+	target := cfacade.NewChildPath(targetNodeID, p.path.ActorID, p.path.ChildID)
+	if code := p.CallWait(target, restoreFuncName, snapshot, nil); ccode.IsFail(code) {
+		clog.Warnf("[migrate] Restore fail, actor resumed. [path = %s, targetNodeID = %s, code = %d]",
+			p.path,
+			targetNodeID,
+			code,
+		)
+		return code
+	}
+
+	// 邮箱中缓存的消息会在退出前转发到目标节点
+	p.migratedTo.Store(targetNodeID)
+	p.system.addForward(p.path, targetNodeID)
+
+	p.migrateSessions(targetNodeID)
+
+	for _, listener := range p.system.migratedListeners {
+		listener(p.path, targetNodeID)
+	}
+
+	clog.Infof("[migrate] Actor migrated. [path = %s, targetNodeID = %s]", p.path, targetNodeID)
+
+	p.stop()
+	return ccode.OK
+}
+
+// migrateSessions 通知网关将session路由到目标节点
+func (p *Actor) migrateSessions(targetNodeID string) {
+	migrationSessions, ok := p.handler.(IActorMigrationSessions)
+	if !ok {
+		return
+	}
+
+	nodeType, err := p.App().Discovery().GetType(targetNodeID)
+	if err != nil {
+		clog.Warnf("[migrate] Get node type fail. [targetNodeID = %s, err = %v]", targetNodeID, err)
+		return
+	}
+
+	for _, session := range migrationSessions.MigrationSessions() {
+		if session == nil || session.AgentPath == "" {
+			continue
+		}
+
+		MigrateRoute(p, session.AgentPath, session.Sid, nodeType, targetNodeID)
+	}
+}
+
+// _restore_ 在目标节点恢复actor的状态
+func (p *Actor) _restore_(snapshot *cproto.MigrateSnapshot) int32 {
+	migration, ok := p.handler.(IActorMigration)
+	if !ok {
+		clog.Warnf("[restore] Handler not implement IActorMigration. [path = %s]", p.path)
+		return ccode.ActorMigrateFail
+	}
+
+	if err := migration.OnRestore(snapshot.Data); err != nil {
+		clog.Warnf("[restore] Restore fail. [path = %s, sourceNodeID = %s, err = %v]", p.path, snapshot.SourceNodeId, err)
+		return ccode.ActorMigrateFail
+	}
+
+	// actor迁回本节点
+	p.system.forwards.Delete(forwardKey(p.path))
+
+	return ccode.OK
+}
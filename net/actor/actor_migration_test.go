@@ -0,0 +1,275 @@
+package cherryActor
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cfacade "github.com/cherry-game/cherry/facade"
+	cproto "github.com/cherry-game/cherry/net/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+type testRoomActor struct {
+	Base
+	count int32
+}
+
+func (p *testRoomActor) OnInit() {
+	p.Remote().Register("add", p.add)
+	p.Remote().Register("count", p.getCount)
+}
+
+func (p *testRoomActor) add() {
+	p.count++
+}
+
+func (p *testRoomActor) getCount() (*int32, int32) {
+	return &p.count, ccode.OK
+}
+
+func (p *testRoomActor) OnSnapshot() ([]byte, error) {
+	return []byte(strconv.Itoa(int(p.count))), nil
+}
+
+func (p *testRoomActor) OnRestore(data []byte) error {
+	count, err := strconv.Atoi(string(data))
+	p.count = int32(count)
+	return err
+}
+
+type (
+	testNodeApp struct {
+		testApp
+		nodeID    string
+		cluster   *testCluster
+		discovery *testDiscovery
+	}
+
+	testCluster struct {
+		cfacade.ICluster
+		systems map[string]*System
+	}
+
+	testDiscovery struct {
+		cfacade.IDiscovery
+		sync.RWMutex
+		members         map[string]cfacade.IMember
+		removeListeners []cfacade.MemberListener
+	}
+
+	testReply struct {
+		ch chan []byte
+	}
+)
+
+func (p *testNodeApp) NodeId() string                { return p.nodeID }
+func (p *testNodeApp) Cluster() cfacade.ICluster     { return p.cluster }
+func (p *testNodeApp) Discovery() cfacade.IDiscovery { return p.discovery }
+
+func (p *testCluster) RequestRemote(nodeID string, packet *cproto.ClusterPacket, _ ...time.Duration) cproto.Response {
+	reply := &testReply{ch: make(chan []byte, 1)}
+
+	message := cfacade.GetMessage()
+	message.Source = packet.SourcePath
+	message.Target = packet.TargetPath
+	message.FuncName = packet.FuncName
+	message.Args = packet.ArgBytes
+	message.IsCluster = true
+	message.TraceParent = packet.TraceParent
+	message.ClusterReply = reply
+
+	if code := p.systems[nodeID].PostRemote(&message); ccode.IsFail(code) {
+		return cproto.Response{Code: code}
+	}
+
+	rsp := &cproto.Response{}
+	if err := proto.Unmarshal(<-reply.ch, rsp); err != nil {
+		return cproto.Response{Code: ccode.RPCUnmarshalError}
+	}
+
+	return cproto.Response{Code: rsp.Code, Data: rsp.Data}
+}
+
+func (p *testCluster) PublishRemote(nodeID string, packet *cproto.ClusterPacket) error {
+	message := cfacade.GetMessage()
+	message.Source = packet.SourcePath
+	message.Target = packet.TargetPath
+	message.FuncName = packet.FuncName
+	message.Args = packet.ArgBytes
+	message.IsCluster = true
+	message.TraceParent = packet.TraceParent
+
+	p.systems[nodeID].PostRemote(&message)
+	return nil
+}
+
+func (p *testReply) Respond(data []byte) error {
+	p.ch <- data
+	return nil
+}
+
+func (p *testDiscovery) Map() map[string]cfacade.IMember {
+	p.RLock()
+	defer p.RUnlock()
+
+	members := make(map[string]cfacade.IMember, len(p.members))
+	for nodeID, member := range p.members {
+		members[nodeID] = member
+	}
+	return members
+}
+
+func (p *testDiscovery) ListByType(nodeType string, filterNodeId ...string) []cfacade.IMember {
+	p.RLock()
+	defer p.RUnlock()
+
+	var list []cfacade.IMember
+	for nodeID, member := range p.members {
+		if member.GetNodeType() == nodeType && (len(filterNodeId) < 1 || filterNodeId[0] != nodeID) {
+			list = append(list, member)
+		}
+	}
+	return list
+}
+
+func (p *testDiscovery) GetMember(nodeID string) (cfacade.IMember, bool) {
+	p.RLock()
+	defer p.RUnlock()
+
+	member, found := p.members[nodeID]
+	return member, found
+}
+
+func (p *testDiscovery) GetType(nodeID string) (string, error) {
+	member, found := p.GetMember(nodeID)
+	if !found {
+		return "", errors.New("node not found")
+	}
+	return member.GetNodeType(), nil
+}
+
+func (p *testDiscovery) OnAddMember(_ cfacade.MemberListener) {
+}
+
+func (p *testDiscovery) OnRemoveMember(listener cfacade.MemberListener) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.removeListeners = append(p.removeListeners, listener)
+}
+
+// RemoveMember 移除成员并通知所有节点
+func (p *testDiscovery) RemoveMember(nodeID string) {
+	p.Lock()
+	member, found := p.members[nodeID]
+	delete(p.members, nodeID)
+	listeners := append([]cfacade.MemberListener(nil), p.removeListeners...)
+	p.Unlock()
+
+	if !found {
+		return
+	}
+
+	for _, listener := range listeners {
+		listener(member)
+	}
+}
+
+func newTestNodes(nodeIDs ...string) map[string]*System {
+	cluster := &testCluster{systems: map[string]*System{}}
+	discovery := &testDiscovery{members: map[string]cfacade.IMember{}}
+
+	for _, nodeID := range nodeIDs {
+		system := NewSystem()
+		system.SetApp(&testNodeApp{nodeID: nodeID, cluster: cluster, discovery: discovery})
+		system.createSystemActor()
+
+		cluster.systems[nodeID] = system
+		discovery.members[nodeID] = &cproto.Member{NodeId: nodeID, NodeType: "game"}
+	}
+
+	return cluster.systems
+}
+
+func TestMigrate(t *testing.T) {
+	systems := newTestNodes("game-1", "game-2")
+	for _, system := range systems {
+		system.RegisterKind("room", func() cfacade.IActorHandler {
+			return &testRoomActor{}
+		})
+	}
+
+	source := systems["game-1"]
+
+	for i := 0; i < 2; i++ {
+		if code := source.CallWait("game-1.tester", "game-1.room:1", "add", nil, nil); code != ccode.OK {
+			t.Fatalf("add code = %d", code)
+		}
+	}
+
+	if code := source.Migrate("room:1", "game-2"); code != ccode.OK {
+		t.Fatalf("migrate code = %d", code)
+	}
+
+	if _, found := systems["game-2"].GetActor("room:1"); !found {
+		t.Fatal("actor should be created on the target node")
+	}
+
+	// 源节点的消息转发到目标节点
+	var count int32
+	if code := source.CallWait("game-1.tester", "game-1.room:1", "count", nil, &count); code != ccode.OK {
+		t.Fatalf("count code = %d", code)
+	}
+
+	if count != 2 {
+		t.Fatalf("count = %d, want 2", count)
+	}
+}
+
+func TestMigrateForwardRemoved(t *testing.T) {
+	systems := newTestNodes("game-1", "game-2")
+	for _, system := range systems {
+		system.RegisterKind("room", func() cfacade.IActorHandler {
+			return &testRoomActor{}
+		})
+	}
+
+	source := systems["game-1"]
+	source.SetForwardExpire(time.Hour)
+
+	for _, actorID := range []string{"room:1", "room:2"} {
+		if code := source.CallWait("game-1.tester", "game-1."+actorID, "add", nil, nil); code != ccode.OK {
+			t.Fatalf("add code = %d", code)
+		}
+
+		if code := source.Migrate(actorID, "game-2"); code != ccode.OK {
+			t.Fatalf("migrate code = %d", code)
+		}
+	}
+
+	path1 := cfacade.NewActorPath("game-1", "room:1", "")
+	path2 := cfacade.NewActorPath("game-1", "room:2", "")
+
+	// 过期的转发记录被删除
+	value, _ := source.forwards.Load(forwardKey(path1))
+	value.(*forwardEntry).expireAt = time.Now().UnixMilli()
+	source.sweepForwards()
+
+	if _, found := source.getForward(path1); found {
+		t.Fatal("expired forward should be removed")
+	}
+
+	if _, found := source.getForward(path2); !found {
+		t.Fatal("forward should be kept before expiry")
+	}
+
+	// 目标节点离线后删除转发记录
+	source.app.Discovery().(*testDiscovery).RemoveMember("game-2")
+	if _, found := source.getForward(path2); found {
+		t.Fatal("forward to removed node should be deleted")
+	}
+}
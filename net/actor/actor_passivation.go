@@ -0,0 +1,149 @@
+package cherryActor
+
+import (
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	passivateFuncName = "_passivate_"
+)
+
+// systemFuncNames actor内部使用的函数,执行时不更新lastAt
+var systemFuncNames = map[string]struct{}{
+	updateTimerFuncName: {},
+	restartFuncName:     {},
+	childFailedFuncName: {},
+	passivateFuncName:   {},
+	rehomeFuncName:      {},
+	migrateFuncName:     {},
+	recoverFuncName:     {},
+	releaseFuncName:     {},
+}
+
+// SetIdleTimeout 设置actor默认的空闲超时时间,超时的actor会被回收(0为不回收)
+func (p *System) SetIdleTimeout(d time.Duration) {
+	p.idleTimeout = d
+	p.startIdleScan()
+}
+
+// SetTypeIdleTimeout 按handler类型设置actor的空闲超时时间
+func (p *System) SetTypeIdleTimeout(handler cfacade.IActorHandler, d time.Duration) {
+	p.idleTypeTimeout.Store(reflect.TypeOf(handler), d)
+	p.startIdleScan()
+}
+
+// SetIdleScanInterval 设置空闲actor的扫描间隔(需在创建actor及设置空闲超时前调用)
+func (p *System) SetIdleScanInterval(d time.Duration) {
+	if d > 0 {
+		p.idleScanInterval = d
+	}
+}
+
+// startIdleScan 启动空闲actor的扫描定时器
+func (p *System) startIdleScan() {
+	p.idleScanOnce.Do(func() {
+		p.idleScanTimer = globalTimer.AddEveryFunc(globalTimer.NextId(), p.idleScanInterval, p.scanIdle, true)
+	})
+}
+
+func (p *System) stopIdleScan() {
+	if p.idleScanTimer != nil {
+		p.idleScanTimer.Stop()
+	}
+}
+
+// getIdleTimeout 获取actor的空闲超时时间
+// 优先级: IActorPassivation.IdleTimeout() > SetTypeIdleTimeout() > SetIdleTimeout()
+func (p *System) getIdleTimeout(thisActor *Actor) time.Duration {
+	if passivation, ok := thisActor.handler.(IActorPassivation); ok {
+		if d := passivation.IdleTimeout(); d > 0 {
+			return d
+		}
+	}
+
+	if value, found := p.idleTypeTimeout.Load(reflect.TypeOf(thisActor.handler)); found {
+		return value.(time.Duration)
+	}
+
+	return p.idleTimeout
+}
+
+// scanIdle 扫描所有actor,通知空闲超时的actor执行回收
+func (p *System) scanIdle() {
+	now := time.Now().UnixMilli()
+
+	p.actorMap.Range(func(_, value any) bool {
+		thisActor, ok := value.(*Actor)
+		if !ok {
+			return true
+		}
+
+		thisActor.child.Each(func(iActor cfacade.IActor) {
+			if childActor, ok := iActor.(*Actor); ok {
+				p.checkIdle(childActor, now)
+			}
+		})
+
+		p.checkIdle(thisActor, now)
+		return true
+	})
+}
+
+func (p *System) checkIdle(thisActor *Actor, now int64) {
+	if thisActor.State() != WorkerState || !thisActor.isIdle(now) {
+		return
+	}
+
+	thisActor.postSystem(passivateFuncName, nil)
+}
+
+// isIdle 判断actor是否空闲超时(now为毫秒)
+func (p *Actor) isIdle(now int64) bool {
+	// 集群单例不回收
+	if p.path.IsParent() && p.system.singletons.isSingleton(p.path.ActorID) {
+		return false
+	}
+
+	timeout := p.system.getIdleTimeout(p)
+	if timeout <= 0 {
+		return false
+	}
+
+	return now-atomic.LoadInt64(&p.lastAt) >= timeout.Milliseconds()
+}
+
+// touch 更新actor最后处理消息的时间(内部消息不更新)
+func (p *Actor) touch(funcName string) {
+	if _, found := systemFuncNames[funcName]; found {
+		return
+	}
+
+	atomic.StoreInt64(&p.lastAt, time.Now().UnixMilli())
+}
+
+// _passivate_ 在actor的goroutine中执行回收
+func (p *Actor) _passivate_() {
+	// 投递回收消息后可能收到了新消息,需要再次确认
+	if p.State() != WorkerState || len(p.close) > 0 || !p.isIdle(time.Now().UnixMilli()) {
+		return
+	}
+
+	if passivation, ok := p.handler.(IActorPassivation); ok {
+		if !passivation.CanPassivate() {
+			return
+		}
+		passivation.OnPassivate()
+	}
+
+	if clog.PrintLevel(zapcore.DebugLevel) {
+		clog.Debugf("[passivate] Idle actor exit. [path = %s, lastAt = %d]", p.path, p.LastAt())
+	}
+
+	p.stop()
+}
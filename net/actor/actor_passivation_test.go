@@ -0,0 +1,71 @@
+package cherryActor
+
+import (
+	"testing"
+	"time"
+)
+
+type testIdleActor struct {
+	Base
+	canPassivate bool
+	passivated   chan struct{}
+}
+
+func (p *testIdleActor) IdleTimeout() time.Duration {
+	return time.Second
+}
+
+func (p *testIdleActor) CanPassivate() bool {
+	return p.canPassivate
+}
+
+func (p *testIdleActor) OnPassivate() {
+	close(p.passivated)
+}
+
+func TestPassivateIdleActor(t *testing.T) {
+	system := NewSystem()
+	system.SetApp(&testApp{})
+	system.SetIdleScanInterval(100 * time.Millisecond)
+	defer system.stopIdleScan()
+
+	idle := &testIdleActor{canPassivate: true, passivated: make(chan struct{})}
+	system.CreateActor("idle", idle)
+
+	veto := &testIdleActor{canPassivate: false, passivated: make(chan struct{})}
+	system.CreateActor("veto", veto)
+
+	select {
+	case <-idle.passivated:
+	case <-time.After(3 * time.Second):
+		t.Fatal("idle actor was not passivated")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if _, found := system.GetActor("idle"); found {
+		t.Fatal("passivated actor should be removed")
+	}
+
+	if _, found := system.GetActor("veto"); !found {
+		t.Fatal("vetoed actor should keep running")
+	}
+}
+
+func TestIsIdleSubSecond(t *testing.T) {
+	system := NewSystem()
+	system.idleTimeout = 500 * time.Millisecond
+
+	thisActor, err := newActor("idle", "", &testActor{}, system)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lastAt := thisActor.lastAt
+	if thisActor.isIdle(lastAt + 100) {
+		t.Fatal("actor should not be idle before the timeout")
+	}
+
+	if !thisActor.isIdle(lastAt + 500) {
+		t.Fatal("actor should be idle after the timeout")
+	}
+}
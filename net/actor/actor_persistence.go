@@ -0,0 +1,367 @@
+package cherryActor
+
+import (
+	"fmt"
+	"time"
+
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+	cserializer "github.com/cherry-game/cherry/net/serializer"
+	jsoniter "github.com/json-iterator/go"
+)
+
+type (
+	// IStateStore actor状态存储
+	IStateStore interface {
+		Load(key string) ([]byte, error)    // 读取状态,不存在时返回nil
+		Save(key string, data []byte) error // 保存状态
+		Delete(key string) error            // 删除状态(包括日志)
+	}
+
+	// IJournalStore actor事件日志存储(事件溯源模式)
+	IJournalStore interface {
+		Append(key string, entries ...JournalEntry) error                          // 追加日志
+		Replay(key string, fromSeq int64, fn func(entry JournalEntry) error) error // 按顺序回放seq > fromSeq的日志
+		Truncate(key string, toSeq int64) error                                    // 删除seq <= toSeq的日志
+	}
+
+	// JournalEntry 事件日志
+	JournalEntry struct {
+		Seq  int64  `json:"seq"`  // 序号
+		Name string `json:"name"` // 事件名
+		Data []byte `json:"data"` // 事件数据
+	}
+
+	// IStateEvent 状态变更事件(事件溯源模式)
+	IStateEvent[S any] interface {
+		Name() string   // 事件名
+		Apply(state *S) // 将事件应用到状态
+	}
+
+	// persistentHandler 由Actor在初始化、停止时调用
+	persistentHandler interface {
+		loadState() error
+		flushState()
+		resetState()
+	}
+
+	// PersistentActor 带状态持久化的actor
+	// 首次调用Data()或OnInit()执行完成后从存储中加载状态,
+	// 通过定时器(SetFlushInterval)及actor停止时保存已修改的状态.
+	// 通过RegisterStateEvent注册事件并且存储实现了IJournalStore时,开启事件溯源模式:
+	// Persist()先追加日志再修改状态,加载时使用快照+日志恢复状态,保存快照后清理日志
+	PersistentActor[S any] struct {
+		Base
+		data          S
+		dirty         bool
+		loaded        bool
+		loadErr       error
+		seq           int64 // 最后一条日志的序号
+		snapshotSeq   int64 // 快照对应的日志序号
+		store         IStateStore
+		journal       IJournalStore
+		serializer    cfacade.ISerializer
+		key           string
+		flushInterval time.Duration
+		events        map[string]func() IStateEvent[S]
+	}
+
+	// stateSnapshot 保存到存储中的状态快照
+	stateSnapshot struct {
+		Seq  int64  `json:"seq"`
+		Data []byte `json:"data"`
+	}
+)
+
+// SetStateStore 设置actor状态的默认存储
+func (p *System) SetStateStore(store IStateStore) {
+	p.stateStore = store
+}
+
+// SetStore 设置状态存储,未设置则使用System的默认存储
+func (p *PersistentActor[S]) SetStore(store IStateStore) {
+	p.store = store
+}
+
+// SetSerializer 设置状态的序列化方式,默认为json
+func (p *PersistentActor[S]) SetSerializer(serializer cfacade.ISerializer) {
+	p.serializer = serializer
+}
+
+// SetFlushInterval 设置定时保存的间隔,为0时仅在actor停止时保存
+func (p *PersistentActor[S]) SetFlushInterval(interval time.Duration) {
+	p.flushInterval = interval
+}
+
+// SetStateKey 设置状态的存储key,默认为 actorID 或 actorID.childID
+func (p *PersistentActor[S]) SetStateKey(key string) {
+	p.key = key
+}
+
+// RegisterStateEvent 注册状态变更事件,需在加载状态前(OnInit中)注册
+func (p *PersistentActor[S]) RegisterStateEvent(newEvent func() IStateEvent[S]) {
+	if newEvent == nil {
+		return
+	}
+
+	if p.events == nil {
+		p.events = make(map[string]func() IStateEvent[S])
+	}
+	p.events[newEvent().Name()] = newEvent
+}
+
+// Data 返回当前状态,修改后需调用MarkDirty()
+func (p *PersistentActor[S]) Data() *S {
+	p.loadState()
+	return &p.data
+}
+
+// MarkDirty 标记状态已修改
+func (p *PersistentActor[S]) MarkDirty() {
+	p.dirty = true
+}
+
+// IsDirty 状态是否有未保存的修改
+func (p *PersistentActor[S]) IsDirty() bool {
+	return p.dirty || p.seq > p.snapshotSeq
+}
+
+// Seq 最后一条事件日志的序号
+func (p *PersistentActor[S]) Seq() int64 {
+	return p.seq
+}
+
+// Persist 修改状态.事件溯源模式下先追加日志,成功后再修改状态
+func (p *PersistentActor[S]) Persist(event IStateEvent[S]) error {
+	if err := p.loadState(); err != nil {
+		return err
+	}
+
+	if p.journal == nil {
+		event.Apply(&p.data)
+		p.dirty = true
+		return nil
+	}
+
+	if _, found := p.events[event.Name()]; !found {
+		return fmt.Errorf("state event not registered. [name = %s]", event.Name())
+	}
+
+	data, err := p.serializer.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	entry := JournalEntry{
+		Seq:  p.seq + 1,
+		Name: event.Name(),
+		Data: data,
+	}
+
+	if err = p.journal.Append(p.key, entry); err != nil {
+		return err
+	}
+
+	p.seq = entry.Seq
+	event.Apply(&p.data)
+
+	return nil
+}
+
+// Flush 保存状态快照,事件溯源模式下同时清理已包含在快照中的日志
+func (p *PersistentActor[S]) Flush() error {
+	if p.store == nil || !p.IsDirty() {
+		return nil
+	}
+
+	bytes, err := p.snapshot()
+	if err != nil {
+		return err
+	}
+
+	if err = p.store.Save(p.key, bytes); err != nil {
+		return err
+	}
+
+	if p.journal != nil && p.seq > p.snapshotSeq {
+		if err = p.journal.Truncate(p.key, p.seq); err != nil {
+			clog.Warnf("[PersistentActor] Truncate journal fail. [key = %s, err = %v]", p.key, err)
+		}
+	}
+
+	p.snapshotSeq = p.seq
+	p.dirty = false
+
+	return nil
+}
+
+// OnSnapshot actor迁移时序列化状态
+func (p *PersistentActor[S]) OnSnapshot() ([]byte, error) {
+	if err := p.loadState(); err != nil {
+		return nil, err
+	}
+	return p.snapshot()
+}
+
+// OnRestore actor迁移到本节点后恢复状态,并由本节点负责保存
+func (p *PersistentActor[S]) OnRestore(data []byte) error {
+	if err := p.restore(data); err != nil {
+		return err
+	}
+
+	p.loaded = true
+	p.loadErr = nil
+	p.dirty = true
+
+	return nil
+}
+
+func (p *PersistentActor[S]) snapshot() ([]byte, error) {
+	data, err := p.serializer.Marshal(&p.data)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsoniter.Marshal(&stateSnapshot{
+		Seq:  p.seq,
+		Data: data,
+	})
+}
+
+func (p *PersistentActor[S]) restore(bytes []byte) error {
+	snapshot := &stateSnapshot{}
+	if err := jsoniter.Unmarshal(bytes, snapshot); err != nil {
+		return err
+	}
+
+	var data S
+	if err := p.serializer.Unmarshal(snapshot.Data, &data); err != nil {
+		return err
+	}
+
+	p.data = data
+	p.seq = snapshot.Seq
+	p.snapshotSeq = snapshot.Seq
+
+	return nil
+}
+
+// loadState 加载快照并回放日志,只执行一次
+func (p *PersistentActor[S]) loadState() error {
+	if p.loaded {
+		return p.loadErr
+	}
+	p.loaded = true
+
+	if p.serializer == nil {
+		p.serializer = cserializer.NewJSON()
+	}
+
+	if p.store == nil {
+		p.store = p.System().stateStore
+	}
+
+	if p.store == nil {
+		p.loadErr = fmt.Errorf("state store not set. [path = %s]", p.Path())
+		return p.loadErr
+	}
+
+	if p.key == "" {
+		p.key = p.Path().ActorID
+		if p.Path().IsChild() {
+			p.key += "." + p.Path().ChildID
+		}
+	}
+
+	if journal, ok := p.store.(IJournalStore); ok && len(p.events) > 0 {
+		p.journal = journal
+	}
+
+	p.loadErr = p.recover()
+	if p.loadErr != nil {
+		return p.loadErr
+	}
+
+	if p.flushInterval > 0 {
+		p.Timer().Add(p.flushInterval, p.flushState)
+	}
+
+	return nil
+}
+
+func (p *PersistentActor[S]) recover() error {
+	bytes, err := p.store.Load(p.key)
+	if err != nil {
+		return err
+	}
+
+	if bytes != nil {
+		if err = p.restore(bytes); err != nil {
+			return err
+		}
+	}
+
+	if p.journal == nil {
+		return nil
+	}
+
+	return p.journal.Replay(p.key, p.seq, func(entry JournalEntry) error {
+		newEvent, found := p.events[entry.Name]
+		if !found {
+			return fmt.Errorf("state event not registered. [name = %s, seq = %d]", entry.Name, entry.Seq)
+		}
+
+		event := newEvent()
+		if err := p.serializer.Unmarshal(entry.Data, event); err != nil {
+			return err
+		}
+
+		event.Apply(&p.data)
+		p.seq = entry.Seq
+
+		return nil
+	})
+}
+
+// flushState 定时器及actor停止时保存状态.加载失败或已迁移到其他节点时不保存
+func (p *PersistentActor[S]) flushState() {
+	if !p.loaded || p.loadErr != nil || p.MigratedTo() != "" {
+		return
+	}
+
+	if err := p.Flush(); err != nil {
+		clog.Warnf("[PersistentActor] Flush fail. [key = %s, err = %v]", p.key, err)
+	}
+}
+
+// resetState actor重启时丢弃内存中的状态(包括未保存的修改),之后重新从存储中加载并注册定时保存
+func (p *PersistentActor[S]) resetState() {
+	var data S
+	p.data = data
+	p.dirty = false
+	p.loaded = false
+	p.loadErr = nil
+	p.seq = 0
+	p.snapshotSeq = 0
+	p.journal = nil
+}
+
+// loadState 加载持久化actor的状态,失败时停止actor(避免空状态覆盖存储中的数据)
+func (p *Actor) loadState() {
+	persistent, ok := p.handler.(persistentHandler)
+	if !ok {
+		return
+	}
+
+	if err := persistent.loadState(); err != nil {
+		clog.Errorf("[loadState] Load state fail, actor will be stopped. [path = %s, err = %v]", p.path, err)
+		p.stop()
+	}
+}
+
+// flushState 保存持久化actor的状态
+func (p *Actor) flushState() {
+	if persistent, ok := p.handler.(persistentHandler); ok {
+		persistent.flushState()
+	}
+}
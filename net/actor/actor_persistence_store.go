@@ -0,0 +1,264 @@
+package cherryActor
+
+import (
+	"bufio"
+	"bytes"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+type (
+	// MemoryStateStore 内存存储,用于测试或无需落地的状态
+	MemoryStateStore struct {
+		sync.RWMutex
+		states   map[string][]byte
+		journals map[string][]JournalEntry
+	}
+
+	// FileStateStore 文件存储,每个key对应 key.state 快照文件及 key.journal 日志文件
+	FileStateStore struct {
+		sync.Mutex
+		dir string
+	}
+)
+
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{
+		states:   make(map[string][]byte),
+		journals: make(map[string][]JournalEntry),
+	}
+}
+
+func (p *MemoryStateStore) Load(key string) ([]byte, error) {
+	p.RLock()
+	defer p.RUnlock()
+
+	data, found := p.states[key]
+	if !found {
+		return nil, nil
+	}
+
+	return append([]byte(nil), data...), nil
+}
+
+func (p *MemoryStateStore) Save(key string, data []byte) error {
+	p.Lock()
+	defer p.Unlock()
+
+	p.states[key] = append([]byte(nil), data...)
+	return nil
+}
+
+func (p *MemoryStateStore) Delete(key string) error {
+	p.Lock()
+	defer p.Unlock()
+
+	delete(p.states, key)
+	delete(p.journals, key)
+	return nil
+}
+
+func (p *MemoryStateStore) Append(key string, entries ...JournalEntry) error {
+	p.Lock()
+	defer p.Unlock()
+
+	p.journals[key] = append(p.journals[key], entries...)
+	return nil
+}
+
+func (p *MemoryStateStore) Replay(key string, fromSeq int64, fn func(entry JournalEntry) error) error {
+	p.RLock()
+	entries := append([]JournalEntry(nil), p.journals[key]...)
+	p.RUnlock()
+
+	for _, entry := range entries {
+		if entry.Seq <= fromSeq {
+			continue
+		}
+
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *MemoryStateStore) Truncate(key string, toSeq int64) error {
+	p.Lock()
+	defer p.Unlock()
+
+	var entries []JournalEntry
+	for _, entry := range p.journals[key] {
+		if entry.Seq > toSeq {
+			entries = append(entries, entry)
+		}
+	}
+
+	p.journals[key] = entries
+	return nil
+}
+
+func NewFileStateStore(dir string) (*FileStateStore, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	return &FileStateStore{
+		dir: dir,
+	}, nil
+}
+
+func (p *FileStateStore) statePath(key string) string {
+	return filepath.Join(p.dir, url.QueryEscape(key)+".state")
+}
+
+func (p *FileStateStore) journalPath(key string) string {
+	return filepath.Join(p.dir, url.QueryEscape(key)+".journal")
+}
+
+func (p *FileStateStore) Load(key string) ([]byte, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	data, err := os.ReadFile(p.statePath(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	return data, err
+}
+
+func (p *FileStateStore) Save(key string, data []byte) error {
+	p.Lock()
+	defer p.Unlock()
+
+	return writeFile(p.statePath(key), data)
+}
+
+func (p *FileStateStore) Delete(key string) error {
+	p.Lock()
+	defer p.Unlock()
+
+	for _, path := range []string{p.statePath(key), p.journalPath(key)} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *FileStateStore) Append(key string, entries ...JournalEntry) error {
+	p.Lock()
+	defer p.Unlock()
+
+	file, err := os.OpenFile(p.journalPath(key), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	for _, entry := range entries {
+		line, err := jsoniter.Marshal(&entry)
+		if err != nil {
+			return err
+		}
+
+		if _, err = file.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+
+	return file.Sync()
+}
+
+func (p *FileStateStore) Replay(key string, fromSeq int64, fn func(entry JournalEntry) error) error {
+	p.Lock()
+	entries, err := p.readJournal(key)
+	p.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.Seq <= fromSeq {
+			continue
+		}
+
+		if err = fn(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *FileStateStore) Truncate(key string, toSeq int64) error {
+	p.Lock()
+	defer p.Unlock()
+
+	entries, err := p.readJournal(key)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		if entry.Seq <= toSeq {
+			continue
+		}
+
+		line, err := jsoniter.Marshal(&entry)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	return writeFile(p.journalPath(key), buf.Bytes())
+}
+
+func (p *FileStateStore) readJournal(key string) ([]JournalEntry, error) {
+	file, err := os.Open(p.journalPath(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		if len(scanner.Bytes()) < 1 {
+			continue
+		}
+
+		entry := JournalEntry{}
+		if err = jsoniter.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
+
+// writeFile 先写入临时文件再替换,避免写入过程中中断导致文件损坏
+func writeFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,168 @@
+package cherryActor
+
+import (
+	"testing"
+	"time"
+
+	ccode "github.com/cherry-game/cherry/code"
+)
+
+type (
+	testWallet struct {
+		Gold int64
+	}
+
+	testAddGold struct {
+		Gold int64
+	}
+
+	testWalletActor struct {
+		PersistentActor[testWallet]
+		journal bool
+		flush   time.Duration
+	}
+)
+
+func (*testAddGold) Name() string {
+	return "addGold"
+}
+
+func (p *testAddGold) Apply(state *testWallet) {
+	state.Gold += p.Gold
+}
+
+func (p *testWalletActor) OnInit() {
+	if p.journal {
+		p.RegisterStateEvent(func() IStateEvent[testWallet] {
+			return &testAddGold{}
+		})
+	}
+
+	if p.flush > 0 {
+		p.SetFlushInterval(p.flush)
+	}
+
+	p.Remote().Register("add", p.add)
+	p.Remote().Register("gold", p.gold)
+	p.Remote().Register("crash", p.crash)
+}
+
+func (p *testWalletActor) crash(gold *int64) {
+	p.Data().Gold = *gold
+	p.MarkDirty()
+	panic("crash")
+}
+
+func (p *testWalletActor) add(gold *int64) int32 {
+	if p.journal {
+		if err := p.Persist(&testAddGold{Gold: *gold}); err != nil {
+			return ccode.ActorCallFail
+		}
+		return ccode.OK
+	}
+
+	p.Data().Gold += *gold
+	p.MarkDirty()
+	return ccode.OK
+}
+
+func (p *testWalletActor) gold() (*int64, int32) {
+	return &p.Data().Gold, ccode.OK
+}
+
+func runWallet(t *testing.T, store IStateStore, journal bool, add int64) int64 {
+	system := NewSystem()
+	system.SetApp(&testApp{})
+	system.SetStateStore(store)
+	system.CreateActor("wallet", &testWalletActor{journal: journal})
+
+	if code := system.CallWait(".tester", ".wallet", "add", &add, nil); code != ccode.OK {
+		t.Fatalf("add code = %d", code)
+	}
+
+	var gold int64
+	if code := system.CallWait(".tester", ".wallet", "gold", nil, &gold); code != ccode.OK {
+		t.Fatalf("gold code = %d", code)
+	}
+
+	system.Stop()
+	return gold
+}
+
+func TestPersistentActor(t *testing.T) {
+	store := NewMemoryStateStore()
+
+	if gold := runWallet(t, store, false, 10); gold != 10 {
+		t.Fatalf("gold = %d, want 10", gold)
+	}
+
+	// 停止时保存,重新创建后加载
+	if gold := runWallet(t, store, false, 5); gold != 15 {
+		t.Fatalf("gold = %d, want 15", gold)
+	}
+}
+
+func TestPersistentActorJournal(t *testing.T) {
+	store, err := NewFileStateStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gold := runWallet(t, store, true, 10); gold != 10 {
+		t.Fatalf("gold = %d, want 10", gold)
+	}
+
+	// 停止时保存快照并清理日志
+	count := 0
+	store.Replay("wallet", 0, func(_ JournalEntry) error {
+		count++
+		return nil
+	})
+	if count != 0 {
+		t.Fatalf("journal entries = %d, want 0", count)
+	}
+
+	// 快照之后的日志在加载时回放
+	store.Append("wallet", JournalEntry{Seq: 2, Name: "addGold", Data: []byte(`{"Gold":7}`)})
+
+	if gold := runWallet(t, store, true, 3); gold != 20 {
+		t.Fatalf("gold = %d, want 20", gold)
+	}
+}
+
+func TestPersistentActorRestart(t *testing.T) {
+	store := NewMemoryStateStore()
+	store.Save("wallet", []byte(`{"seq":0,"data":"eyJHb2xkIjoxMH0="}`))
+
+	system := NewSystem()
+	system.SetApp(&testApp{})
+	system.SetStateStore(store)
+	system.SetSupervisorStrategy(NewOneForOneStrategy(3, time.Minute))
+	system.CreateActor("wallet", &testWalletActor{flush: 10 * time.Millisecond})
+
+	corrupted := int64(999)
+	system.Call(".tester", ".wallet", "crash", &corrupted)
+
+	// 重启后丢弃未保存的修改,从存储中重新加载
+	var gold int64
+	if code := system.CallWait(".tester", ".wallet", "gold", nil, &gold); code != ccode.OK || gold != 10 {
+		t.Fatalf("gold = %d, code = %d, want 10", gold, code)
+	}
+
+	// 重启后继续定时保存
+	add := int64(5)
+	system.CallWait(".tester", ".wallet", "add", &add, nil)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		data, _ := store.Load("wallet")
+		if string(data) == `{"seq":0,"data":"eyJHb2xkIjoxNX0="}` {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("state not flushed after restart. [data = %s]", data)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,263 @@
+package cherryActor
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	rehomeFuncName       = "_rehome_"
+	defaultVirtualNodes  = 100 // 每个节点在哈希环上的虚拟节点数量
+	placementHashDivider = "#"
+)
+
+type (
+	// placement actor的集群放置服务
+	// 通过一致性哈希将actor(kind + id)映射到nodeType下的某个节点
+	placement struct {
+		system    *System
+		kinds     sync.Map // key:kind, value:*hashRing
+		listening sync.Once
+	}
+
+	// hashRing 一致性哈希环
+	hashRing struct {
+		sync.RWMutex
+		nodeType     string
+		virtualNodes int
+		hashes       []uint32          // 已排序的哈希值
+		nodes        map[uint32]string // key:hash, value:nodeID
+	}
+)
+
+func newPlacement(system *System) *placement {
+	return &placement{
+		system: system,
+	}
+}
+
+// SetPlacement 设置kind类型的actor分布在nodeType类型的节点上
+// 目标路径未指定节点时(如 .player:1 或 .player.1),Call/CallWait会自动定位到所属节点
+func (p *System) SetPlacement(kind, nodeType string) {
+	if strings.TrimSpace(kind) == "" || strings.TrimSpace(nodeType) == "" {
+		clog.Warnf("[SetPlacement] kind or nodeType is nil. [kind = %s, nodeType = %s]", kind, nodeType)
+		return
+	}
+
+	ring := newHashRing(nodeType, defaultVirtualNodes)
+	p.placement.kinds.Store(kind, ring)
+
+	if p.app != nil {
+		p.placement.load()
+	}
+}
+
+// Locate 获取actor所属的节点(集群单例返回当前选举的所属节点)
+func (p *System) Locate(actorID, childID string) (string, bool) {
+	if childID == "" {
+		if nodeID, found := p.singletons.locate(actorID); found {
+			return nodeID, true
+		}
+	}
+
+	kind, key := placementKey(actorID, childID)
+
+	ring, found := p.placement.getRing(kind)
+	if !found {
+		return "", false
+	}
+
+	return ring.get(key)
+}
+
+// resolvePath 目标路径未指定节点时,通过放置服务补全节点
+func (p *System) resolvePath(target string, targetPath *cfacade.ActorPath) (string, *cfacade.ActorPath) {
+	if targetPath.NodeID != "" {
+		return target, targetPath
+	}
+
+	nodeID, found := p.Locate(targetPath.ActorID, targetPath.ChildID)
+	if !found || nodeID == p.NodeId() {
+		return target, targetPath
+	}
+
+	newPath := cfacade.NewActorPath(nodeID, targetPath.ActorID, targetPath.ChildID)
+	return newPath.String(), newPath
+}
+
+// load 监听成员变化,重建哈希环
+func (p *placement) load() {
+	discovery := p.system.app.Discovery()
+	if discovery == nil {
+		return
+	}
+
+	p.listening.Do(func() {
+		discovery.OnAddMember(func(member cfacade.IMember) {
+			p.onMemberChanged(member.GetNodeType())
+		})
+
+		discovery.OnRemoveMember(func(member cfacade.IMember) {
+			p.onMemberChanged(member.GetNodeType())
+		})
+	})
+
+	p.kinds.Range(func(_, value any) bool {
+		ring := value.(*hashRing)
+		ring.rebuild(discovery.ListByType(ring.nodeType))
+		return true
+	})
+}
+
+func (p *placement) getRing(kind string) (*hashRing, bool) {
+	value, found := p.kinds.Load(kind)
+	if !found {
+		return nil, false
+	}
+
+	return value.(*hashRing), true
+}
+
+// onMemberChanged 成员变化时重建哈希环,并回收不再属于本节点的actor
+func (p *placement) onMemberChanged(nodeType string) {
+	discovery := p.system.app.Discovery()
+
+	p.kinds.Range(func(_, value any) bool {
+		ring := value.(*hashRing)
+		if ring.nodeType == nodeType {
+			ring.rebuild(discovery.ListByType(nodeType))
+		}
+		return true
+	})
+
+	p.rehome()
+}
+
+// rehome 通知不再属于本节点的actor退出
+func (p *placement) rehome() {
+	p.system.actorMap.Range(func(_, value any) bool {
+		thisActor, ok := value.(*Actor)
+		if !ok {
+			return true
+		}
+
+		thisActor.child.Each(func(iActor cfacade.IActor) {
+			if childActor, ok := iActor.(*Actor); ok && !p.isOwner(childActor) {
+				childActor.postSystem(rehomeFuncName, nil)
+			}
+		})
+
+		if !p.isOwner(thisActor) {
+			thisActor.postSystem(rehomeFuncName, nil)
+		}
+
+		return true
+	})
+}
+
+// isOwner actor是否属于本节点(未设置放置的actor均属于本节点)
+func (p *placement) isOwner(thisActor *Actor) bool {
+	nodeID, found := p.system.Locate(thisActor.path.ActorID, thisActor.path.ChildID)
+	return !found || nodeID == p.system.NodeId()
+}
+
+// _rehome_ actor已迁移到其他节点,保存数据后退出
+func (p *Actor) _rehome_() {
+	if p.State() != WorkerState || len(p.close) > 0 || p.system.placement.isOwner(p) {
+		return
+	}
+
+	if passivation, ok := p.handler.(IActorPassivation); ok {
+		passivation.OnPassivate()
+	}
+
+	if clog.PrintLevel(zapcore.DebugLevel) {
+		clog.Debugf("[rehome] Actor moved to other node. [path = %s]", p.path)
+	}
+
+	p.stop()
+}
+
+// placementKey 根据actor路径获取kind及哈希key
+// kind:id 的kind为ActorID中分隔符前的部分; kind.id 的kind为ActorID
+func placementKey(actorID, childID string) (string, string) {
+	if index := strings.Index(actorID, KindSeparator); index > 0 {
+		return actorID[:index], actorID
+	}
+
+	if childID == "" {
+		return actorID, actorID
+	}
+
+	return actorID, actorID + KindSeparator + childID
+}
+
+func newHashRing(nodeType string, virtualNodes int) *hashRing {
+	return &hashRing{
+		nodeType:     nodeType,
+		virtualNodes: virtualNodes,
+		nodes:        make(map[uint32]string),
+	}
+}
+
+func (r *hashRing) rebuild(members []cfacade.IMember) {
+	nodeIDs := make([]string, 0, len(members))
+	for _, member := range members {
+		nodeIDs = append(nodeIDs, member.GetNodeId())
+	}
+
+	r.setNodes(nodeIDs)
+}
+
+func (r *hashRing) setNodes(nodeIDs []string) {
+	hashes := make([]uint32, 0, len(nodeIDs)*r.virtualNodes)
+	nodes := make(map[uint32]string, len(nodeIDs)*r.virtualNodes)
+
+	for _, nodeID := range nodeIDs {
+		for i := 0; i < r.virtualNodes; i++ {
+			hash := crc32.ChecksumIEEE([]byte(nodeID + placementHashDivider + strconv.Itoa(i)))
+			if _, found := nodes[hash]; found {
+				continue
+			}
+
+			nodes[hash] = nodeID
+			hashes = append(hashes, hash)
+		}
+	}
+
+	sort.Slice(hashes, func(i, j int) bool {
+		return hashes[i] < hashes[j]
+	})
+
+	r.Lock()
+	r.hashes = hashes
+	r.nodes = nodes
+	r.Unlock()
+}
+
+func (r *hashRing) get(key string) (string, bool) {
+	r.RLock()
+	defer r.RUnlock()
+
+	if len(r.hashes) < 1 {
+		return "", false
+	}
+
+	hash := crc32.ChecksumIEEE([]byte(key))
+	index := sort.Search(len(r.hashes), func(i int) bool {
+		return r.hashes[i] >= hash
+	})
+
+	if index == len(r.hashes) {
+		index = 0
+	}
+
+	return r.nodes[r.hashes[index]], true
+}
@@ -0,0 +1,69 @@
+package cherryActor
+
+import (
+	"strconv"
+	"testing"
+
+	cfacade "github.com/cherry-game/cherry/facade"
+	cproto "github.com/cherry-game/cherry/net/proto"
+)
+
+func testMembers(nodeIDs ...string) []cfacade.IMember {
+	var members []cfacade.IMember
+	for _, nodeID := range nodeIDs {
+		members = append(members, &cproto.Member{NodeId: nodeID, NodeType: "game"})
+	}
+	return members
+}
+
+func TestHashRingRehome(t *testing.T) {
+	ring := newHashRing("game", defaultVirtualNodes)
+	ring.rebuild(testMembers("game-1", "game-2", "game-3"))
+
+	before := map[string]string{}
+	for i := 0; i < 1000; i++ {
+		key := "player:" + strconv.Itoa(i)
+		before[key], _ = ring.get(key)
+	}
+
+	ring.rebuild(testMembers("game-1", "game-2"))
+
+	for key, nodeID := range before {
+		after, _ := ring.get(key)
+		if nodeID != "game-3" && after != nodeID {
+			t.Fatalf("key = %s moved from %s to %s", key, nodeID, after)
+		}
+
+		if after == "game-3" {
+			t.Fatalf("key = %s still on removed node", key)
+		}
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	system := NewSystem()
+	system.SetPlacement("player", "game")
+
+	ring, _ := system.placement.getRing("player")
+	ring.rebuild(testMembers("game-1"))
+
+	// kind:id 与 kind.id 为同一个actor,定位到同一个节点
+	if k1, v1 := placementKey("player:1", ""); k1 != "player" || v1 != "player:1" {
+		t.Fatalf("kind = %s, key = %s", k1, v1)
+	}
+
+	if k2, v2 := placementKey("player", "1"); k2 != "player" || v2 != "player:1" {
+		t.Fatalf("kind = %s, key = %s", k2, v2)
+	}
+
+	targetPath, _ := cfacade.ToActorPath(".player.1")
+	target, _ := system.resolvePath(".player.1", targetPath)
+	if target != "game-1.player.1" {
+		t.Fatalf("target = %s", target)
+	}
+
+	targetPath, _ = cfacade.ToActorPath(".guild.1")
+	if target, _ = system.resolvePath(".guild.1", targetPath); target != ".guild.1" {
+		t.Fatalf("target = %s", target)
+	}
+}
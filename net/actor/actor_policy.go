@@ -0,0 +1,49 @@
+package cherryActor
+
+import (
+	"context"
+	"time"
+
+	cfacade "github.com/cherry-game/cherry/facade"
+	cproto "github.com/cherry-game/cherry/net/proto"
+)
+
+// SetCallPolicy 设置当前actor调用目标节点类型时的策略(重试、熔断、对冲请求),
+// 优先于profile中cluster->policy的配置,nodeType为cfacade.AnyNodeType时匹配所有类型,policy为nil时删除
+// 需在OnInit()中设置
+func (p *Actor) SetCallPolicy(nodeType string, policy *cfacade.Policy) {
+	if policy == nil {
+		delete(p.callPolicies, nodeType)
+		return
+	}
+
+	if p.callPolicies == nil {
+		p.callPolicies = cfacade.Policies{}
+	}
+	p.callPolicies[nodeType] = policy
+}
+
+// callContext 在ctx中保存当前actor的trace上下文及调用策略(ctx中已存在时不覆盖)
+func (p *Actor) callContext(ctx context.Context) context.Context {
+	ctx = p.traceContext(ctx)
+	if cfacade.PoliciesFromContext(ctx) != nil {
+		return ctx
+	}
+	return cfacade.WithPolicies(ctx, p.callPolicies)
+}
+
+// publishRemote 集群支持调用策略时使用ctx中的调用策略
+func (p *System) publishRemote(ctx context.Context, nodeID string, packet *cproto.ClusterPacket) error {
+	if cluster, ok := p.app.Cluster().(cfacade.IPolicyCluster); ok {
+		return cluster.PublishRemoteWithPolicy(cfacade.PoliciesFromContext(ctx), nodeID, packet)
+	}
+	return p.app.Cluster().PublishRemote(nodeID, packet)
+}
+
+// requestRemote 集群支持调用策略时使用ctx中的调用策略
+func (p *System) requestRemote(ctx context.Context, nodeID string, packet *cproto.ClusterPacket, timeout time.Duration) cproto.Response {
+	if cluster, ok := p.app.Cluster().(cfacade.IPolicyCluster); ok {
+		return cluster.RequestRemoteWithPolicy(cfacade.PoliciesFromContext(ctx), nodeID, packet, timeout)
+	}
+	return p.app.Cluster().RequestRemote(nodeID, packet, timeout)
+}
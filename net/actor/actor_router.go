@@ -0,0 +1,261 @@
+package cherryActor
+
+import (
+	"math/rand"
+	"strconv"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+)
+
+const (
+	resizeRouterFuncName = "_resizeRouter_"
+)
+
+const (
+	RoundRobinStrategy      RouterStrategy = 1 // 轮询
+	RandomStrategy          RouterStrategy = 2 // 随机
+	ConsistentHashStrategy  RouterStrategy = 3 // 按key一致性哈希
+	SmallestMailboxStrategy RouterStrategy = 4 // 邮箱深度最小
+	BroadcastStrategy       RouterStrategy = 5 // 广播到所有routee
+)
+
+type (
+	RouterStrategy int
+
+	// RouteeFactory 创建routee的handler
+	RouteeFactory func() cfacade.IActorHandler
+
+	// RouterHashKey 一致性哈希路由时获取消息的key
+	RouterHashKey func(m *cfacade.Message) string
+
+	// IRouterHashKey 消息参数实现该接口时,默认使用其返回值作为一致性哈希的key
+	IRouterHashKey interface {
+		RouterHashKey() string
+	}
+
+	// Router 路由actor,将发送到自身path的消息转发给子actor(routee)处理
+	// 调用方继续使用Call/CallWait发送消息到router的path,由routee执行函数并返回结果.
+	// routee的childID为 1~size,发送到子actor path(如 .pool.1)的消息不经过路由
+	Router struct {
+		Base
+		strategy  RouterStrategy
+		size      int
+		newRoutee RouteeFactory
+		hashKey   RouterHashKey
+		routees   []string // routee的childID
+		index     int      // 轮询的位置
+		ring      *hashRing
+	}
+)
+
+// NewRouter 创建路由actor的handler,通过System.CreateActor创建router
+func NewRouter(strategy RouterStrategy, size int, newRoutee RouteeFactory) *Router {
+	return &Router{
+		strategy:  strategy,
+		size:      size,
+		newRoutee: newRoutee,
+		hashKey:   defaultRouterHashKey,
+	}
+}
+
+// SetHashKey 设置一致性哈希路由时获取消息key的函数
+func (p *Router) SetHashKey(hashKey RouterHashKey) {
+	if hashKey != nil {
+		p.hashKey = hashKey
+	}
+}
+
+// NewHandler router重启时使用相同的配置创建新实例
+func (p *Router) NewHandler() cfacade.IActorHandler {
+	router := NewRouter(p.strategy, p.size, p.newRoutee)
+	router.hashKey = p.hashKey
+	return router
+}
+
+func (p *Router) OnInit() {
+	p.Remote().Register(resizeRouterFuncName, p._resizeRouter_)
+	p.resize(p.size)
+}
+
+// Size routee的数量
+func (p *Router) Size() int {
+	return len(p.routees)
+}
+
+func (p *Router) OnLocalReceived(m *cfacade.Message) (next bool, invoke bool) {
+	return p.onReceived(m, true), false
+}
+
+func (p *Router) OnRemoteReceived(m *cfacade.Message) (next bool, invoke bool) {
+	return p.onReceived(m, false), false
+}
+
+// onReceived 路由发送到router的消息,返回false表示已处理
+func (p *Router) onReceived(m *cfacade.Message, isLocal bool) bool {
+	if m.TargetPath().IsChild() || isInternalFunc(m.FuncName) {
+		return true
+	}
+
+	if p.strategy == BroadcastStrategy {
+		p.broadcast(m, isLocal)
+		return false
+	}
+
+	routee, found := p.route(m)
+	if !found {
+		clog.Warnf("[Router] Routee not found. [path = %s, funcName = %s]", p.path, m.FuncName)
+		p.system.deadLetter(m, isLocal, ChildNotFoundReason)
+		return false
+	}
+
+	postRoutee(routee, m, isLocal)
+	return false
+}
+
+func (p *Router) route(m *cfacade.Message) (*Actor, bool) {
+	if len(p.routees) < 1 {
+		return nil, false
+	}
+
+	switch p.strategy {
+	case RandomStrategy:
+		return p.getRoutee(p.routees[rand.Intn(len(p.routees))])
+	case ConsistentHashStrategy:
+		if childID, found := p.ring.get(p.hashKey(m)); found {
+			return p.getRoutee(childID)
+		}
+		return nil, false
+	case SmallestMailboxStrategy:
+		return p.smallestMailbox()
+	default:
+		childID := p.routees[p.index%len(p.routees)]
+		p.index++
+		return p.getRoutee(childID)
+	}
+}
+
+func (p *Router) smallestMailbox() (*Actor, bool) {
+	var (
+		routee *Actor
+		min    int32
+	)
+
+	for _, childID := range p.routees {
+		thisActor, found := p.getRoutee(childID)
+		if !found {
+			continue
+		}
+
+		count := thisActor.localMail.Count() + thisActor.remoteMail.Count()
+		if routee == nil || count < min {
+			routee = thisActor
+			min = count
+		}
+	}
+
+	return routee, routee != nil
+}
+
+// broadcast 发送消息到所有routee,仅第一个routee的执行结果会返回给调用方
+func (p *Router) broadcast(m *cfacade.Message, isLocal bool) {
+	for i, childID := range p.routees {
+		routee, found := p.getRoutee(childID)
+		if !found {
+			continue
+		}
+
+		message := m
+		if i > 0 {
+			clone := *m
+			clone.ChanResult = nil
+			clone.ClusterReply = nil
+			message = &clone
+		}
+
+		postRoutee(routee, message, isLocal)
+	}
+}
+
+// getRoutee 获取routee,已退出的routee会重新创建
+func (p *Router) getRoutee(childID string) (*Actor, bool) {
+	if routee, found := p.child.GetActor(childID); found {
+		return routee, true
+	}
+
+	iActor, err := p.Child().Create(childID, p.newRoutee())
+	if err != nil {
+		clog.Warnf("[Router] Create routee fail. [path = %s, childID = %s, err = %v]", p.path, childID, err)
+		return nil, false
+	}
+
+	routee, ok := iActor.(*Actor)
+	return routee, ok
+}
+
+// resize 调整routee的数量
+func (p *Router) resize(size int) {
+	if size < 1 {
+		size = 1
+	}
+
+	for i := len(p.routees); i > size; i-- {
+		if routee, found := p.child.GetActor(strconv.Itoa(i)); found {
+			routee.stop()
+		}
+	}
+
+	routees := make([]string, 0, size)
+	for i := 1; i <= size; i++ {
+		childID := strconv.Itoa(i)
+		routees = append(routees, childID)
+
+		if i > len(p.routees) {
+			p.getRoutee(childID)
+		}
+	}
+
+	p.size = size
+	p.routees = routees
+	p.index = 0
+
+	if p.strategy == ConsistentHashStrategy {
+		if p.ring == nil {
+			p.ring = newHashRing("", defaultVirtualNodes)
+		}
+		p.ring.setNodes(routees)
+	}
+}
+
+func (p *Router) _resizeRouter_(size int) int32 {
+	p.resize(size)
+	return ccode.OK
+}
+
+// ResizeRouter 调整本节点router的routee数量
+func (p *System) ResizeRouter(actorID string, size int) int32 {
+	source := cfacade.NewPath(p.NodeId(), resizeRouterFuncName)
+	target := cfacade.NewPath(p.NodeId(), actorID)
+	return p.CallWait(source, target, resizeRouterFuncName, size, nil)
+}
+
+func postRoutee(routee *Actor, m *cfacade.Message, isLocal bool) {
+	if isLocal {
+		routee.PostLocal(m)
+	} else {
+		routee.PostRemote(m)
+	}
+}
+
+func defaultRouterHashKey(m *cfacade.Message) string {
+	if key, ok := m.Args.(IRouterHashKey); ok {
+		return key.RouterHashKey()
+	}
+
+	if m.Session != nil && m.Session.Uid > 0 {
+		return strconv.FormatInt(m.Session.Uid, 10)
+	}
+
+	return m.Source
+}
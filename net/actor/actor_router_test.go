@@ -0,0 +1,83 @@
+package cherryActor
+
+import (
+	"testing"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cfacade "github.com/cherry-game/cherry/facade"
+)
+
+type (
+	testWorkerActor struct {
+		Base
+	}
+
+	testHashArg struct {
+		Key string
+	}
+)
+
+func (p *testHashArg) RouterHashKey() string {
+	return p.Key
+}
+
+func (p *testWorkerActor) OnInit() {
+	p.Remote().Register("work", p.work)
+}
+
+func (p *testWorkerActor) work(_ *testHashArg) (*string, int32) {
+	childID := p.Path().ChildID
+	return &childID, ccode.OK
+}
+
+func newTestRouter(strategy RouterStrategy, size int) *System {
+	system := NewSystem()
+	system.SetApp(&testApp{})
+	system.CreateActor("pool", NewRouter(strategy, size, func() cfacade.IActorHandler {
+		return &testWorkerActor{}
+	}))
+	return system
+}
+
+func callWorker(t *testing.T, system *System, key string) string {
+	var childID string
+	if code := system.CallWait(".tester", ".pool", "work", &testHashArg{Key: key}, &childID); code != ccode.OK {
+		t.Fatalf("work code = %d", code)
+	}
+	return childID
+}
+
+func TestRouterRoundRobin(t *testing.T) {
+	system := newTestRouter(RoundRobinStrategy, 3)
+
+	for i, want := range []string{"1", "2", "3", "1"} {
+		if childID := callWorker(t, system, ""); childID != want {
+			t.Fatalf("call %d routed to %s, want %s", i, childID, want)
+		}
+	}
+
+	if code := system.ResizeRouter("pool", 2); code != ccode.OK {
+		t.Fatalf("resize code = %d", code)
+	}
+
+	for i, want := range []string{"1", "2", "1"} {
+		if childID := callWorker(t, system, ""); childID != want {
+			t.Fatalf("call %d routed to %s after resize, want %s", i, childID, want)
+		}
+	}
+}
+
+func TestRouterConsistentHash(t *testing.T) {
+	system := newTestRouter(ConsistentHashStrategy, 4)
+
+	routed := map[string]string{}
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		routed[key] = callWorker(t, system, key)
+	}
+
+	for key, childID := range routed {
+		if got := callWorker(t, system, key); got != childID {
+			t.Fatalf("key %s routed to %s, want %s", key, got, childID)
+		}
+	}
+}
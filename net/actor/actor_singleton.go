@@ -0,0 +1,329 @@
+package cherryActor
+
+import (
+	"hash/crc32"
+	"strings"
+	"sync"
+	"time"
+
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+)
+
+const (
+	recoverFuncName     = "_recover_"
+	releaseFuncName     = "_release_"
+	defaultSingletonTTL = 3 * time.Second        // 默认的租约时长
+	singletonRetryDelay = 100 * time.Millisecond // 租约时长为0时,启动失败的重试间隔
+)
+
+type (
+	// ISingletonRecovery 集群单例actor在故障转移后启动时触发OnRecover(在OnInit之后执行)
+	// previousNodeID为故障的原所属节点,可在此恢复数据或补偿未完成的业务
+	ISingletonRecovery interface {
+		OnRecover(previousNodeID string)
+	}
+
+	// singletons 集群单例actor
+	// 每个单例在nodeType类型的节点中通过选举确定唯一的所属节点(对单例名及节点id做最高随机权重哈希),
+	// 所属节点持有租约运行该actor.所属节点变化时,原节点立即停止actor,新节点等待租约过期后再启动,
+	// 避免同一时刻在两个节点运行
+	singletons struct {
+		sync.Mutex
+		system    *System
+		items     map[string]*singleton // key:name
+		ttl       time.Duration         // 租约时长
+		listening sync.Once
+	}
+
+	singleton struct {
+		name      string
+		nodeType  string
+		factory   KindFactory
+		owner     string // 当前选举的所属节点
+		leaseTerm uint64 // 租约任期,所属节点变化时递增,用于取消过期的启动
+		running   bool   // 是否在本节点运行
+	}
+)
+
+func newSingletons(system *System) *singletons {
+	return &singletons{
+		system: system,
+		items:  make(map[string]*singleton),
+		ttl:    defaultSingletonTTL,
+	}
+}
+
+// RegisterSingleton 注册集群单例actor,单例只在nodeType类型的节点中选举出的一个节点上运行
+// 所有节点(包括非nodeType类型的节点)都需注册,以便通过逻辑名称 .name 调用:
+// Call/CallWait的目标路径未指定节点时(如 .worldBoss),会自动定位到当前所属节点
+// factory为nil或当前节点不是nodeType类型时,只用于定位不会运行
+func (p *System) RegisterSingleton(name, nodeType string, factory KindFactory) {
+	if strings.TrimSpace(name) == "" || strings.TrimSpace(nodeType) == "" {
+		clog.Warnf("[RegisterSingleton] name or nodeType is nil. [name = %s, nodeType = %s]", name, nodeType)
+		return
+	}
+
+	p.singletons.Lock()
+	p.singletons.items[name] = &singleton{
+		name:     name,
+		nodeType: nodeType,
+		factory:  factory,
+	}
+	p.singletons.Unlock()
+
+	if p.app != nil {
+		p.singletons.load()
+	}
+}
+
+// SetSingletonLease 设置单例的租约时长,所属节点变化后新节点等待该时长再启动单例
+func (p *System) SetSingletonLease(ttl time.Duration) {
+	if ttl >= 0 {
+		p.singletons.Lock()
+		p.singletons.ttl = ttl
+		p.singletons.Unlock()
+	}
+}
+
+// SingletonOwner 获取单例当前的所属节点
+func (p *System) SingletonOwner(name string) (string, bool) {
+	p.singletons.Lock()
+	defer p.singletons.Unlock()
+
+	item, found := p.singletons.items[name]
+	if !found || item.owner == "" {
+		return "", false
+	}
+
+	return item.owner, true
+}
+
+// SingletonPath 单例的逻辑路径(不含节点id)
+func SingletonPath(name string) string {
+	return cfacade.NewPath("", name)
+}
+
+// load 监听成员变化并进行选举
+func (p *singletons) load() {
+	discovery := p.system.app.Discovery()
+	if discovery == nil {
+		return
+	}
+
+	p.listening.Do(func() {
+		discovery.OnAddMember(func(_ cfacade.IMember) {
+			p.elect()
+		})
+
+		discovery.OnRemoveMember(func(_ cfacade.IMember) {
+			p.elect()
+		})
+	})
+
+	p.elect()
+}
+
+// elect 根据当前成员重新选举所有单例的所属节点
+// 在锁外启动单例,避免在锁内执行factory及actor的初始化
+func (p *singletons) elect() {
+	discovery := p.system.app.Discovery()
+	if discovery == nil {
+		return
+	}
+
+	var starts []func()
+
+	p.Lock()
+	for _, item := range p.items {
+		owner := electOwner(item.name, discovery.ListByType(item.nodeType))
+		if owner == item.owner {
+			continue
+		}
+
+		previous := item.owner
+		item.owner = owner
+		item.leaseTerm++
+
+		clog.Infof("[singleton] Owner changed. [name = %s, previous = %s, owner = %s]", item.name, previous, owner)
+
+		if item.running && owner != p.system.NodeId() {
+			p.release(item)
+		}
+
+		if owner == p.system.NodeId() && !item.running && p.eligible(item) {
+			if start := p.acquire(item, previous, discovery); start != nil {
+				starts = append(starts, start)
+			}
+		}
+	}
+	p.Unlock()
+
+	for _, start := range starts {
+		start()
+	}
+}
+
+// acquire 成为所属节点.原所属节点存在时等待租约过期后再启动,否则返回立即执行的启动函数
+func (p *singletons) acquire(item *singleton, previous string, discovery cfacade.IDiscovery) func() {
+	failover := false
+	if previous != "" {
+		_, alive := discovery.GetMember(previous)
+		failover = !alive
+	}
+
+	term := item.leaseTerm
+	start := func() {
+		p.start(item, term, previous, failover)
+	}
+
+	if previous == "" || p.ttl <= 0 {
+		return start
+	}
+
+	globalTimer.AfterFunc(globalTimer.NextId(), p.ttl, start, true)
+	return nil
+}
+
+// start 在本节点启动单例,故障转移时执行恢复函数
+// 本节点的原actor尚未退出时(所属节点变化后又变回本节点),在下一个租约周期重试
+func (p *singletons) start(item *singleton, term uint64, previous string, failover bool) {
+	p.Lock()
+	// 等待期间所属节点再次变化,本次启动作废
+	if item.leaseTerm != term || item.running {
+		p.Unlock()
+		return
+	}
+	factory := item.factory
+	p.Unlock()
+
+	if _, found := p.system.GetActor(item.name); found {
+		p.retry(item, term, previous, failover)
+		return
+	}
+
+	iActor, err := p.system.CreateActor(item.name, factory())
+	if err != nil {
+		clog.Warnf("[singleton] Create actor fail, retry later. [name = %s, err = %v]", item.name, err)
+		p.retry(item, term, previous, failover)
+		return
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	// 启动期间不再是所属节点
+	if item.owner != p.system.NodeId() {
+		p.release(item)
+		return
+	}
+
+	item.running = true
+	clog.Infof("[singleton] Actor started. [name = %s, node = %s, failover = %v]", item.name, p.system.NodeId(), failover)
+
+	if failover {
+		if thisActor, ok := iActor.(*Actor); ok {
+			thisActor.postSystem(recoverFuncName, previous)
+		}
+	}
+}
+
+// retry 在下一个租约周期重新启动单例
+func (p *singletons) retry(item *singleton, term uint64, previous string, failover bool) {
+	p.Lock()
+	delay := p.ttl
+	p.Unlock()
+
+	if delay <= 0 {
+		delay = singletonRetryDelay
+	}
+
+	globalTimer.AfterFunc(globalTimer.NextId(), delay, func() {
+		p.start(item, term, previous, failover)
+	}, true)
+}
+
+// release 不再是所属节点,停止单例
+func (p *singletons) release(item *singleton) {
+	item.running = false
+
+	if thisActor, found := p.system.GetActor(item.name); found {
+		thisActor.postSystem(releaseFuncName, nil)
+	}
+}
+
+// eligible 当前节点是否可以运行该单例
+func (p *singletons) eligible(item *singleton) bool {
+	if item.factory == nil {
+		return false
+	}
+
+	nodeType, err := p.system.app.Discovery().GetType(p.system.NodeId())
+	if err != nil {
+		// 发现服务中没有当前节点时,以选举结果为准
+		return true
+	}
+
+	return nodeType == item.nodeType
+}
+
+// locate 获取单例的所属节点
+func (p *singletons) locate(name string) (string, bool) {
+	p.Lock()
+	defer p.Unlock()
+
+	item, found := p.items[name]
+	if !found || item.owner == "" {
+		return "", false
+	}
+
+	return item.owner, true
+}
+
+// isSingleton actorID是否为已注册的单例
+func (p *singletons) isSingleton(actorID string) bool {
+	p.Lock()
+	defer p.Unlock()
+
+	_, found := p.items[actorID]
+	return found
+}
+
+// electOwner 最高随机权重哈希: 权重最大的节点为所属节点,节点变化只影响其所属的单例
+func electOwner(name string, members []cfacade.IMember) string {
+	var (
+		owner  string
+		weight uint32
+	)
+
+	for _, member := range members {
+		nodeID := member.GetNodeId()
+		w := crc32.ChecksumIEEE([]byte(name + placementHashDivider + nodeID))
+		if owner == "" || w > weight || (w == weight && nodeID < owner) {
+			owner, weight = nodeID, w
+		}
+	}
+
+	return owner
+}
+
+// _release_ 单例不再属于本节点,保存数据后退出
+// 与_rehome_不同,不再检查所属节点,重新成为所属节点时等待退出后再启动
+func (p *Actor) _release_() {
+	if p.State() != WorkerState || len(p.close) > 0 {
+		return
+	}
+
+	if passivation, ok := p.handler.(IActorPassivation); ok {
+		passivation.OnPassivate()
+	}
+
+	p.stop()
+}
+
+// _recover_ 故障转移后执行单例的恢复函数
+func (p *Actor) _recover_(previousNodeID string) {
+	if recovery, ok := p.handler.(ISingletonRecovery); ok {
+		recovery.OnRecover(previousNodeID)
+	}
+}
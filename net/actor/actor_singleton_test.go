@@ -0,0 +1,153 @@
+package cherryActor
+
+import (
+	"testing"
+	"time"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cfacade "github.com/cherry-game/cherry/facade"
+)
+
+type testBossActor struct {
+	Base
+	recovered chan string
+	passivate time.Duration // 模拟退出前保存数据的耗时
+}
+
+func (p *testBossActor) OnInit() {
+	p.Remote().Register("node", func() (*string, int32) {
+		nodeID := p.path.NodeID
+		return &nodeID, ccode.OK
+	})
+}
+
+func (p *testBossActor) OnRecover(previousNodeID string) {
+	p.recovered <- previousNodeID
+}
+
+func (p *testBossActor) OnPassivate() {
+	time.Sleep(p.passivate)
+}
+
+func TestSingletonFailover(t *testing.T) {
+	recovered := make(chan string, 1)
+	systems := newTestNodes("game-1", "game-2", "game-3")
+
+	for _, system := range systems {
+		system.SetSingletonLease(20 * time.Millisecond)
+		system.RegisterSingleton("boss", "game", func() cfacade.IActorHandler {
+			return &testBossActor{recovered: recovered}
+		})
+	}
+
+	owner, found := systems["game-1"].SingletonOwner("boss")
+	if !found {
+		t.Fatal("singleton owner not elected")
+	}
+
+	for nodeID, system := range systems {
+		if _, running := system.GetActor("boss"); running != (nodeID == owner) {
+			t.Fatalf("boss running on %s = %v, owner = %s", nodeID, running, owner)
+		}
+	}
+
+	var caller string
+	for nodeID := range systems {
+		if nodeID != owner {
+			caller = nodeID
+			break
+		}
+	}
+
+	var nodeID string
+	if code := systems[caller].CallWait(caller+".tester", SingletonPath("boss"), "node", nil, &nodeID); code != ccode.OK || nodeID != owner {
+		t.Fatalf("call boss. [code = %d, node = %s, owner = %s]", code, nodeID, owner)
+	}
+
+	// 所属节点故障,其他节点接管并执行恢复函数
+	systems[owner].app.Discovery().(*testDiscovery).RemoveMember(owner)
+
+	select {
+	case previous := <-recovered:
+		if previous != owner {
+			t.Fatalf("recover previous = %s, want %s", previous, owner)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("singleton was not recovered")
+	}
+
+	newOwner, _ := systems[caller].SingletonOwner("boss")
+	if newOwner == owner {
+		t.Fatalf("owner not changed. [owner = %s]", owner)
+	}
+
+	if code := systems[caller].CallWait(caller+".tester", SingletonPath("boss"), "node", nil, &nodeID); code != ccode.OK || nodeID != newOwner {
+		t.Fatalf("call boss after failover. [code = %d, node = %s, owner = %s]", code, nodeID, newOwner)
+	}
+
+	// 原所属节点停止单例
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, running := systems[owner].GetActor("boss"); !running {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("previous owner still running singleton")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSingletonOwnerFlipBack(t *testing.T) {
+	systems := newTestNodes("game-1")
+	system := systems["game-1"]
+	system.SetSingletonLease(10 * time.Millisecond)
+	system.RegisterSingleton("boss", "game", func() cfacade.IActorHandler {
+		return &testBossActor{recovered: make(chan string, 1), passivate: 50 * time.Millisecond}
+	})
+
+	if _, found := system.GetActor("boss"); !found {
+		t.Fatal("singleton not started")
+	}
+
+	// 所属节点变为其他节点后,在原actor退出前又变回本节点
+	flip := func(owner string) {
+		system.singletons.Lock()
+		defer system.singletons.Unlock()
+
+		item := system.singletons.items["boss"]
+		previous := item.owner
+		item.owner = owner
+		item.leaseTerm++
+
+		if owner != system.NodeId() {
+			system.singletons.release(item)
+			return
+		}
+
+		discovery := system.app.Discovery()
+		if start := system.singletons.acquire(item, previous, discovery); start != nil {
+			go start()
+		}
+	}
+
+	flip("game-2")
+	flip("game-1")
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		system.singletons.Lock()
+		running := system.singletons.items["boss"].running
+		system.singletons.Unlock()
+
+		var nodeID string
+		if running && system.CallWait(".tester", SingletonPath("boss"), "node", nil, &nodeID) == ccode.OK {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("singleton not restarted after owner flipped back")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
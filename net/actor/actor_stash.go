@@ -0,0 +1,121 @@
+package cherryActor
+
+import (
+	creflect "github.com/cherry-game/cherry/extend/reflect"
+	cfacade "github.com/cherry-game/cherry/facade"
+)
+
+type (
+	// stashMessage 暂存的消息及其所属通道
+	stashMessage struct {
+		lane    Lane
+		message *cfacade.Message
+	}
+
+	// Behavior 一组可整体切换的执行函数(Become)
+	// 切换后actor的local/remote消息使用Behavior中注册的函数执行,actor内部函数不受影响
+	Behavior struct {
+		local  mailbox
+		remote mailbox
+	}
+)
+
+// NewBehavior 创建Behavior,通过Local()/Remote()注册函数
+func NewBehavior() *Behavior {
+	return &Behavior{
+		local:  newMailbox(LocalName),
+		remote: newMailbox(RemoteName),
+	}
+}
+
+func (b *Behavior) Local() IMailBox {
+	return &b.local
+}
+
+func (b *Behavior) Remote() IMailBox {
+	return &b.remote
+}
+
+// Stash 暂存当前正在处理的消息,之后通过Unstash/UnstashAll按顺序重新处理
+// 在OnLocalReceived/OnRemoteReceived中调用,并返回next=false,invoke=false
+// actor停止时未恢复的消息记为死信
+func (p *Actor) Stash(m *cfacade.Message) {
+	if m == nil {
+		return
+	}
+
+	p.stash = append(p.stash, stashMessage{
+		lane:    p.currentLane,
+		message: m,
+	})
+}
+
+// Unstash 恢复最早暂存的一条消息,该消息在邮箱中的其他消息之前处理
+func (p *Actor) Unstash() bool {
+	if len(p.stash) < 1 {
+		return false
+	}
+
+	p.unstashed = append(p.unstashed, p.stash[0])
+	p.stash = p.stash[1:]
+	return true
+}
+
+// UnstashAll 按暂存顺序恢复所有消息,返回恢复的数量
+func (p *Actor) UnstashAll() int {
+	count := len(p.stash)
+	p.unstashed = append(p.unstashed, p.stash...)
+	p.stash = nil
+	return count
+}
+
+// StashSize 暂存的消息数量
+func (p *Actor) StashSize() int {
+	return len(p.stash)
+}
+
+// Become 切换执行函数,之后的local/remote消息使用behavior中的函数执行
+func (p *Actor) Become(behavior *Behavior) {
+	p.behavior = behavior
+}
+
+// Unbecome 恢复使用actor默认注册的执行函数
+func (p *Actor) Unbecome() {
+	p.behavior = nil
+}
+
+// findFunc 获取执行函数.切换了Behavior时使用Behavior中的函数,actor内部函数始终使用默认注册的函数
+func (p *Actor) findFunc(mb *mailbox, funcName string) (*creflect.FuncInfo, bool) {
+	if p.behavior == nil || isInternalFunc(funcName) {
+		return mb.GetFuncInfo(funcName)
+	}
+
+	if mb == p.localMail {
+		return p.behavior.local.GetFuncInfo(funcName)
+	}
+
+	return p.behavior.remote.GetFuncInfo(funcName)
+}
+
+// processUnstashed 处理一条已恢复的暂存消息
+func (p *Actor) processUnstashed() {
+	item := p.unstashed[0]
+	p.unstashed[0] = stashMessage{}
+	p.unstashed = p.unstashed[1:]
+
+	if item.lane == LocalLane {
+		p.receiveLocal(item.message)
+	} else {
+		p.receiveRemote(item.message, item.lane)
+	}
+}
+
+// discardStash actor停止时丢弃未处理的暂存消息
+func (p *Actor) discardStash() {
+	for _, item := range append(p.unstashed, p.stash...) {
+		p.system.deadLetter(item.message, item.lane == LocalLane, StashDiscardedReason)
+	}
+
+	p.unstashed = nil
+	p.stash = nil
+}
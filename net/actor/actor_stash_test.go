@@ -0,0 +1,143 @@
+package cherryActor
+
+import (
+	"reflect"
+	"testing"
+
+	cfacade "github.com/cherry-game/cherry/facade"
+)
+
+type testStashActor struct {
+	Base
+	ready bool
+	steps []string
+}
+
+func (p *testStashActor) OnInit() {
+	p.Remote().Register("work", p.work)
+	p.Remote().Register("admin", p.admin)
+	p.Remote().Register("ready", p.setReady)
+	p.Remote().Register("become", p.become)
+	p.SetSystemFunc("admin")
+}
+
+func (p *testStashActor) OnRemoteReceived(m *cfacade.Message) (next bool, invoke bool) {
+	if !p.ready && m.FuncName == "work" {
+		p.Stash(m)
+		return false, false
+	}
+	return true, false
+}
+
+func (p *testStashActor) work(name string) {
+	p.steps = append(p.steps, name)
+}
+
+func (p *testStashActor) admin(name string) {
+	p.steps = append(p.steps, "admin:"+name)
+}
+
+func (p *testStashActor) setReady() {
+	p.ready = true
+	p.UnstashAll()
+}
+
+func (p *testStashActor) become() {
+	behavior := NewBehavior()
+	behavior.Remote().Register("work", func(name string) {
+		p.steps = append(p.steps, "become:"+name)
+	})
+	p.Become(behavior)
+}
+
+func newTestStashSystem(t *testing.T) (*System, *testStashActor) {
+	system := NewSystem()
+	system.SetApp(&testApp{})
+	system.SetManualRun(true)
+
+	handler := &testStashActor{}
+	if _, err := system.CreateActor("stash", handler); err != nil {
+		t.Fatal(err)
+	}
+
+	return system, handler
+}
+
+func TestSystemLane(t *testing.T) {
+	system, handler := newTestStashSystem(t)
+	handler.ready = true
+
+	system.Call(".source", ".stash", "work", "1")
+	system.Call(".source", ".stash", "work", "2")
+	system.Call(".source", ".stash", "admin", "3")
+	system.RunPending()
+
+	want := []string{"admin:3", "1", "2"}
+	if !reflect.DeepEqual(handler.steps, want) {
+		t.Fatalf("steps = %v, want %v", handler.steps, want)
+	}
+}
+
+func TestStash(t *testing.T) {
+	system, handler := newTestStashSystem(t)
+
+	system.Call(".source", ".stash", "work", "1")
+	system.Call(".source", ".stash", "work", "2")
+	system.Call(".source", ".stash", "ready", nil)
+	system.Call(".source", ".stash", "work", "3")
+	system.RunPending()
+
+	want := []string{"1", "2", "3"}
+	if !reflect.DeepEqual(handler.steps, want) {
+		t.Fatalf("steps = %v, want %v", handler.steps, want)
+	}
+}
+
+func TestStashDiscarded(t *testing.T) {
+	system, handler := newTestStashSystem(t)
+
+	system.Call(".source", ".stash", "work", "1")
+	system.RunPending()
+
+	if handler.StashSize() != 1 {
+		t.Fatalf("stash size = %d", handler.StashSize())
+	}
+
+	handler.Exit()
+	system.RunPending()
+
+	letters := system.DeadLetters(func(letter *DeadLetter) bool {
+		return letter.Reason == StashDiscardedReason
+	}, 0)
+
+	if len(letters) != 1 || letters[0].FuncName != "work" {
+		t.Fatalf("letters = %+v", letters)
+	}
+}
+
+func TestBecome(t *testing.T) {
+	system, handler := newTestStashSystem(t)
+	handler.ready = true
+
+	system.Call(".source", ".stash", "work", "1")
+	system.Call(".source", ".stash", "become", nil)
+	system.Call(".source", ".stash", "work", "2")
+	system.RunPending()
+
+	handler.Unbecome()
+	system.Call(".source", ".stash", "work", "3")
+	system.RunPending()
+
+	want := []string{"1", "become:2", "3"}
+	if !reflect.DeepEqual(handler.steps, want) {
+		t.Fatalf("steps = %v, want %v", handler.steps, want)
+	}
+}
+
+func TestNormalizeLanes(t *testing.T) {
+	lanes := normalizeLanes([]Lane{LocalLane, LocalLane, Lane(9), SystemLane})
+	want := []Lane{LocalLane, SystemLane, RemoteLane, EventLane}
+	if !reflect.DeepEqual(lanes, want) {
+		t.Fatalf("lanes = %v, want %v", lanes, want)
+	}
+}
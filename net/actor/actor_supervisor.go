@@ -0,0 +1,295 @@
+package cherryActor
+
+import (
+	"fmt"
+	"time"
+
+	cutils "github.com/cherry-game/cherry/extend/utils"
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+)
+
+const (
+	restartFuncName     = "_restart_"
+	childFailedFuncName = "_childFailed_"
+)
+
+const (
+	OneForOne StrategyKind = 0 // 只重启发生异常的actor
+	AllForOne StrategyKind = 1 // 重启发生异常的actor及其所有兄弟actor(顶层actor的兄弟为handler类型相同的其他顶层actor)
+)
+
+const (
+	RestartDirective  Directive = 0 // 重启actor
+	ResumeDirective   Directive = 1 // 忽略异常,继续处理后续消息
+	StopDirective     Directive = 2 // 停止actor
+	EscalateDirective Directive = 3 // 停止actor,并将异常上报给父actor
+)
+
+type (
+	StrategyKind int
+	Directive    int
+
+	// SupervisorStrategy 监督策略
+	// 父actor通过SetSupervisorStrategy()设置子actor的监督策略
+	// System通过SetSupervisorStrategy()设置顶层actor的监督策略
+	SupervisorStrategy struct {
+		Kind        StrategyKind                       // 策略类型
+		MaxRestarts int                                // 时间窗口内最大重启次数,超过则上报异常(0为不限制)
+		Within      time.Duration                      // 时间窗口(0为不限制)
+		Decider     func(reason interface{}) Directive // 根据异常决定处理方式(nil则默认重启)
+	}
+
+	actorSupervisor struct {
+		thisActor *Actor
+		strategy  *SupervisorStrategy // 子actor的监督策略
+		restartAt []time.Time         // 当前actor的重启时间
+	}
+)
+
+func NewOneForOneStrategy(maxRestarts int, within time.Duration) *SupervisorStrategy {
+	return &SupervisorStrategy{
+		Kind:        OneForOne,
+		MaxRestarts: maxRestarts,
+		Within:      within,
+	}
+}
+
+func NewAllForOneStrategy(maxRestarts int, within time.Duration) *SupervisorStrategy {
+	return &SupervisorStrategy{
+		Kind:        AllForOne,
+		MaxRestarts: maxRestarts,
+		Within:      within,
+	}
+}
+
+func (p *SupervisorStrategy) decide(reason interface{}) Directive {
+	if p.Decider == nil {
+		return RestartDirective
+	}
+	return p.Decider(reason)
+}
+
+func newSupervisor(thisActor *Actor) actorSupervisor {
+	return actorSupervisor{
+		thisActor: thisActor,
+	}
+}
+
+func (p *actorSupervisor) onStop() {
+	p.strategy = nil
+	p.restartAt = nil
+}
+
+// allowRestart 判断时间窗口内的重启次数是否超过限制
+func (p *actorSupervisor) allowRestart(strategy *SupervisorStrategy, now time.Time) bool {
+	if strategy.MaxRestarts < 1 {
+		return true
+	}
+
+	if strategy.Within > 0 {
+		deadline := now.Add(-strategy.Within)
+		index := 0
+		for index < len(p.restartAt) && !p.restartAt[index].After(deadline) {
+			index++
+		}
+		p.restartAt = p.restartAt[index:]
+	}
+
+	if len(p.restartAt) >= strategy.MaxRestarts {
+		return false
+	}
+
+	p.restartAt = append(p.restartAt, now)
+	return true
+}
+
+// _restart_ 收到兄弟actor的重启通知(AllForOne)
+func (p *actorSupervisor) _restart_(reason string) {
+	p.thisActor.restart(reason)
+}
+
+// _childFailed_ 子actor上报的异常,由当前actor的监督策略处理
+func (p *actorSupervisor) _childFailed_(reason string) {
+	p.thisActor.onFailure(reason)
+}
+
+// SetSupervisorStrategy 设置子actor的监督策略
+func (p *Actor) SetSupervisorStrategy(strategy *SupervisorStrategy) {
+	p.supervisor.strategy = strategy
+}
+
+// supervisorStrategy 子actor使用父actor的监督策略,顶层actor使用System的监督策略
+func (p *Actor) supervisorStrategy() *SupervisorStrategy {
+	if p.path.IsParent() {
+		return p.system.supervisorStrategy
+	}
+
+	if parent, found := p.system.GetActor(p.path.ActorID); found {
+		return parent.supervisor.strategy
+	}
+
+	return nil
+}
+
+// onFailure 处理函数执行时发生的异常
+func (p *Actor) onFailure(reason interface{}) {
+	strategy := p.supervisorStrategy()
+	if strategy == nil {
+		// 未设置监督策略,仅记录日志
+		return
+	}
+
+	switch strategy.decide(reason) {
+	case ResumeDirective:
+		{
+			clog.Warnf("[onFailure] Resume actor. [path = %s, reason = %v]", p.path, reason)
+		}
+	case StopDirective:
+		{
+			clog.Warnf("[onFailure] Stop actor. [path = %s, reason = %v]", p.path, reason)
+			p.stop()
+		}
+	case EscalateDirective:
+		{
+			p.escalate(reason)
+		}
+	default:
+		{
+			if !p.supervisor.allowRestart(strategy, time.Now()) {
+				clog.Errorf("[onFailure] Restart limit exceeded. [path = %s, maxRestarts = %d, within = %v]",
+					p.path,
+					strategy.MaxRestarts,
+					strategy.Within,
+				)
+				p.escalate(reason)
+				return
+			}
+
+			if strategy.Kind == AllForOne {
+				p.restartSiblings(reason)
+			}
+
+			p.restart(reason)
+		}
+	}
+}
+
+// escalate 停止当前actor,并将异常上报给父actor
+func (p *Actor) escalate(reason interface{}) {
+	clog.Errorf("[escalate] Stop actor. [path = %s, reason = %v]", p.path, reason)
+
+	p.stop()
+
+	if p.path.IsChild() {
+		if parent, found := p.system.GetActor(p.path.ActorID); found {
+			parent.postSystem(childFailedFuncName, fmt.Sprint(reason))
+		}
+	}
+}
+
+// restartSiblings 通知所有兄弟actor重启
+// 子actor的兄弟为同一父actor下的其他子actor,顶层actor的兄弟为handler类型相同的其他顶层actor(不包括系统actor)
+func (p *Actor) restartSiblings(reason interface{}) {
+	restartFn := func(sibling *Actor) {
+		if sibling != p && sibling.State() == WorkerState {
+			sibling.postSystem(restartFuncName, fmt.Sprint(reason))
+		}
+	}
+
+	if p.path.IsParent() {
+		p.system.actorMap.Range(func(_, value any) bool {
+			sibling, ok := value.(*Actor)
+			if ok && sibling.path.ActorID != SystemActorID && sibling.typeName == p.typeName {
+				restartFn(sibling)
+			}
+			return true
+		})
+		return
+	}
+
+	if parent, found := p.system.GetActor(p.path.ActorID); found {
+		parent.child.Each(func(iActor cfacade.IActor) {
+			if sibling, ok := iActor.(*Actor); ok {
+				restartFn(sibling)
+			}
+		})
+	}
+}
+
+// restart 在当前goroutine中重启actor
+// 执行旧handler的OnStop(),清理已注册的函数、事件、定时器,然后重新执行OnInit()
+// handler实现IActorHandlerFactory时使用新创建的实例,否则复用原实例(保留构造时注入的字段)
+// 邮箱中未处理的消息以及子actor会被保留,持久化actor会从存储中重新加载状态(未保存的修改将丢失)
+func (p *Actor) restart(reason interface{}) {
+	clog.Warnf("[restart] Restart actor. [path = %s, reason = %v]", p.path, reason)
+
+	cutils.Try(func() {
+		p.handler.OnStop()
+	}, func(errString string) {
+		clog.Errorf("[restart] OnStop error. [path = %s, err = %s]", p.path, errString)
+	})
+
+	p.timer.reset()
+	p.localMail.clearFunc()
+	p.remoteMail.clearFunc()
+	p.interceptors = nil
+	p.event.clearFunc()
+	p.supervisor.strategy = nil
+	p.behavior = nil
+	p.systemFuncs.Range(func(key, _ any) bool {
+		p.systemFuncs.Delete(key)
+		return true
+	})
+
+	// 暂存的消息交由新的handler重新处理
+	p.UnstashAll()
+
+	p.handler = newHandlerInstance(p.handler)
+	if persistent, ok := p.handler.(persistentHandler); ok {
+		persistent.resetState()
+	}
+	p.registerSystemFunc()
+
+	if actorLoad, ok := p.handler.(IActorLoader); ok {
+		actorLoad.load(p)
+	}
+
+	cutils.Try(func() {
+		p.handler.OnInit()
+		p.loadState()
+	}, func(errString string) {
+		clog.Errorf("[restart] OnInit error. [path = %s, err = %s]", p.path, errString)
+	})
+}
+
+// stop 在当前goroutine中停止actor
+func (p *Actor) stop() {
+	select {
+	case p.close <- struct{}{}:
+	default:
+	}
+}
+
+// postSystem 向actor投递内部函数调用
+func (p *Actor) postSystem(funcName string, arg interface{}) {
+	message := cfacade.GetMessage()
+	message.Source = p.path.String()
+	message.Target = p.path.String()
+	message.FuncName = funcName
+	message.Args = arg
+
+	p.PostRemote(&message)
+}
+
+// newHandlerInstance 获取重启后使用的handler实例
+// 实现IActorHandlerFactory时使用其创建的新实例,否则复用原实例
+func newHandlerInstance(handler cfacade.IActorHandler) cfacade.IActorHandler {
+	if factory, ok := handler.(IActorHandlerFactory); ok {
+		if newHandler := factory.NewHandler(); newHandler != nil {
+			return newHandler
+		}
+	}
+
+	return handler
+}
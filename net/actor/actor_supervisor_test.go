@@ -0,0 +1,113 @@
+package cherryActor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	ccode "github.com/cherry-game/cherry/code"
+)
+
+func TestSupervisorAllowRestart(t *testing.T) {
+	supervisor := &actorSupervisor{}
+	strategy := NewOneForOneStrategy(2, time.Minute)
+
+	now := time.Now()
+	if !supervisor.allowRestart(strategy, now) || !supervisor.allowRestart(strategy, now) {
+		t.Fatal("restart should be allowed within the limit")
+	}
+
+	if supervisor.allowRestart(strategy, now) {
+		t.Fatal("restart should be denied when the limit is exceeded")
+	}
+
+	if !supervisor.allowRestart(strategy, now.Add(2*time.Minute)) {
+		t.Fatal("restart should be allowed after the window has passed")
+	}
+}
+
+func TestNewHandlerInstance(t *testing.T) {
+	handler := &testActor{}
+	if newHandler := newHandlerInstance(handler); newHandler != handler {
+		t.Fatal("handler without factory should be reused")
+	}
+}
+
+type (
+	testCrashActor struct {
+		Base
+		name  string // 构造时注入的字段
+		inits int32
+	}
+
+	testOtherCrashActor struct {
+		testCrashActor
+	}
+)
+
+func (p *testCrashActor) OnInit() {
+	p.inits++
+	p.Remote().Register("crash", p.crash)
+	p.Remote().Register("info", p.info)
+}
+
+func (p *testCrashActor) crash() {
+	panic("crash")
+}
+
+func (p *testCrashActor) info() (*string, int32) {
+	info := fmt.Sprintf("%s-%d", p.name, p.inits)
+	return &info, ccode.OK
+}
+
+func callInfo(t *testing.T, system *System, target string) string {
+	var info string
+	if code := system.CallWait(".source", target, "info", nil, &info); code != ccode.OK {
+		t.Fatalf("%s code = %d", target, code)
+	}
+	return info
+}
+
+func TestSupervisorRestart(t *testing.T) {
+	system := NewSystem()
+	system.SetApp(&testApp{})
+	system.SetSupervisorStrategy(NewOneForOneStrategy(3, time.Minute))
+	system.CreateActor("player", &testCrashActor{name: "player"})
+
+	system.Call(".source", ".player", "crash", nil)
+
+	// 重启后保留注入的字段,并继续处理后续消息
+	if info := callInfo(t, system, ".player"); info != "player-2" {
+		t.Fatalf("info = %s", info)
+	}
+}
+
+func TestSupervisorAllForOne(t *testing.T) {
+	system := NewSystem()
+	system.SetApp(&testApp{})
+	system.SetSupervisorStrategy(NewAllForOneStrategy(3, time.Minute))
+	system.CreateActor("player1", &testCrashActor{name: "player1"})
+	system.CreateActor("player2", &testCrashActor{name: "player2"})
+	system.CreateActor("other", &testOtherCrashActor{testCrashActor{name: "other"}})
+
+	// 等待所有actor启动
+	callInfo(t, system, ".player2")
+	callInfo(t, system, ".other")
+
+	system.Call(".source", ".player1", "crash", nil)
+
+	if info := callInfo(t, system, ".player1"); info != "player1-2" {
+		t.Fatalf("info = %s", info)
+	}
+
+	// 兄弟actor通过系统通道重启,等待其处理完成
+	time.Sleep(50 * time.Millisecond)
+	if info := callInfo(t, system, ".player2"); info != "player2-2" {
+		t.Fatalf("info = %s", info)
+	}
+
+	// 不同类型的顶层actor不受影响
+	if info := callInfo(t, system, ".other"); info != "other-1" {
+		t.Fatalf("info = %s", info)
+	}
+}
@@ -1,6 +1,7 @@
 package cherryActor
 
 import (
+	"sync"
 	"time"
 
 	cherryTimeWheel "github.com/cherry-game/cherry/extend/time_wheel"
@@ -16,10 +17,11 @@ type (
 	actorTimer struct {
 		thisActor    *Actor
 		timerInfoMap map[uint64]*timerInfo //key:timerId,value:*timerInfo
+		mu           sync.RWMutex          // 修改timerInfoMap时与Count()互斥
 	}
 
 	timerInfo struct {
-		timer *cherryTimeWheel.Timer
+		timer ITimerTask
 		fn    func()
 		once  bool
 	}
@@ -44,14 +46,14 @@ func (p *actorTimer) Add(delay time.Duration, fn func(), async ...bool) uint64 {
 	}
 
 	newId := globalTimer.NextId()
-	timer := globalTimer.AddEveryFunc(newId, delay, p.callUpdateTimer(newId), async...)
+	timer := p.scheduler().ScheduleFunc(newId, &cherryTimeWheel.EverySchedule{Interval: delay}, p.callUpdateTimer(newId), async...)
 
 	if timer == nil {
 		clog.Warnf("[ActorTimer] Add error. delay = %+v", delay)
 		return 0
 	}
 
-	p.addTimerInfo(timer, fn, false)
+	p.addTimerInfo(newId, timer, fn, false)
 
 	return newId
 }
@@ -63,14 +65,14 @@ func (p *actorTimer) AddOnce(delay time.Duration, fn func(), async ...bool) uint
 	}
 
 	newId := globalTimer.NextId()
-	timer := globalTimer.AfterFunc(newId, delay, p.callUpdateTimer(newId), async...)
+	timer := p.scheduler().AfterFunc(newId, delay, p.callUpdateTimer(newId), async...)
 
 	if timer == nil {
 		clog.Warnf("[ActorTimer] AddOnce error. d = %+v", delay)
 		return 0
 	}
 
-	p.addTimerInfo(timer, fn, true)
+	p.addTimerInfo(newId, timer, fn, true)
 
 	return newId
 }
@@ -95,35 +97,72 @@ func (p *actorTimer) AddSchedule(s ITimerSchedule, fn func(), async ...bool) uin
 	}
 
 	newId := globalTimer.NextId()
-	timer := globalTimer.ScheduleFunc(newId, s, p.callUpdateTimer(newId), async...)
+	timer := p.scheduler().ScheduleFunc(newId, s, p.callUpdateTimer(newId), async...)
 
-	p.addTimerInfo(timer, fn, false)
+	if timer == nil {
+		clog.Warnf("[ActorTimer] AddSchedule error. schedule = %+v", s)
+		return 0
+	}
+
+	p.addTimerInfo(newId, timer, fn, false)
 
 	return newId
 }
 
+// scheduler 定时器的调度器
+func (p *actorTimer) scheduler() ITimerScheduler {
+	return p.thisActor.system.timerScheduler
+}
+
 func (p *actorTimer) Remove(id uint64) {
 	funcItem, found := p.timerInfoMap[id]
 	if found {
 		funcItem.timer.Stop()
-		delete(p.timerInfoMap, id)
+		p.deleteTimerInfo(id)
 	}
 }
 
+// Count 当前定时器数量
+func (p *actorTimer) Count() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return len(p.timerInfoMap)
+}
+
+// reset 移除所有定时器并清空
+func (p *actorTimer) reset() {
+	p.RemoveAll()
+
+	p.mu.Lock()
+	p.timerInfoMap = make(map[uint64]*timerInfo)
+	p.mu.Unlock()
+}
+
 func (p *actorTimer) RemoveAll() {
 	for _, info := range p.timerInfoMap {
 		info.timer.Stop()
 	}
 }
 
-func (p *actorTimer) addTimerInfo(timer *cherryTimeWheel.Timer, fn func(), once bool) {
-	p.timerInfoMap[timer.ID()] = &timerInfo{
+func (p *actorTimer) addTimerInfo(id uint64, timer ITimerTask, fn func(), once bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.timerInfoMap[id] = &timerInfo{
 		timer: timer,
 		fn:    fn,
 		once:  once,
 	}
 }
 
+func (p *actorTimer) deleteTimerInfo(id uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.timerInfoMap, id)
+}
+
 func (p *actorTimer) callUpdateTimer(id uint64) func() {
 	return func() {
 		p.thisActor.Call(p.thisActor.PathString(), updateTimerFuncName, id)
@@ -143,6 +182,6 @@ func (p *actorTimer) _updateTimer_(id uint64) {
 	})
 
 	if value.once {
-		delete(p.timerInfoMap, id)
+		p.deleteTimerInfo(id)
 	}
 }
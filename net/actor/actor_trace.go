@@ -0,0 +1,78 @@
+package cherryActor
+
+import (
+	"context"
+	"strconv"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cfacade "github.com/cherry-game/cherry/facade"
+	ctrace "github.com/cherry-game/cherry/net/trace"
+)
+
+// TraceParent 当前正在处理的消息的W3C traceparent,可用于日志关联
+// 在actor内发起的Call/CallWait会自动携带该trace上下文
+func (p *Actor) TraceParent() string {
+	traceParent, _ := p.traceParent.Load().(string)
+	return traceParent
+}
+
+// traceContext 将当前的trace上下文保存到ctx(ctx中已存在时不覆盖)
+func (p *Actor) traceContext(ctx context.Context) context.Context {
+	if ctrace.FromContext(ctx) != "" {
+		return ctx
+	}
+	return ctrace.WithTraceParent(ctx, p.TraceParent())
+}
+
+func (p *Actor) startInvokeSpan(mb *mailbox, m *cfacade.Message) *ctrace.Span {
+	parent := messageTraceParent(m)
+
+	var span *ctrace.Span
+	if !isInternalFunc(m.FuncName) {
+		span = ctrace.StartSpan("invoke "+m.FuncName, ctrace.ServerSpan, parent)
+		span.SetAttribute("actor.path", p.path.String())
+		span.SetAttribute("actor.mailbox", mb.name)
+		span.SetAttribute("actor.source", m.Source)
+	}
+
+	p.traceParent.Store(ctrace.ChildTraceParent(span, parent))
+	return span
+}
+
+func (p *Actor) endInvokeSpan(span *ctrace.Span) {
+	p.traceParent.Store("")
+	span.End()
+}
+
+// startCallSpan 创建跨节点调用的span
+func startCallSpan(name, traceParent, source, target, funcName string) *ctrace.Span {
+	span := ctrace.StartSpan(name+" "+funcName, ctrace.ClientSpan, traceParent)
+	span.SetAttribute("actor.source", source)
+	span.SetAttribute("actor.target", target)
+	return span
+}
+
+func endCallSpan(span *ctrace.Span, code int32) {
+	if span == nil {
+		return
+	}
+
+	span.SetAttribute("code", strconv.Itoa(int(code)))
+	if ccode.IsFail(code) {
+		span.SetError("code = " + strconv.Itoa(int(code)))
+	}
+	span.End()
+}
+
+// messageTraceParent 消息的trace上下文,未设置时使用session中的trace上下文
+func messageTraceParent(m *cfacade.Message) string {
+	if m.TraceParent != "" {
+		return m.TraceParent
+	}
+
+	if m.Session != nil {
+		return m.Session.TraceParent
+	}
+
+	return ""
+}
@@ -0,0 +1,96 @@
+package cherryActor
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	ccode "github.com/cherry-game/cherry/code"
+	ctrace "github.com/cherry-game/cherry/net/trace"
+)
+
+type (
+	testTraceExporter struct {
+		sync.Mutex
+		names []string
+		trace map[string]bool
+	}
+
+	testFrontActor struct {
+		Base
+	}
+
+	testCenterActor struct {
+		Base
+	}
+
+	testTraceReply struct {
+		TraceParent string
+	}
+)
+
+func (p *testTraceExporter) Export(span *ctrace.Span) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.names = append(p.names, span.Name)
+	p.trace[span.Context.TraceIDString()] = true
+}
+
+func (p *testTraceExporter) Close() error {
+	return nil
+}
+
+func (p *testFrontActor) OnInit() {
+	p.Remote().Register("login", func() (*testTraceReply, int32) {
+		reply := &testTraceReply{}
+		code := p.CallWait("game-2.center", "auth", nil, reply)
+		return reply, code
+	})
+}
+
+func (p *testCenterActor) OnInit() {
+	p.Remote().Register("auth", func() (*testTraceReply, int32) {
+		return &testTraceReply{TraceParent: p.TraceParent()}, ccode.OK
+	})
+}
+
+func TestTracePropagation(t *testing.T) {
+	exporter := &testTraceExporter{trace: map[string]bool{}}
+	ctrace.SetExporter(exporter)
+	defer ctrace.SetExporter(nil)
+
+	systems := newTestNodes("game-1", "game-2")
+	systems["game-1"].CreateActor("front", &testFrontActor{})
+	systems["game-2"].CreateActor("center", &testCenterActor{})
+
+	root := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := ctrace.WithTraceParent(context.Background(), root)
+
+	reply := &testTraceReply{}
+	if code := systems["game-1"].CallWaitContext(ctx, "game-1.tester", "game-1.front", "login", nil, reply); code != ccode.OK {
+		t.Fatalf("login code = %d", code)
+	}
+
+	if ctrace.TraceID(reply.TraceParent) != ctrace.TraceID(root) || reply.TraceParent == root {
+		t.Fatalf("center trace parent = %s", reply.TraceParent)
+	}
+
+	exporter.Lock()
+	defer exporter.Unlock()
+
+	if len(exporter.trace) != 1 || !exporter.trace[ctrace.TraceID(root)] {
+		t.Fatalf("traces = %v", exporter.trace)
+	}
+
+	found := false
+	for _, name := range exporter.names {
+		if name == "request auth" {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatalf("spans = %v", exporter.names)
+	}
+}
@@ -24,6 +24,9 @@ func (c *Component) Name() string {
 
 func (c *Component) Init() {
 	c.System.SetApp(c.App())
+	c.System.placement.load()
+	c.System.singletons.load()
+	c.System.createSystemActor()
 }
 
 func (c *Component) OnAfterInit() {
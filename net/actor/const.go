@@ -13,4 +13,6 @@ var (
 const (
 	LocalName  = "local"
 	RemoteName = "remote"
+	EventName  = "event"
+	SystemName = "system"
 )
@@ -0,0 +1,238 @@
+package cherryActor
+
+import (
+	"sync"
+	"time"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cfacade "github.com/cherry-game/cherry/facade"
+	cproto "github.com/cherry-game/cherry/net/proto"
+)
+
+const (
+	ActorNotFoundReason   DeadLetterReason = 1 // 目标actor不存在
+	ActorNotWorkingReason DeadLetterReason = 2 // 目标actor不在工作状态
+	ChildNotFoundReason   DeadLetterReason = 3 // 目标子actor不存在
+	FuncNotFoundReason    DeadLetterReason = 4 // 函数未注册
+	EventNotFoundReason   DeadLetterReason = 5 // 事件没有注册处理函数
+	MailboxFullReason     DeadLetterReason = 6 // 邮箱已满
+	StashDiscardedReason  DeadLetterReason = 7 // actor停止时暂存的消息未处理
+)
+
+const (
+	defaultDeadLetterSize = 1024 // 默认保留的死信数量
+)
+
+type (
+	DeadLetterReason int
+
+	// DeadLetter 无法投递或执行的消息
+	DeadLetter struct {
+		Source   string             // 来源actor path
+		Target   string             // 目标actor path
+		FuncName string             // 函数名或事件名
+		Reason   DeadLetterReason   // 原因
+		Time     int64              // 时间(ms)
+		Message  *cfacade.Message   // 原消息(仅监听函数可用,不保存到缓存)
+		Event    cfacade.IEventData // 原事件(仅监听函数可用,不保存到缓存)
+	}
+
+	// DeadLetterListener 死信监听函数
+	DeadLetterListener func(letter *DeadLetter)
+
+	// deadLetters 死信缓存(环形队列)
+	deadLetters struct {
+		sync.RWMutex
+		letters   []DeadLetter
+		index     int
+		count     int
+		listeners []DeadLetterListener
+		response  bool // 客户端请求失败时返回错误码
+	}
+)
+
+func (r DeadLetterReason) String() string {
+	switch r {
+	case ActorNotFoundReason:
+		return "actor not found"
+	case ActorNotWorkingReason:
+		return "actor not working"
+	case ChildNotFoundReason:
+		return "child actor not found"
+	case FuncNotFoundReason:
+		return "function not found"
+	case EventNotFoundReason:
+		return "event not found"
+	case MailboxFullReason:
+		return "mailbox full"
+	case StashDiscardedReason:
+		return "stash discarded"
+	}
+	return "unknown"
+}
+
+// code 返回给调用方的错误码
+func (r DeadLetterReason) code() int32 {
+	switch r {
+	case FuncNotFoundReason:
+		return ccode.ActorFuncNameError
+	case ChildNotFoundReason:
+		return ccode.ActorChildIDNotFound
+	case MailboxFullReason:
+		return ccode.ActorMailboxFull
+	}
+	return ccode.ActorCallFail
+}
+
+func newDeadLetters(size int) *deadLetters {
+	return &deadLetters{
+		letters: make([]DeadLetter, size),
+	}
+}
+
+func (p *deadLetters) add(letter DeadLetter) {
+	p.Lock()
+	p.letters[p.index] = letter
+	p.index = (p.index + 1) % len(p.letters)
+	if p.count < len(p.letters) {
+		p.count++
+	}
+	p.Unlock()
+}
+
+// SetDeadLetterSize 设置保留的死信数量
+func (p *System) SetDeadLetterSize(size int) {
+	if size < 1 {
+		return
+	}
+
+	p.deadLetters.Lock()
+	p.deadLetters.letters = make([]DeadLetter, size)
+	p.deadLetters.index = 0
+	p.deadLetters.count = 0
+	p.deadLetters.Unlock()
+}
+
+// SetDeadLetterResponse 消息无法投递或执行时,是否返回错误码给调用方(客户端请求及CallWait)
+func (p *System) SetDeadLetterResponse(enable bool) {
+	p.deadLetters.response = enable
+}
+
+// OnDeadLetter 添加死信监听函数
+func (p *System) OnDeadLetter(listener DeadLetterListener) {
+	if listener == nil {
+		return
+	}
+
+	p.deadLetters.Lock()
+	p.deadLetters.listeners = append(p.deadLetters.listeners, listener)
+	p.deadLetters.Unlock()
+}
+
+// DeadLetters 查询死信(按时间倒序),filter为nil时返回全部,limit<=0时不限制数量
+func (p *System) DeadLetters(filter func(letter *DeadLetter) bool, limit int) []DeadLetter {
+	p.deadLetters.RLock()
+	defer p.deadLetters.RUnlock()
+
+	var list []DeadLetter
+	size := len(p.deadLetters.letters)
+
+	for i := 1; i <= p.deadLetters.count; i++ {
+		letter := p.deadLetters.letters[(p.deadLetters.index-i+size)%size]
+		if filter != nil && !filter(&letter) {
+			continue
+		}
+
+		list = append(list, letter)
+		if limit > 0 && len(list) >= limit {
+			break
+		}
+	}
+
+	return list
+}
+
+// deadLetter 记录无法投递或执行的消息
+func (p *System) deadLetter(m *cfacade.Message, isLocal bool, reason DeadLetterReason) {
+	letter := DeadLetter{
+		Source:   m.Source,
+		Target:   m.Target,
+		FuncName: m.FuncName,
+		Reason:   reason,
+		Time:     time.Now().UnixMilli(),
+	}
+
+	p.deadLetters.add(letter)
+	p.metrics.Load().observeDeadLetter(reason)
+
+	letter.Message = m
+	p.notifyDeadLetter(&letter)
+
+	if p.deadLetters.response {
+		p.replyCode(m, isLocal, reason.code())
+	}
+}
+
+// dropFunc 邮箱超出容量丢弃消息时记录死信,等待结果的调用方(CallWait)总是返回ActorMailboxFull
+func (p *System) dropFunc(isLocal bool) func(interface{}) {
+	return func(v interface{}) {
+		m, ok := v.(*cfacade.Message)
+		if !ok {
+			return
+		}
+
+		p.deadLetter(m, isLocal, MailboxFullReason)
+		if !p.deadLetters.response && (m.ChanResult != nil || m.IsReply()) {
+			p.replyCode(m, isLocal, ccode.ActorMailboxFull)
+		}
+	}
+}
+
+// deadEvent 记录没有处理函数的事件
+func (p *System) deadEvent(data cfacade.IEventData, target string) {
+	letter := DeadLetter{
+		Target:   target,
+		FuncName: data.Name(),
+		Reason:   EventNotFoundReason,
+		Time:     time.Now().UnixMilli(),
+	}
+
+	p.deadLetters.add(letter)
+	p.metrics.Load().observeDeadLetter(EventNotFoundReason)
+
+	letter.Event = data
+	p.notifyDeadLetter(&letter)
+}
+
+func (p *System) notifyDeadLetter(letter *DeadLetter) {
+	p.deadLetters.RLock()
+	listeners := p.deadLetters.listeners
+	p.deadLetters.RUnlock()
+
+	for _, listener := range listeners {
+		listener(letter)
+	}
+}
+
+// replyCode 返回错误码给调用方
+func (p *System) replyCode(m *cfacade.Message, isLocal bool, code int32) {
+	if m.ChanResult != nil {
+		retChanResult(m.ChanResult, &cproto.Response{Code: code})
+		return
+	}
+
+	if m.IsReply() {
+		retResponse(m.ClusterReply, &cproto.Response{Code: code})
+		return
+	}
+
+	if isLocal && m.Session != nil && m.Session.AgentPath != "" {
+		rsp := &cproto.PomeloResponse{
+			Sid:  m.Session.Sid,
+			Mid:  m.Session.Mid,
+			Code: code,
+		}
+
+		p.Call(m.Target, m.Session.AgentPath, ResponseFuncName, rsp)
+	}
+}
@@ -0,0 +1,94 @@
+package cherryActor
+
+import (
+	"testing"
+	"time"
+
+	ccode "github.com/cherry-game/cherry/code"
+)
+
+func TestDeadLetter(t *testing.T) {
+	system := NewSystem()
+	system.SetApp(&testApp{})
+	system.SetCallTimeout(time.Second)
+	system.SetDeadLetterResponse(true)
+	system.CreateActor("target", &testBlockActor{})
+
+	var notified []DeadLetterReason
+	system.OnDeadLetter(func(letter *DeadLetter) {
+		notified = append(notified, letter.Reason)
+	})
+
+	if code := system.CallWait(".source", ".missing", "block", nil, nil); code != ccode.ActorCallFail {
+		t.Fatalf("missing actor code = %d", code)
+	}
+
+	// 开启错误返回后,调用方无需等待超时
+	if code := system.CallWait(".source", ".target", "unknown", nil, nil); code != ccode.ActorFuncNameError {
+		t.Fatalf("unknown func code = %d", code)
+	}
+
+	letters := system.DeadLetters(nil, 0)
+	if len(letters) != 2 || len(notified) != 2 {
+		t.Fatalf("letters = %+v, notified = %v", letters, notified)
+	}
+
+	if letters[0].Reason != FuncNotFoundReason || letters[1].Reason != ActorNotFoundReason {
+		t.Fatalf("letters = %+v", letters)
+	}
+
+	if letters[0].Message != nil {
+		t.Fatal("message should not be kept in the ring")
+	}
+
+	letters = system.DeadLetters(func(letter *DeadLetter) bool {
+		return letter.Target == ".missing"
+	}, 1)
+	if len(letters) != 1 || letters[0].FuncName != "block" {
+		t.Fatalf("letters = %+v", letters)
+	}
+}
+
+func TestDeadLetterRing(t *testing.T) {
+	letters := newDeadLetters(2)
+	for i := 1; i <= 3; i++ {
+		letters.add(DeadLetter{Time: int64(i)})
+	}
+
+	system := NewSystem()
+	system.deadLetters = letters
+
+	list := system.DeadLetters(nil, 0)
+	if len(list) != 2 || list[0].Time != 3 || list[1].Time != 2 {
+		t.Fatalf("list = %+v", list)
+	}
+}
+
+func TestPostRemoteNotWorking(t *testing.T) {
+	system := NewSystem()
+	system.SetApp(&testApp{})
+	system.SetCallTimeout(time.Second)
+	system.CreateActor("target", &testInterceptActor{})
+
+	if code := system.CallWait(".source", ".target", "allow", nil, nil); code != ccode.OK {
+		t.Fatalf("allow code = %d", code)
+	}
+
+	targetActor, _ := system.GetActor("target")
+	targetActor.setState(FreeState)
+
+	// 目标actor不在工作状态时立即返回错误,不等待超时
+	begin := time.Now()
+	if code := system.CallWait(".source", ".target", "allow", nil, nil); code != ccode.ActorCallFail {
+		t.Fatalf("code = %d, want %d", code, ccode.ActorCallFail)
+	}
+
+	if time.Since(begin) > 100*time.Millisecond {
+		t.Fatal("call should fail fast")
+	}
+
+	letters := system.DeadLetters(nil, 1)
+	if len(letters) != 1 || letters[0].Reason != ActorNotWorkingReason {
+		t.Fatalf("letters = %+v", letters)
+	}
+}
@@ -5,11 +5,46 @@ import (
 
 	creflect "github.com/cherry-game/cherry/extend/reflect"
 	cfacade "github.com/cherry-game/cherry/facade"
+	cproto "github.com/cherry-game/cherry/net/proto"
 )
 
 type (
 	IActorLoader interface {
-		load(actor Actor)
+		load(actor *Actor)
+	}
+
+	// IActorHandlerFactory actor重启时,通过该接口创建新的handler实例(未实现则复用原实例并重新执行OnInit)
+	IActorHandlerFactory interface {
+		NewHandler() cfacade.IActorHandler
+	}
+
+	// IActorMailboxOptions 自定义actor的邮箱容量(未实现则使用System的默认设置)
+	IActorMailboxOptions interface {
+		MailboxOptions() MailboxOptions
+	}
+
+	// IActorLanePriority 自定义actor各消息通道的处理优先级(未实现则使用System的默认设置)
+	IActorLanePriority interface {
+		LanePriority() []Lane
+	}
+
+	// IActorPassivation actor空闲回收
+	IActorPassivation interface {
+		IdleTimeout() time.Duration // 空闲超时时间,返回0则使用System的设置
+		CanPassivate() bool         // 回收前触发,返回false则本次不回收
+		OnPassivate()               // 回收前触发,可在此保存数据
+	}
+
+	// IActorMigration actor跨节点迁移
+	// 迁移成功后源节点的actor会退出(仍会触发OnStop),可通过MigratedTo()判断是否已迁移
+	IActorMigration interface {
+		OnSnapshot() ([]byte, error) // 在源节点序列化actor的状态
+		OnRestore(data []byte) error // 在目标节点恢复actor的状态(在OnInit之后执行)
+	}
+
+	// IActorMigrationSessions actor迁移成功后,通知网关将这些session路由到目标节点
+	IActorMigrationSessions interface {
+		MigrationSessions() []*cproto.Session
 	}
 )
 
@@ -18,6 +53,8 @@ type (
 		Register(name string, fn IEventFunc)     // 注册事件
 		Registers(names []string, fn IEventFunc) // 注册多个事件
 		Unregister(name string)                  // 注销事件
+		Count() int32                            // 当前队列深度
+		Dropped() int64                          // 已丢弃的事件数量
 	}
 
 	IEventFunc func(cfacade.IEventData) // 接收事件数据时的处理函数
@@ -27,6 +64,8 @@ type (
 	IMailBox interface {
 		Register(funcName string, fn interface{}) // 注册执行函数
 		GetFuncInfo(funcName string) (*creflect.FuncInfo, bool)
+		Count() int32   // 当前邮箱深度
+		Dropped() int64 // 已丢弃/拒绝的消息数量
 	}
 )
 
@@ -39,6 +78,7 @@ type (
 		AddSchedule(s ITimerSchedule, f func(), async ...bool) uint64           // 添加自定义调度
 		Remove(id uint64)                                                       // 移除定时器
 		RemoveAll()                                                             // 移除所有定时器
+		Count() int                                                             // 当前定时器数量
 	}
 
 	ITimerSchedule interface {
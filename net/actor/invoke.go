@@ -38,12 +38,7 @@ func InvokeLocalFunc(app cfacade.IApplication, fi *creflect.FuncInfo, m *cfacade
 		return
 	}
 
-	EncodeLocalArgs(app, fi, m)
-
-	values := make([]reflect.Value, 2)
-	values[0] = reflect.ValueOf(m.Session) // session
-	values[1] = reflect.ValueOf(m.Args)    // args
-	resp, err, ok := PCall(fi, values)
+	resp, err, ok := callLocal(app, fi, m)
 	if !ok {
 		clog.Debugf("[InvokeLocalFunc]. function: %s is not standardization, target=%s",
 			m.FuncName, m.Target)
@@ -66,12 +61,7 @@ func AgentInvokeLocalFunc(app cfacade.IApplication, fi *creflect.FuncInfo, m *cf
 		return
 	}
 
-	EncodeLocalArgs(app, fi, m)
-
-	values := make([]reflect.Value, 2)
-	values[0] = reflect.ValueOf(m.Session) // session
-	values[1] = reflect.ValueOf(m.Args)    // args
-	resp, err, ok := PCall(fi, values)
+	resp, err, ok := callLocal(app, fi, m)
 	if !ok {
 		clog.Debugf("[AgentInvokeLocalFunc]. function: %s is not standardization, target=%s",
 			m.FuncName, m.Target)
@@ -101,17 +91,11 @@ func InvokeRemoteFunc(app cfacade.IApplication, fi *creflect.FuncInfo, m *cfacad
 		return
 	}
 
-	EncodeRemoteArgs(app, fi, m)
-
-	values := make([]reflect.Value, fi.InArgsLen)
-	if fi.InArgsLen > 0 {
-		values[0] = reflect.ValueOf(m.Args) // args
-	}
+	call := remoteCaller(app, fi, m)
 
 	if m.IsCluster {
 		cutils.Try(func() {
-			rets := fi.Value.Call(values)
-			rspCode, rspData := retValue(app.Serializer(), rets)
+			rspCode, rspData := call()
 
 			retResponse(m.ClusterReply, &cproto.Response{
 				Code: rspCode,
@@ -123,22 +107,24 @@ func InvokeRemoteFunc(app cfacade.IApplication, fi *creflect.FuncInfo, m *cfacad
 				Code: ccode.RPCRemoteExecuteError,
 			})
 			clog.Errorf("[InvokeRemoteFunc] invoke error. [message = %+v, err = %s]", m, errString)
+
+			// 交由actor的监督策略处理
+			panic(errString)
 		})
 	} else {
 		cutils.Try(func() {
 			if m.ChanResult == nil {
-				fi.Value.Call(values)
+				call()
 			} else {
-				rets := fi.Value.Call(values)
-				rspCode, rspData := retValue(app.Serializer(), rets)
-				m.ChanResult <- &cproto.Response{
+				rspCode, rspData := call()
+				retChanResult(m.ChanResult, &cproto.Response{
 					Code: rspCode,
 					Data: rspData,
-				}
+				})
 			}
 		}, func(errString string) {
 			if m.ChanResult != nil {
-				m.ChanResult <- nil
+				retChanResult(m.ChanResult, nil)
 			}
 
 			clog.Errorf("[remote] invoke error.[source = %s, target = %s -> %s, funcType = %v, err = %+v]",
@@ -148,10 +134,49 @@ func InvokeRemoteFunc(app cfacade.IApplication, fi *creflect.FuncInfo, m *cfacad
 				fi.InArgs,
 				errString,
 			)
+
+			// 交由actor的监督策略处理
+			panic(errString)
 		})
 	}
 }
 
+// callLocal 调用local函数,泛型注册的函数不使用反射
+func callLocal(app cfacade.IApplication, fi *creflect.FuncInfo, m *cfacade.Message) (interface{}, error, bool) {
+	if invoker, ok := fi.Invoker.(localInvoker); ok {
+		return invoker.invokeLocal(app.Serializer(), m)
+	}
+
+	EncodeLocalArgs(app, fi, m)
+
+	values := make([]reflect.Value, 2)
+	values[0] = reflect.ValueOf(m.Session) // session
+	values[1] = reflect.ValueOf(m.Args)    // args
+	return PCall(fi, values)
+}
+
+// remoteCaller 返回remote函数的调用函数,泛型注册的函数不使用反射
+func remoteCaller(app cfacade.IApplication, fi *creflect.FuncInfo, m *cfacade.Message) func() (int32, []byte) {
+	if invoker, ok := fi.Invoker.(remoteInvoker); ok {
+		return func() (int32, []byte) {
+			resp, code := invoker.invokeRemote(app.Serializer(), m)
+			return retTyped(app.Serializer(), resp, code)
+		}
+	}
+
+	EncodeRemoteArgs(app, fi, m)
+
+	values := make([]reflect.Value, fi.InArgsLen)
+	if fi.InArgsLen > 0 {
+		values[0] = reflect.ValueOf(m.Args) // args
+	}
+
+	return func() (int32, []byte) {
+		rets := fi.Value.Call(values)
+		return retValue(app.Serializer(), rets)
+	}
+}
+
 func EncodeRemoteArgs(app cfacade.IApplication, fi *creflect.FuncInfo, m *cfacade.Message) error {
 	if m.IsCluster {
 		if fi.InArgsLen == 0 {
@@ -238,3 +263,12 @@ func retResponse(reply cfacade.IRespond, rsp *cproto.Response) {
 		}
 	}
 }
+
+// retChanResult 返回同步调用的结果,调用方已超时退出时丢弃该结果
+func retChanResult(chanResult chan interface{}, rsp *cproto.Response) {
+	select {
+	case chanResult <- rsp:
+	default:
+		clog.Debugf("[retChanResult] Caller has gone away, discard response. [rsp = %+v]", rsp)
+	}
+}
@@ -0,0 +1,142 @@
+package cherryActor
+
+import (
+	"fmt"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cherryError "github.com/cherry-game/cherry/error"
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+	cproto "github.com/cherry-game/cherry/net/proto"
+)
+
+type (
+	// localInvoker 泛型注册的local函数,参数解码及调用不使用反射
+	localInvoker interface {
+		invokeLocal(serializer cfacade.ISerializer, m *cfacade.Message) (resp interface{}, err error, ok bool)
+	}
+
+	// remoteInvoker 泛型注册的remote函数,参数解码及调用不使用反射
+	remoteInvoker interface {
+		invokeRemote(serializer cfacade.ISerializer, m *cfacade.Message) (resp interface{}, code int32)
+	}
+
+	localFunc[Req, Resp any]       func(session *cproto.Session, req *Req) (*Resp, error)
+	localNotifyFunc[Req any]       func(session *cproto.Session, req *Req)
+	remoteFunc[Req any]            func(req *Req) int32
+	remoteReplyFunc[Req, Resp any] func(req *Req) (*Resp, int32)
+)
+
+// RegisterLocal 注册客户端请求(request)的处理函数,函数签名在编译期检查
+func RegisterLocal[Req, Resp any](mb IMailBox, funcName string, fn func(session *cproto.Session, req *Req) (*Resp, error)) {
+	registerTyped(mb, funcName, fn, localFunc[Req, Resp](fn))
+}
+
+// RegisterLocalNotify 注册客户端通知(notify)的处理函数,无需返回结果
+func RegisterLocalNotify[Req any](mb IMailBox, funcName string, fn func(session *cproto.Session, req *Req)) {
+	registerTyped(mb, funcName, fn, localNotifyFunc[Req](fn))
+}
+
+// RegisterRemote 注册actor之间调用的函数,返回值为错误码
+func RegisterRemote[Req any](mb IMailBox, funcName string, fn func(req *Req) int32) {
+	registerTyped(mb, funcName, fn, remoteFunc[Req](fn))
+}
+
+// RegisterRemoteReply 注册actor之间调用的函数,返回结果及错误码(用于CallWait)
+func RegisterRemoteReply[Req, Resp any](mb IMailBox, funcName string, fn func(req *Req) (*Resp, int32)) {
+	registerTyped(mb, funcName, fn, remoteReplyFunc[Req, Resp](fn))
+}
+
+func registerTyped(mb IMailBox, funcName string, fn, invoker interface{}) {
+	box, ok := mb.(*mailbox)
+	if !ok {
+		clog.Errorf("[RegisterTyped] Mailbox type error. [funcName = %s]", funcName)
+		return
+	}
+
+	box.register(funcName, fn, invoker)
+}
+
+func (f localFunc[Req, Resp]) invokeLocal(serializer cfacade.ISerializer, m *cfacade.Message) (interface{}, error, bool) {
+	req, err := decodeArg[Req](serializer, m)
+	if err != nil {
+		clog.Warnf("[invokeLocal] Decode arg error. [target = %s, funcName = %s, err = %v]", m.Target, m.FuncName, err)
+		return nil, nil, false
+	}
+
+	resp, err := f(m.Session, req)
+	if err == nil && resp == nil {
+		err = cherryError.ErrReplyShouldBeNotNull
+	}
+
+	return resp, err, true
+}
+
+func (f localNotifyFunc[Req]) invokeLocal(serializer cfacade.ISerializer, m *cfacade.Message) (interface{}, error, bool) {
+	req, err := decodeArg[Req](serializer, m)
+	if err != nil {
+		clog.Warnf("[invokeLocal] Decode arg error. [target = %s, funcName = %s, err = %v]", m.Target, m.FuncName, err)
+		return nil, nil, false
+	}
+
+	f(m.Session, req)
+	return nil, nil, false
+}
+
+func (f remoteFunc[Req]) invokeRemote(serializer cfacade.ISerializer, m *cfacade.Message) (interface{}, int32) {
+	req, err := decodeArg[Req](serializer, m)
+	if err != nil {
+		clog.Warnf("[invokeRemote] Decode arg error. [target = %s, funcName = %s, err = %v]", m.Target, m.FuncName, err)
+		return nil, ccode.ActorUnmarshalError
+	}
+
+	return nil, f(req)
+}
+
+func (f remoteReplyFunc[Req, Resp]) invokeRemote(serializer cfacade.ISerializer, m *cfacade.Message) (interface{}, int32) {
+	req, err := decodeArg[Req](serializer, m)
+	if err != nil {
+		clog.Warnf("[invokeRemote] Decode arg error. [target = %s, funcName = %s, err = %v]", m.Target, m.FuncName, err)
+		return nil, ccode.ActorUnmarshalError
+	}
+
+	resp, code := f(req)
+	if resp == nil {
+		return nil, code
+	}
+
+	return resp, code
+}
+
+// decodeArg 参数为[]byte时反序列化,为*Req时直接使用
+func decodeArg[Req any](serializer cfacade.ISerializer, m *cfacade.Message) (*Req, error) {
+	switch arg := m.Args.(type) {
+	case nil:
+		return nil, nil
+	case *Req:
+		return arg, nil
+	case []byte:
+		req := new(Req)
+		if err := serializer.Unmarshal(arg, req); err != nil {
+			return nil, err
+		}
+		return req, nil
+	}
+
+	return nil, fmt.Errorf("arg type error. [type = %T, want = %T]", m.Args, (*Req)(nil))
+}
+
+// retTyped 序列化泛型函数的返回结果
+func retTyped(serializer cfacade.ISerializer, resp interface{}, code int32) (int32, []byte) {
+	if resp == nil {
+		return code, nil
+	}
+
+	data, err := serializer.Marshal(resp)
+	if err != nil {
+		clog.Warn(err)
+		return ccode.RPCRemoteExecuteError, nil
+	}
+
+	return code, data
+}
@@ -0,0 +1,76 @@
+package cherryActor
+
+import (
+	"testing"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cfacade "github.com/cherry-game/cherry/facade"
+	cproto "github.com/cherry-game/cherry/net/proto"
+)
+
+type (
+	testSumReq struct {
+		A, B int32
+	}
+
+	testSumResp struct {
+		Sum int32
+	}
+
+	testTypedActor struct {
+		Base
+	}
+)
+
+func (p *testTypedActor) OnInit() {
+	RegisterRemoteReply(p.Remote(), "sum", p.sum)
+	RegisterLocal(p.Local(), "sum", func(_ *cproto.Session, req *testSumReq) (*testSumResp, error) {
+		resp, _ := p.sum(req)
+		return resp, nil
+	})
+}
+
+func (p *testTypedActor) sum(req *testSumReq) (*testSumResp, int32) {
+	return &testSumResp{Sum: req.A + req.B}, ccode.OK
+}
+
+func TestTypedRemote(t *testing.T) {
+	system := NewSystem()
+	system.SetApp(&testApp{})
+	system.CreateActor("typed", &testTypedActor{})
+
+	resp := &testSumResp{}
+	if code := system.CallWait(".tester", ".typed", "sum", &testSumReq{A: 1, B: 2}, resp); code != ccode.OK {
+		t.Fatalf("sum code = %d", code)
+	}
+
+	if resp.Sum != 3 {
+		t.Fatalf("sum = %d, want 3", resp.Sum)
+	}
+}
+
+func TestTypedLocal(t *testing.T) {
+	system := NewSystem()
+	app := &testApp{}
+	system.SetApp(app)
+	thisActor, _ := system.CreateActor("typed", &testTypedActor{})
+
+	// 等待OnInit执行完成
+	system.CallWait(".tester", ".typed", "sum", &testSumReq{}, nil)
+
+	funcInfo, found := thisActor.(*Actor).Local().GetFuncInfo("sum")
+	if !found {
+		t.Fatal("sum not registered")
+	}
+
+	data, _ := app.Serializer().Marshal(&testSumReq{A: 2, B: 3})
+	m := &cfacade.Message{
+		Session: &cproto.Session{},
+		Args:    data,
+	}
+
+	resp, err, ok := callLocal(app, funcInfo, m)
+	if !ok || err != nil || resp.(*testSumResp).Sum != 5 {
+		t.Fatalf("resp = %+v, err = %v, ok = %v", resp, err, ok)
+	}
+}
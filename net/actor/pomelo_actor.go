@@ -47,6 +47,7 @@ func (p *pomeloActor) OnInit() {
 	p.Remote().Register(PushFuncName, p.push)
 	p.Remote().Register(KickFuncName, p.kick)
 	p.Remote().Register(BroadcastName, p.broadcast)
+	p.Remote().Register(MigrateRouteFuncName, p.migrateRoute)
 
 	if p.onInitFunc != nil {
 		p.onInitFunc()
@@ -57,6 +58,11 @@ func (p *pomeloActor) SetOnInitFunc(fn func()) {
 	p.onInitFunc = fn
 }
 
+// CanPassivate 网关actor不参与空闲回收
+func (*pomeloActor) CanPassivate() bool {
+	return false
+}
+
 func (p *pomeloActor) Load(app cfacade.IApplication) {
 	if len(p.connectors) < 1 {
 		panic("connectors is nil. Please call the AddConnector(...) method add IConnector.")
@@ -194,3 +200,15 @@ func (p *pomeloActor) broadcast(rsp *cproto.PomeloBroadcastPush) {
 		}
 	}
 }
+
+func (p *pomeloActor) migrateRoute(rsp *cproto.MigrateRoute) {
+	agent, found := pomelo.GetAgent(rsp.Sid)
+	if !found {
+		if clog.PrintLevel(zapcore.DebugLevel) {
+			clog.Debugf("[migrateRoute] Not found agent. [rsp = %+v]", rsp)
+		}
+		return
+	}
+
+	agent.Session().SetRoute(rsp.NodeType, rsp.NodeId)
+}
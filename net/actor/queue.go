@@ -1,15 +1,40 @@
 package cherryActor
 
 import (
+	"sync"
 	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
+const (
+	DropNewestPolicy OverflowPolicy = 0 // 丢弃新消息(记录死信)
+	DropOldestPolicy OverflowPolicy = 1 // 丢弃最旧的消息(记录死信)
+	RejectPolicy     OverflowPolicy = 2 // 拒绝新消息,并返回错误码
+	BlockPolicy      OverflowPolicy = 3 // 阻塞生产者,超时后返回错误码
+)
+
 type (
+	OverflowPolicy int
+
+	// MailboxOptions 邮箱容量设置
+	MailboxOptions struct {
+		Capacity     int32          // 容量(0为不限制)
+		Policy       OverflowPolicy // 超出容量时的处理策略
+		BlockTimeout time.Duration  // BlockPolicy的阻塞超时时间
+	}
+
 	queue struct {
 		head, tail *queueNode
 		C          chan int32
 		count      int32
+		MailboxOptions
+		spaceMu  sync.Mutex        // 保护space
+		space    chan struct{}     // BlockPolicy时阻塞的生产者等待该通道,有空余位置时关闭以通知所有生产者
+		waiting  int32             // 阻塞中的生产者数量
+		dropping int32             // 待丢弃的旧消息数量(DropOldestPolicy)
+		dropped  int64             // 已丢弃/拒绝的消息数量
+		onDrop   func(interface{}) // 消息被丢弃时回调(DropNewestPolicy、DropOldestPolicy)
 	}
 
 	queueNode struct {
@@ -20,18 +45,51 @@ type (
 
 func newQueue() queue {
 	stub := &queueNode{}
-	q := queue{
+	return queue{
 		head:  stub,
 		tail:  stub,
 		C:     make(chan int32, 1),
 		count: 0,
 	}
-	return q
 }
 
-func (p *queue) Push(v interface{}) {
+func (p *queue) setOptions(opts MailboxOptions) {
+	if opts.Policy == BlockPolicy && opts.BlockTimeout <= 0 {
+		opts.BlockTimeout = time.Second
+	}
+	p.MailboxOptions = opts
+}
+
+// Push 添加消息,返回false表示消息被拒绝
+func (p *queue) Push(v interface{}) bool {
 	if v == nil {
-		return
+		return true
+	}
+
+	reserved := false
+	if p.Capacity > 0 {
+		reserved = p.reserve()
+		if !reserved {
+			switch p.Policy {
+			case DropNewestPolicy:
+				atomic.AddInt64(&p.dropped, 1)
+				p.drop(v)
+				return true
+			case RejectPolicy:
+				atomic.AddInt64(&p.dropped, 1)
+				return false
+			case BlockPolicy:
+				if !p.waitSpace() {
+					atomic.AddInt64(&p.dropped, 1)
+					return false
+				}
+				reserved = true
+			case DropOldestPolicy:
+				// 由消费者在Pop时丢弃最旧的消息
+				atomic.AddInt32(&p.dropping, 1)
+				atomic.AddInt64(&p.dropped, 1)
+			}
+		}
 	}
 
 	n := new(queueNode)
@@ -42,21 +100,42 @@ func (p *queue) Push(v interface{}) {
 	// release node to consumer
 	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&prev.next)), unsafe.Pointer(n))
 
-	p._setCount(1)
+	if reserved {
+		// 已在reserve()中计数,只通知消费者
+		p._setCount(0)
+	} else {
+		p._setCount(1)
+	}
+
+	return true
 }
 
 func (p *queue) Pop() interface{} {
-	tail := p.tail
-	next := (*queueNode)(atomic.LoadPointer((*unsafe.Pointer)(unsafe.Pointer(&tail.next)))) // acquire
-	if next != nil {
+	for {
+		tail := p.tail
+		next := (*queueNode)(atomic.LoadPointer((*unsafe.Pointer)(unsafe.Pointer(&tail.next)))) // acquire
+		if next == nil {
+			return nil
+		}
+
 		p.tail = next
 		v := next.val
 		next.val = nil
 		p._setCount(-1)
+
+		if p.Capacity > 0 {
+			if p.Policy == BlockPolicy {
+				p.notifySpace()
+			}
+
+			if p.Policy == DropOldestPolicy && p.takeDropping() {
+				p.drop(v)
+				continue
+			}
+		}
+
 		return v
 	}
-
-	return nil
 }
 
 func (p *queue) Empty() bool {
@@ -65,8 +144,14 @@ func (p *queue) Empty() bool {
 	return next == nil
 }
 
+// Count 当前队列深度
 func (p *queue) Count() int32 {
-	return atomic.LoadInt32(&p.count)
+	return atomic.LoadInt32(&p.count) - atomic.LoadInt32(&p.dropping)
+}
+
+// Dropped 已丢弃/拒绝的消息数量
+func (p *queue) Dropped() int64 {
+	return atomic.LoadInt64(&p.dropped)
 }
 
 func (p *queue) _setCount(delta int32) {
@@ -79,9 +164,87 @@ func (p *queue) _setCount(delta int32) {
 	}
 }
 
+// reserve 队列未满时占用一个位置(计数与判断为原子操作,避免并发生产者超出容量)
+func (p *queue) reserve() bool {
+	for {
+		count := atomic.LoadInt32(&p.count)
+		if count-atomic.LoadInt32(&p.dropping) >= p.Capacity {
+			return false
+		}
+
+		if atomic.CompareAndSwapInt32(&p.count, count, count+1) {
+			return true
+		}
+	}
+}
+
+func (p *queue) drop(v interface{}) {
+	if p.onDrop != nil {
+		p.onDrop(v)
+	}
+}
+
+func (p *queue) takeDropping() bool {
+	for {
+		dropping := atomic.LoadInt32(&p.dropping)
+		if dropping < 1 {
+			return false
+		}
+
+		if atomic.CompareAndSwapInt32(&p.dropping, dropping, dropping-1) {
+			return true
+		}
+	}
+}
+
+// waitSpace 等待队列有空余位置,返回true时已占用该位置
+func (p *queue) waitSpace() bool {
+	timer := time.NewTimer(p.BlockTimeout)
+	defer timer.Stop()
+
+	for {
+		// 先登记为等待中再检查,Pop释放位置后看不到等待者时,此处的reserve()必定成功
+		p.spaceMu.Lock()
+		atomic.AddInt32(&p.waiting, 1)
+		if p.reserve() {
+			atomic.AddInt32(&p.waiting, -1)
+			p.spaceMu.Unlock()
+			return true
+		}
+
+		if p.space == nil {
+			p.space = make(chan struct{})
+		}
+		space := p.space
+		p.spaceMu.Unlock()
+
+		select {
+		case <-space:
+			atomic.AddInt32(&p.waiting, -1)
+		case <-timer.C:
+			atomic.AddInt32(&p.waiting, -1)
+			return false
+		}
+	}
+}
+
+// notifySpace 唤醒所有阻塞的生产者,由其竞争空余位置
+func (p *queue) notifySpace() {
+	if atomic.LoadInt32(&p.waiting) < 1 {
+		return
+	}
+
+	p.spaceMu.Lock()
+	if p.space != nil {
+		close(p.space)
+		p.space = nil
+	}
+	p.spaceMu.Unlock()
+}
+
+// Destroy 丢弃队列中剩余的消息
+// 不关闭C及重置head,actor退出时仍可能有并发的Push(向已关闭的通道发送会panic)
 func (p *queue) Destroy() {
-	close(p.C)
-	p.head = nil
-	p.tail = nil
-	p.count = 0
+	for p.Pop() != nil {
+	}
 }
@@ -0,0 +1,186 @@
+package cherryActor
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ccode "github.com/cherry-game/cherry/code"
+)
+
+func TestQueueDropNewest(t *testing.T) {
+	q := newQueue()
+	q.setOptions(MailboxOptions{Capacity: 2, Policy: DropNewestPolicy})
+
+	for i := 1; i <= 3; i++ {
+		if !q.Push(i) {
+			t.Fatalf("push %d should not be rejected", i)
+		}
+	}
+
+	if q.Count() != 2 || q.Dropped() != 1 {
+		t.Fatalf("count = %d, dropped = %d", q.Count(), q.Dropped())
+	}
+
+	if v := q.Pop(); v != 1 {
+		t.Fatalf("pop = %v, want 1", v)
+	}
+}
+
+func TestQueueDropOldest(t *testing.T) {
+	q := newQueue()
+	q.setOptions(MailboxOptions{Capacity: 2, Policy: DropOldestPolicy})
+
+	for i := 1; i <= 3; i++ {
+		q.Push(i)
+	}
+
+	if q.Count() != 2 || q.Dropped() != 1 {
+		t.Fatalf("count = %d, dropped = %d", q.Count(), q.Dropped())
+	}
+
+	if v := q.Pop(); v != 2 {
+		t.Fatalf("pop = %v, want 2", v)
+	}
+
+	if v := q.Pop(); v != 3 {
+		t.Fatalf("pop = %v, want 3", v)
+	}
+
+	if v := q.Pop(); v != nil {
+		t.Fatalf("pop = %v, want nil", v)
+	}
+}
+
+func TestQueueReject(t *testing.T) {
+	q := newQueue()
+	q.setOptions(MailboxOptions{Capacity: 1, Policy: RejectPolicy})
+
+	if !q.Push(1) || q.Push(2) {
+		t.Fatal("second push should be rejected")
+	}
+}
+
+func TestQueueBlock(t *testing.T) {
+	q := newQueue()
+	q.setOptions(MailboxOptions{Capacity: 1, Policy: BlockPolicy, BlockTimeout: 20 * time.Millisecond})
+
+	q.Push(1)
+	if q.Push(2) {
+		t.Fatal("push should time out when the queue is full")
+	}
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		q.Pop()
+	}()
+
+	if !q.Push(3) {
+		t.Fatal("push should succeed once the consumer frees space")
+	}
+}
+
+func TestQueueBlockProducers(t *testing.T) {
+	q := newQueue()
+	q.setOptions(MailboxOptions{Capacity: 1, Policy: BlockPolicy, BlockTimeout: 200 * time.Millisecond})
+
+	// 多个生产者同时阻塞,消费者每释放一个位置都需唤醒等待中的生产者,否则会等待超时
+	var (
+		wg       sync.WaitGroup
+		rejected int32
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 200; n++ {
+				if !q.Push(n) {
+					atomic.AddInt32(&rejected, 1)
+				}
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	for {
+		select {
+		case <-done:
+			for q.Pop() != nil {
+			}
+
+			if n := atomic.LoadInt32(&rejected); n != 0 {
+				t.Fatalf("rejected = %d, want 0", n)
+			}
+			return
+		case <-q.C:
+			for q.Pop() != nil {
+			}
+		}
+	}
+}
+
+func TestQueueConcurrentCapacity(t *testing.T) {
+	q := newQueue()
+	q.setOptions(MailboxOptions{Capacity: 10, Policy: RejectPolicy})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			q.Push(i)
+		}(i)
+	}
+	wg.Wait()
+
+	if q.Count() != 10 || q.Dropped() != 90 {
+		t.Fatalf("count = %d, dropped = %d", q.Count(), q.Dropped())
+	}
+}
+
+type testBoundedActor struct {
+	testBlockActor
+}
+
+func (*testBoundedActor) MailboxOptions() MailboxOptions {
+	return MailboxOptions{Capacity: 1, Policy: DropOldestPolicy}
+}
+
+func TestMailboxDropOldest(t *testing.T) {
+	system := NewSystem()
+	system.SetApp(&testApp{})
+	system.SetCallTimeout(time.Second)
+	system.CreateActor("target", &testBoundedActor{})
+
+	// 第一条消息执行期间,后续消息在邮箱中等待
+	system.Call(".source", ".target", "block", nil)
+	time.Sleep(20 * time.Millisecond)
+
+	result := make(chan int32, 1)
+	go func() {
+		result <- system.CallWait(".source", ".target", "block", nil, nil)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	system.Call(".source", ".target", "block", nil)
+
+	select {
+	case code := <-result:
+		if code != ccode.ActorMailboxFull {
+			t.Fatalf("code = %d, want %d", code, ccode.ActorMailboxFull)
+		}
+	case <-time.After(800 * time.Millisecond):
+		t.Fatal("dropped call was not answered")
+	}
+
+	letters := system.DeadLetters(nil, 0)
+	if len(letters) != 1 || letters[0].Reason != MailboxFullReason {
+		t.Fatalf("dead letters = %+v", letters)
+	}
+}
@@ -40,6 +40,12 @@ func NewSimpleActor(agentActorID string) *simpleActor {
 // OnInit Actor初始化前触发该函数
 func (p *simpleActor) OnInit() {
 	p.Remote().Register(ResponseFuncName, p.response)
+	p.Remote().Register(MigrateRouteFuncName, p.migrateRoute)
+}
+
+// CanPassivate 网关actor不参与空闲回收
+func (*simpleActor) CanPassivate() bool {
+	return false
 }
 
 func (p *simpleActor) Load(app cfacade.IApplication) {
@@ -121,3 +127,15 @@ func (p *simpleActor) response(rsp *cproto.PomeloResponse) {
 
 	agent.Response(rsp.Mid, rsp.Data)
 }
+
+func (p *simpleActor) migrateRoute(rsp *cproto.MigrateRoute) {
+	agent, found := simple.GetAgent(rsp.Sid)
+	if !found {
+		if clog.PrintLevel(zapcore.DebugLevel) {
+			clog.Debugf("[migrateRoute] Not found agent. [rsp = %+v]", rsp)
+		}
+		return
+	}
+
+	agent.Session().SetRoute(rsp.NodeType, rsp.NodeId)
+}
@@ -1,28 +1,62 @@
 package cherryActor
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	ccode "github.com/cherry-game/cherry/code"
+	ctimeWheel "github.com/cherry-game/cherry/extend/time_wheel"
 	cutils "github.com/cherry-game/cherry/extend/utils"
 	cfacade "github.com/cherry-game/cherry/facade"
 	clog "github.com/cherry-game/cherry/logger"
 	cproto "github.com/cherry-game/cherry/net/proto"
+	ctrace "github.com/cherry-game/cherry/net/trace"
 )
 
 type (
 	// System Actor系统
 	System struct {
-		app              cfacade.IApplication
-		actorMap         *sync.Map          // key:actorID, value:*actor
-		localInvokeFunc  cfacade.InvokeFunc // default local func
-		remoteInvokeFunc cfacade.InvokeFunc // default remote func
-		wg               *sync.WaitGroup    // wait group
-		callTimeout      time.Duration      // call调用超时
-		arrivalTimeOut   int64              // message到达超时(毫秒)
-		executionTimeout int64              // 消息执行超时(毫秒)
+		app                cfacade.IApplication
+		actorMap           *sync.Map                    // key:actorID, value:*actor
+		localInvokeFunc    cfacade.InvokeFunc           // default local func
+		remoteInvokeFunc   cfacade.InvokeFunc           // default remote func
+		wg                 *sync.WaitGroup              // wait group
+		supervisorStrategy *SupervisorStrategy          // 顶层actor的监督策略
+		mailboxOptions     MailboxOptions               // 默认的邮箱容量设置
+		lanes              []Lane                       // 默认的消息通道优先级
+		callTimeout        time.Duration                // call调用超时
+		arrivalTimeOut     int64                        // message到达超时(毫秒)
+		executionTimeout   int64                        // 消息执行超时(毫秒)
+		idleTimeout        time.Duration                // actor默认空闲超时时间(0为不回收)
+		idleTypeTimeout    sync.Map                     // key:handler type, value:空闲超时时间
+		idleScanInterval   time.Duration                // 空闲actor扫描间隔
+		idleScanOnce       sync.Once                    // 启动空闲actor扫描
+		idleScanTimer      *ctimeWheel.Timer            // 空闲actor扫描定时器
+		kinds              sync.Map                     // key:kind, value:KindFactory
+		activateLock       sync.Mutex                   // 虚拟actor创建锁
+		placement          *placement                   // actor的集群放置服务
+		singletons         *singletons                  // 集群单例actor
+		forwards           sync.Map                     // 已迁移的actor. key:actor path(不含节点id), value:*forwardEntry
+		forwardExpire      time.Duration                // 转发记录的保留时间
+		forwardOnce        sync.Once                    // 启动转发记录的清理定时器及成员监听
+		forwardTimer       *ctimeWheel.Timer            // 转发记录的清理定时器
+		migratedListeners  []MigratedListener           // actor迁移完成的监听函数
+		eventTypes         sync.Map                     // 可跨节点接收的事件. key:event name, value:func() cfacade.IEventData
+		eventDedupe        *eventDedupe                 // 跨节点事件去重
+		eventSeq           int64                        // 发布到其他节点的事件序号(以启动时间为初始值,节点重启后不重复)
+		interceptors       []Interceptor                // 函数调用拦截器
+		deadLetters        *deadLetters                 // 死信
+		stateStore         IStateStore                  // actor状态的默认存储
+		metrics            atomic.Pointer[actorMetrics] // 指标(未开启时为nil)
+		timerScheduler     ITimerScheduler              // actor定时器的调度器
+		outbound           OutboundFunc                 // Call/CallWait的发送拦截函数
+		manualRun          bool                         // 手动驱动模式(由RunPending处理消息)
+		manualActors       []*Actor                     // 手动驱动模式下运行中的actor
+		manualMu           sync.Mutex                   // manualActors锁
 	}
 )
 
@@ -35,7 +69,16 @@ func NewSystem() *System {
 		callTimeout:      3 * time.Second,
 		arrivalTimeOut:   100,
 		executionTimeout: 100,
+		idleScanInterval: 10 * time.Second,
+		forwardExpire:    defaultForwardExpire,
+		timerScheduler:   globalScheduler,
+		lanes:            DefaultLanePriority,
 	}
+	system.placement = newPlacement(system)
+	system.singletons = newSingletons(system)
+	system.eventDedupe = newEventDedupe(eventDedupeSize)
+	system.eventSeq = time.Now().UnixNano()
+	system.deadLetters = newDeadLetters(defaultDeadLetterSize)
 
 	return system
 }
@@ -53,6 +96,9 @@ func (p *System) NodeId() string {
 }
 
 func (p *System) Stop() {
+	p.stopIdleScan()
+	p.stopForwardSweep()
+
 	p.actorMap.Range(func(key, value any) bool {
 		actor, ok := value.(*Actor)
 		if ok {
@@ -66,6 +112,9 @@ func (p *System) Stop() {
 	})
 
 	clog.Info("actor system stopping!")
+	if p.manualRun {
+		p.RunPending()
+	}
 	p.wg.Wait()
 	clog.Info("actor system stopped!")
 }
@@ -114,14 +163,19 @@ func (p *System) CreateActor(id string, handler cfacade.IActorHandler) (cfacade.
 		return nil, err
 	}
 
-	p.actorMap.Store(id, &thisActor) // add to map
-	go thisActor.run()               // new actor is running!
+	p.actorMap.Store(id, thisActor) // add to map
+	p.spawn(thisActor)              // new actor is running!
 
-	return &thisActor, nil
+	return thisActor, nil
 }
 
 // Call 发送远程消息(不回复)
 func (p *System) Call(source, target, funcName string, arg interface{}) int32 {
+	return p.call(context.Background(), source, target, funcName, arg)
+}
+
+// call ctx中的traceparent及调用策略会传递给集群
+func (p *System) call(ctx context.Context, source, target, funcName string, arg interface{}) int32 {
 	if target == "" {
 		clog.Warnf("[Call] Target path is nil. [source = %s, target = %s, funcName = %s]",
 			source,
@@ -151,6 +205,14 @@ func (p *System) Call(source, target, funcName string, arg interface{}) int32 {
 		return ccode.ActorConvertPathError
 	}
 
+	if p.outbound != nil {
+		if code, handled := p.outbound(ctx, source, target, funcName, arg, nil, false); handled {
+			return code
+		}
+	}
+
+	target, targetPath = p.resolvePath(target, targetPath)
+
 	if targetPath.NodeID != "" && targetPath.NodeID != p.NodeId() {
 		clusterPacket := cproto.GetClusterPacket()
 		clusterPacket.SourcePath = source
@@ -169,8 +231,13 @@ func (p *System) Call(source, target, funcName string, arg interface{}) int32 {
 			clusterPacket.ArgBytes = argsBytes
 		}
 
-		err = p.app.Cluster().PublishRemote(targetPath.NodeID, clusterPacket)
+		traceParent := ctrace.FromContext(ctx)
+		span := startCallSpan("publish", traceParent, source, target, funcName)
+		clusterPacket.TraceParent = ctrace.ChildTraceParent(span, traceParent)
+
+		err = p.publishRemote(ctx, targetPath.NodeID, clusterPacket)
 		if err != nil {
+			endCallSpan(span, ccode.ActorPublishRemoteError)
 			clog.Warnf("[Call] Publish remote fail. [source = %s, target = %s, funcName = %s, err = %v]",
 				source,
 				target,
@@ -179,16 +246,18 @@ func (p *System) Call(source, target, funcName string, arg interface{}) int32 {
 			)
 			return ccode.ActorPublishRemoteError
 		}
+		endCallSpan(span, ccode.OK)
 	} else {
 		remoteMsg := cfacade.GetMessage()
 		remoteMsg.Source = source
 		remoteMsg.Target = target
 		remoteMsg.FuncName = funcName
 		remoteMsg.Args = arg
+		remoteMsg.TraceParent = ctrace.FromContext(ctx)
 
-		if !p.PostRemote(&remoteMsg) {
+		if code := p.PostRemote(&remoteMsg); ccode.IsFail(code) {
 			clog.Warnf("[Call] Post remote fail. [source = %s, target = %s, funcName = %s]", source, target, funcName)
-			return ccode.ActorCallFail
+			return code
 		}
 	}
 
@@ -197,6 +266,26 @@ func (p *System) Call(source, target, funcName string, arg interface{}) int32 {
 
 // CallWait 发送远程消息(等待回复)
 func (p *System) CallWait(source, target, funcName string, arg interface{}, reply interface{}) int32 {
+	return p.CallWaitContext(context.Background(), source, target, funcName, arg, reply)
+}
+
+// CallWaitContext 发送远程消息(等待回复),可通过ctx取消等待
+// ctx设置了deadline时以deadline为准,否则使用callTimeout
+// ctx中的traceparent(cherryTrace.WithTraceParent)会传递给目标actor
+// 超时返回ccode.ActorCallTimeout,取消返回ccode.ActorCallCanceled
+func (p *System) CallWaitContext(ctx context.Context, source, target, funcName string, arg interface{}, reply interface{}) int32 {
+	if p.metrics.Load() == nil {
+		return p.callWait(ctx, source, target, funcName, arg, reply)
+	}
+
+	begin := time.Now()
+	code := p.callWait(ctx, source, target, funcName, arg, reply)
+	p.metrics.Load().observeCallWait(funcName, begin, code)
+
+	return code
+}
+
+func (p *System) callWait(ctx context.Context, source, target, funcName string, arg interface{}, reply interface{}) int32 {
 	sourcePath, err := cfacade.ToActorPath(source)
 	if err != nil {
 		clog.Warnf("[CallWait] Source path error. [source = %s, target = %s, funcName = %s, err = %v]",
@@ -219,6 +308,14 @@ func (p *System) CallWait(source, target, funcName string, arg interface{}, repl
 		return ccode.ActorConvertPathError
 	}
 
+	if p.outbound != nil {
+		if code, handled := p.outbound(ctx, source, target, funcName, arg, reply, true); handled {
+			return code
+		}
+	}
+
+	target, targetPath = p.resolvePath(target, targetPath)
+
 	if source == target {
 		clog.Warnf("[CallWait] Source path is equal target. [source = %s, target = %s, funcName = %s]",
 			source,
@@ -250,7 +347,18 @@ func (p *System) CallWait(source, target, funcName string, arg interface{}, repl
 			clusterPacket.ArgBytes = argsBytes
 		}
 
-		rsp := p.app.Cluster().RequestRemote(targetPath.NodeID, clusterPacket, p.callTimeout)
+		timeout, code := p.remainTimeout(ctx)
+		if ccode.IsFail(code) {
+			clusterPacket.Recycle()
+			return code
+		}
+
+		traceParent := ctrace.FromContext(ctx)
+		span := startCallSpan("request", traceParent, source, target, funcName)
+		clusterPacket.TraceParent = ctrace.ChildTraceParent(span, traceParent)
+
+		rsp := p.requestRemote(ctx, targetPath.NodeID, clusterPacket, timeout)
+		endCallSpan(span, rsp.Code)
 		if ccode.IsFail(rsp.Code) {
 			return rsp.Code
 		}
@@ -268,9 +376,9 @@ func (p *System) CallWait(source, target, funcName string, arg interface{}, repl
 		message.Target = target
 		message.FuncName = funcName
 		message.Args = arg
-		message.ChanResult = make(chan interface{})
-
-		var result interface{}
+		message.TraceParent = ctrace.FromContext(ctx)
+		// 带缓冲,调用方超时退出后,迟到的回复会被直接丢弃
+		message.ChanResult = make(chan interface{}, 1)
 
 		if sourcePath.ActorID == targetPath.ActorID {
 			if sourcePath.ChildID == targetPath.ChildID {
@@ -278,49 +386,66 @@ func (p *System) CallWait(source, target, funcName string, arg interface{}, repl
 			}
 
 			childActor, found := p.GetChildActor(targetPath.ActorID, targetPath.ChildID)
-			if !found {
-				return ccode.ActorChildIDNotFound
-			}
+			if found {
+				if code := childActor.PostRemote(&message); ccode.IsFail(code) {
+					return code
+				}
+			} else {
+				if _, _, isKind := p.getKindFactory(targetPath.ActorID); !isKind {
+					return ccode.ActorChildIDNotFound
+				}
 
-			childActor.PostRemote(&message)
-			result = <-message.ChanResult
+				// 虚拟actor由宿主actor按需创建
+				if code := p.PostRemote(&message); ccode.IsFail(code) {
+					return code
+				}
+			}
 		} else {
-			if !p.PostRemote(&message) {
+			if code := p.PostRemote(&message); ccode.IsFail(code) {
 				clog.Warnf("[CallWait] Post remote fail. [source = %s, target = %s, funcName = %s]", source, target, funcName)
-				return ccode.ActorCallFail
+				return code
 			}
-			result = <-message.ChanResult
 		}
 
-		if result != nil {
-			rsp := result.(*cproto.Response)
-			if rsp == nil {
-				clog.Warnf("[CallWait] Response is nil. [targetPath = %s]",
+		result, code := p.waitResult(ctx, message.ChanResult)
+		if ccode.IsFail(code) {
+			clog.Warnf("[CallWait] Wait result fail. [source = %s, target = %s, funcName = %s, code = %d]",
+				source,
+				target,
+				funcName,
+				code,
+			)
+			return code
+		}
+
+		rsp, ok := result.(*cproto.Response)
+		if !ok || rsp == nil {
+			// 目标函数执行异常
+			clog.Warnf("[CallWait] Response is nil. [targetPath = %s]",
+				target,
+			)
+			return ccode.RPCRemoteExecuteError
+		}
+
+		if ccode.IsFail(rsp.Code) {
+			return rsp.Code
+		}
+
+		if reply != nil {
+			if rsp.Data == nil {
+				clog.Warnf("[CallWait] rsp.Data is nil. [targetPath = %s, error = %s]",
 					target,
+					err,
 				)
-				return ccode.ActorCallFail
 			}
 
-			if ccode.IsFail(rsp.Code) {
-				return rsp.Code
-			}
-
-			if reply != nil {
-				if rsp.Data == nil {
-					clog.Warnf("[CallWait] rsp.Data is nil. [targetPath = %s, error = %s]",
-						target,
-						err,
-					)
-				}
-
-				err = p.app.Serializer().Unmarshal(rsp.Data, reply)
-				if err != nil {
-					clog.Warnf("[CallWait] Unmarshal reply error. [targetPath = %s, error = %s]",
-						target,
-						err,
-					)
-					return ccode.ActorUnmarshalError
-				}
+			err = p.app.Serializer().Unmarshal(rsp.Data, reply)
+			if err != nil {
+				clog.Warnf("[CallWait] Unmarshal reply error. [targetPath = %s, error = %s]",
+					target,
+					err,
+				)
+				return ccode.ActorUnmarshalError
 			}
 		}
 	}
@@ -328,18 +453,81 @@ func (p *System) CallWait(source, target, funcName string, arg interface{}, repl
 	return ccode.OK
 }
 
+// waitResult 等待本地actor的执行结果
+func (p *System) waitResult(ctx context.Context, chanResult chan interface{}) (interface{}, int32) {
+	var timeoutChan <-chan time.Time
+	if _, ok := ctx.Deadline(); !ok && p.callTimeout > 0 {
+		timer := time.NewTimer(p.callTimeout)
+		defer timer.Stop()
+		timeoutChan = timer.C
+	}
+
+	select {
+	case result := <-chanResult:
+		return result, ccode.OK
+	case <-timeoutChan:
+		return nil, ccode.ActorCallTimeout
+	case <-ctx.Done():
+		return nil, ctxErrorCode(ctx)
+	}
+}
+
+// remainTimeout 根据ctx的deadline计算请求的超时时间
+func (p *System) remainTimeout(ctx context.Context) (time.Duration, int32) {
+	if ctx.Err() != nil {
+		return 0, ctxErrorCode(ctx)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return p.callTimeout, ccode.OK
+	}
+
+	timeout := time.Until(deadline)
+	if timeout <= 0 {
+		return 0, ccode.ActorCallTimeout
+	}
+
+	return timeout, ccode.OK
+}
+
+func ctxErrorCode(ctx context.Context) int32 {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ccode.ActorCallTimeout
+	}
+	return ccode.ActorCallCanceled
+}
+
 // PostRemote 提交远程消息
-func (p *System) PostRemote(m *cfacade.Message) bool {
+func (p *System) PostRemote(m *cfacade.Message) int32 {
 	if m == nil {
 		clog.Error("Message is nil.")
-		return false
+		return ccode.ActorCallFail
 	}
 
-	if targetActor, found := p.GetActor(m.TargetPath().ActorID); found {
-		if targetActor.state == WorkerState {
-			targetActor.PostRemote(m)
+	targetActor, found := p.GetActor(m.TargetPath().ActorID)
+	if !found {
+		if nodeID, forwarded := p.getForward(m.TargetPath()); forwarded {
+			p.forward(m, nodeID, false)
+			return ccode.OK
 		}
-		return true
+
+		targetActor, found = p.activate(m.TargetPath())
+	}
+
+	if found {
+		if targetActor.canPost() {
+			return targetActor.PostRemote(m)
+		}
+		clog.Warnf("[PostRemote] actor is not work state. [source = %s, target = %s -> %s], "+
+			"targetActor.state = %v",
+			m.Source,
+			m.Target,
+			m.FuncName,
+			targetActor.State(),
+		)
+		p.deadLetter(m, false, ActorNotWorkingReason)
+		return ccode.ActorCallFail
 	}
 
 	clog.Warnf("[PostRemote] actor not found. [source = %s, target = %s -> %s]",
@@ -347,29 +535,40 @@ func (p *System) PostRemote(m *cfacade.Message) bool {
 		m.Target,
 		m.FuncName,
 	)
-	return false
+	p.deadLetter(m, false, ActorNotFoundReason)
+	return ccode.ActorCallFail
 }
 
 // PostLocal 提交本地消息
-func (p *System) PostLocal(m *cfacade.Message) bool {
+func (p *System) PostLocal(m *cfacade.Message) int32 {
 	if m == nil {
 		clog.Error("Message is nil.")
-		return false
+		return ccode.ActorCallFail
 	}
 
-	if targetActor, found := p.GetActor(m.TargetPath().ActorID); found {
-		if targetActor.state == WorkerState {
-			targetActor.PostLocal(m)
-			return true
+	targetActor, found := p.GetActor(m.TargetPath().ActorID)
+	if !found {
+		if nodeID, forwarded := p.getForward(m.TargetPath()); forwarded {
+			p.forward(m, nodeID, true)
+			return ccode.OK
+		}
+
+		targetActor, found = p.activate(m.TargetPath())
+	}
+
+	if found {
+		if targetActor.canPost() {
+			return targetActor.PostLocal(m)
 		}
 		clog.Warnf("[PostLocal] actor is not work state. [source = %s, target = %s -> %s], "+
 			"targetActor.state = %v",
 			m.Source,
 			m.Target,
 			m.FuncName,
-			targetActor.state,
+			targetActor.State(),
 		)
-		return false
+		p.deadLetter(m, true, ActorNotWorkingReason)
+		return ccode.ActorCallFail
 	}
 
 	clog.Warnf("[PostLocal] actor not found. [source = %s, target = %s -> %s]",
@@ -377,25 +576,47 @@ func (p *System) PostLocal(m *cfacade.Message) bool {
 		m.Target,
 		m.FuncName,
 	)
+	p.deadLetter(m, true, ActorNotFoundReason)
 
-	return false
+	return ccode.ActorCallFail
 }
 
 // PostEvent 提交事件
+// 事件实现了cfacade.IEventScope时,会按发布范围同时发布到其他节点
 func (p *System) PostEvent(data cfacade.IEventData) {
 	if data == nil {
 		clog.Error("[PostEvent] Event is nil.")
 		return
 	}
 
+	accepted := p.postLocalEvent(data)
+
+	if eventScope, ok := data.(cfacade.IEventScope); ok {
+		if scope, _ := eventScope.Scope(); scope != cfacade.LocalEventScope {
+			p.publishEvent(data, eventScope)
+			return
+		}
+	}
+
+	if !accepted {
+		p.deadEvent(data, "")
+	}
+}
+
+// postLocalEvent 提交事件到本节点的actor,返回是否有actor注册了该事件
+func (p *System) postLocalEvent(data cfacade.IEventData) bool {
+	accepted := false
+
 	p.actorMap.Range(func(key, value any) bool {
 		if thisActor, found := value.(*Actor); found {
-			if thisActor.state == WorkerState {
-				thisActor.event.Push(data)
+			if thisActor.State() == WorkerState && thisActor.event.Push(data) {
+				accepted = true
 			}
 		}
 		return true
 	})
+
+	return accepted
 }
 
 func (p *System) SetLocalInvoke(fn cfacade.InvokeFunc) {
@@ -410,6 +631,23 @@ func (p *System) SetRemoteInvoke(fn cfacade.InvokeFunc) {
 	}
 }
 
+// SetSupervisorStrategy 设置顶层actor的监督策略(nil则不处理异常,仅记录日志)
+func (p *System) SetSupervisorStrategy(strategy *SupervisorStrategy) {
+	p.supervisorStrategy = strategy
+}
+
+// SetMailboxOptions 设置actor默认的邮箱容量,对之后创建的actor生效
+func (p *System) SetMailboxOptions(opts MailboxOptions) {
+	p.mailboxOptions = opts
+}
+
+// SetTimerScheduler 设置actor定时器的调度器(需在创建actor前调用),默认使用全局时间轮
+func (p *System) SetTimerScheduler(scheduler ITimerScheduler) {
+	if scheduler != nil {
+		p.timerScheduler = scheduler
+	}
+}
+
 func (p *System) SetCallTimeout(d time.Duration) {
 	p.callTimeout = d
 }
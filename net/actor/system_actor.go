@@ -0,0 +1,43 @@
+package cherryActor
+
+import (
+	cproto "github.com/cherry-game/cherry/net/proto"
+)
+
+const (
+	SystemActorID = "_system_" // 内置的系统actor,处理节点间的系统消息
+	eventFuncName = "_event_"
+)
+
+type (
+	// systemActor 内置的系统actor
+	systemActor struct {
+		Base
+	}
+)
+
+// createSystemActor 创建内置的系统actor
+func (p *System) createSystemActor() {
+	if _, err := p.CreateActor(SystemActorID, &systemActor{}); err != nil {
+		panic(err)
+	}
+}
+
+func (p *systemActor) AliasID() string {
+	return SystemActorID
+}
+
+func (p *systemActor) OnInit() {
+	p.Remote().Register(eventFuncName, p.onEvent)
+	p.Remote().Register(SnapshotFuncName, p.snapshot)
+}
+
+// CanPassivate 系统actor不参与空闲回收
+func (*systemActor) CanPassivate() bool {
+	return false
+}
+
+// onEvent 接收其他节点发布的事件
+func (p *systemActor) onEvent(packet *cproto.EventPacket) {
+	p.system.onClusterEvent(packet)
+}
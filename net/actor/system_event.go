@@ -0,0 +1,152 @@
+package cherryActor
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+	cproto "github.com/cherry-game/cherry/net/proto"
+)
+
+const (
+	eventDedupeSize = 4096 // 事件去重的缓存数量
+)
+
+type (
+	// eventDedupe 跨节点事件去重(保留最近的eventDedupeSize条),只过滤同一次发布的重复投递
+	eventDedupe struct {
+		sync.Mutex
+		keys  []string
+		index int
+		set   map[string]struct{}
+	}
+)
+
+func newEventDedupe(size int) *eventDedupe {
+	return &eventDedupe{
+		keys: make([]string, size),
+		set:  make(map[string]struct{}, size),
+	}
+}
+
+// seen 返回事件是否已处理过,未处理过则记录
+func (p *eventDedupe) seen(key string) bool {
+	p.Lock()
+	defer p.Unlock()
+
+	if _, found := p.set[key]; found {
+		return true
+	}
+
+	if oldKey := p.keys[p.index]; oldKey != "" {
+		delete(p.set, oldKey)
+	}
+
+	p.keys[p.index] = key
+	p.index = (p.index + 1) % len(p.keys)
+	p.set[key] = struct{}{}
+
+	return false
+}
+
+// RegisterEvent 注册可跨节点接收的事件类型(以Name()区分)
+func (p *System) RegisterEvent(newEvent func() cfacade.IEventData) {
+	if newEvent == nil {
+		return
+	}
+
+	data := newEvent()
+	if data == nil {
+		clog.Warn("[RegisterEvent] Event is nil.")
+		return
+	}
+
+	p.eventTypes.Store(data.Name(), newEvent)
+}
+
+// publishEvent 按事件的发布范围,发布到其他节点
+func (p *System) publishEvent(data cfacade.IEventData, eventScope cfacade.IEventScope) {
+	scope, nodeType := eventScope.Scope()
+	if scope == cfacade.LocalEventScope || p.app == nil || p.app.Discovery() == nil {
+		return
+	}
+
+	var members []cfacade.IMember
+	switch scope {
+	case cfacade.NodeTypeEventScope:
+		members = p.app.Discovery().ListByType(nodeType, p.NodeId())
+	case cfacade.ClusterEventScope:
+		for nodeID, member := range p.app.Discovery().Map() {
+			if nodeID != p.NodeId() {
+				members = append(members, member)
+			}
+		}
+	}
+
+	if len(members) < 1 {
+		return
+	}
+
+	bytes, err := p.eventSerializer(data).Marshal(data)
+	if err != nil {
+		clog.Warnf("[PostEvent] Marshal event error. [name = %s, err = %v]", data.Name(), err)
+		return
+	}
+
+	packet := &cproto.EventPacket{
+		Name:         data.Name(),
+		UniqueId:     data.UniqueId(),
+		Data:         bytes,
+		SourceNodeId: p.NodeId(),
+		BuildTime:    time.Now().UnixMilli(),
+		Seq:          atomic.AddInt64(&p.eventSeq, 1),
+	}
+
+	source := cfacade.NewPath(p.NodeId(), SystemActorID)
+	for _, member := range members {
+		target := cfacade.NewPath(member.GetNodeId(), SystemActorID)
+		p.Call(source, target, eventFuncName, packet)
+	}
+}
+
+// onClusterEvent 接收其他节点发布的事件,并提交到本节点的actor
+func (p *System) onClusterEvent(packet *cproto.EventPacket) {
+	// 同一次发布(来源节点及发布序号相同)重复投递的事件只处理一次,再次发布的相同事件正常处理
+	key := packet.SourceNodeId + ":" + strconv.FormatInt(packet.Seq, 10)
+
+	if p.eventDedupe.seen(key) {
+		return
+	}
+
+	value, found := p.eventTypes.Load(packet.Name)
+	if !found {
+		clog.Warnf("[onClusterEvent] Event not registered. [name = %s, sourceNodeId = %s]",
+			packet.Name,
+			packet.SourceNodeId,
+		)
+		return
+	}
+
+	data := value.(func() cfacade.IEventData)()
+	if err := p.eventSerializer(data).Unmarshal(packet.Data, data); err != nil {
+		clog.Warnf("[onClusterEvent] Unmarshal event error. [name = %s, err = %v]", packet.Name, err)
+		return
+	}
+
+	if !p.postLocalEvent(data) {
+		p.deadEvent(data, "")
+	}
+}
+
+// eventSerializer 获取事件的序列化器
+func (p *System) eventSerializer(data cfacade.IEventData) cfacade.ISerializer {
+	if eventSerializer, ok := data.(cfacade.IEventSerializer); ok {
+		if serializer := eventSerializer.Serializer(); serializer != nil {
+			return serializer
+		}
+	}
+	return p.app.Serializer()
+}
@@ -0,0 +1,157 @@
+package cherryActor
+
+import (
+	"testing"
+	"time"
+
+	cfacade "github.com/cherry-game/cherry/facade"
+	cproto "github.com/cherry-game/cherry/net/proto"
+	cserializer "github.com/cherry-game/cherry/net/serializer"
+)
+
+type (
+	testLoginEvent struct {
+		PlayerId int64
+	}
+
+	// testJSONLoginEvent 使用json序列化的事件
+	testJSONLoginEvent struct {
+		testLoginEvent
+	}
+
+	testEventActor struct {
+		Base
+		received chan int64
+	}
+
+	// testProtobufApp 使用protobuf序列化的节点
+	testProtobufApp struct {
+		*testNodeApp
+	}
+)
+
+func (*testLoginEvent) Name() string {
+	return "login"
+}
+
+func (p *testLoginEvent) UniqueId() int64 {
+	return p.PlayerId
+}
+
+func (*testLoginEvent) Scope() (cfacade.EventScope, string) {
+	return cfacade.NodeTypeEventScope, "game"
+}
+
+func (*testJSONLoginEvent) Serializer() cfacade.ISerializer {
+	return cserializer.NewJSON()
+}
+
+func (*testProtobufApp) Serializer() cfacade.ISerializer {
+	return cserializer.NewProtobuf()
+}
+
+func (p *testEventActor) OnInit() {
+	p.Event().Register("login", func(e cfacade.IEventData) {
+		p.received <- e.UniqueId()
+	})
+}
+
+func TestClusterEvent(t *testing.T) {
+	systems := newTestNodes("game-1", "game-2")
+
+	receiver := &testEventActor{received: make(chan int64, 2)}
+	systems["game-2"].RegisterEvent(func() cfacade.IEventData {
+		return &testLoginEvent{}
+	})
+	systems["game-2"].CreateActor("receiver", receiver)
+	time.Sleep(10 * time.Millisecond)
+
+	systems["game-1"].PostEvent(&testLoginEvent{PlayerId: 1001})
+
+	select {
+	case playerId := <-receiver.received:
+		if playerId != 1001 {
+			t.Fatalf("playerId = %d", playerId)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event was not delivered to the remote node")
+	}
+}
+
+func TestClusterEventProtobuf(t *testing.T) {
+	systems := newTestNodes("game-1", "game-2")
+	for _, system := range systems {
+		system.SetApp(&testProtobufApp{testNodeApp: system.app.(*testNodeApp)})
+	}
+
+	receiver := &testEventActor{received: make(chan int64, 8)}
+	systems["game-2"].RegisterEvent(func() cfacade.IEventData {
+		return &testJSONLoginEvent{}
+	})
+	systems["game-2"].CreateActor("receiver", receiver)
+	time.Sleep(10 * time.Millisecond)
+
+	// 非proto.Message的事件需指定序列化器,否则无法发布到其他节点
+	systems["game-1"].PostEvent(&testLoginEvent{PlayerId: 1001})
+
+	// 再次发布的相同事件(如同一玩家再次登录)正常处理
+	for i := 0; i < 2; i++ {
+		systems["game-1"].PostEvent(&testJSONLoginEvent{testLoginEvent{PlayerId: 1002}})
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case playerId := <-receiver.received:
+			if playerId != 1002 {
+				t.Fatalf("playerId = %d", playerId)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d was not delivered to the remote node", i)
+		}
+	}
+
+	// 同一次发布的重复投递只处理一次
+	packet := &cproto.EventPacket{
+		Name:         "login",
+		UniqueId:     1003,
+		Data:         []byte(`{"PlayerId":1003}`),
+		SourceNodeId: "game-1",
+		Seq:          1,
+	}
+
+	source := cfacade.NewPath("game-1", SystemActorID)
+	target := cfacade.NewPath("game-2", SystemActorID)
+	for i := 0; i < 2; i++ {
+		systems["game-1"].Call(source, target, eventFuncName, packet)
+	}
+
+	select {
+	case playerId := <-receiver.received:
+		if playerId != 1003 {
+			t.Fatalf("playerId = %d", playerId)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("redelivered event was not delivered")
+	}
+
+	select {
+	case playerId := <-receiver.received:
+		t.Fatalf("unexpected event, playerId = %d", playerId)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestClusterEventDedupe(t *testing.T) {
+	dedupe := newEventDedupe(2)
+
+	if dedupe.seen("a") || !dedupe.seen("a") {
+		t.Fatal("second event should be suppressed")
+	}
+
+	dedupe.seen("b")
+	dedupe.seen("c")
+
+	if dedupe.seen("a") {
+		t.Fatal("evicted key should be accepted again")
+	}
+}
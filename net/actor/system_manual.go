@@ -0,0 +1,101 @@
+package cherryActor
+
+import (
+	"context"
+)
+
+type (
+	// OutboundFunc Call/CallWait发送前的拦截函数,返回handled=true时不再发送消息,code作为调用结果返回
+	// wait为true时为CallWait,reply为接收回复的对象.可用于测试时捕获及模拟actor发出的调用
+	OutboundFunc func(ctx context.Context, source, target, funcName string, arg, reply interface{}, wait bool) (code int32, handled bool)
+)
+
+// SetOutbound 设置Call/CallWait的发送拦截函数(需在创建actor前调用)
+func (p *System) SetOutbound(fn OutboundFunc) {
+	p.outbound = fn
+}
+
+// SetManualRun 设置手动驱动模式(需在创建actor前调用,通常用于测试)
+// 手动模式下actor不启动goroutine,创建时同步执行OnInit,消息由RunPending在调用方的goroutine中处理,
+// CallAsync也会同步等待结果后再把回调投递到actor
+func (p *System) SetManualRun(enable bool) {
+	p.manualRun = enable
+}
+
+// ManualRun 是否为手动驱动模式
+func (p *System) ManualRun() bool {
+	return p.manualRun
+}
+
+// RunPending 手动模式下依次处理所有actor的待处理消息,直到没有消息为止,返回处理的消息数量
+// 每轮按actor的创建顺序每个actor处理一条消息(按消息通道优先级选取)
+func (p *System) RunPending() int {
+	if !p.manualRun {
+		return 0
+	}
+
+	count := 0
+	for {
+		processed := false
+		for _, thisActor := range p.runningActors() {
+			if thisActor.runOnce() {
+				processed = true
+				count++
+			}
+		}
+
+		if !processed {
+			return count
+		}
+	}
+}
+
+// spawn 启动actor
+func (p *System) spawn(thisActor *Actor) {
+	if !p.manualRun {
+		go thisActor.run()
+		return
+	}
+
+	p.manualMu.Lock()
+	p.manualActors = append(p.manualActors, thisActor)
+	p.manualMu.Unlock()
+
+	thisActor.onInit()
+}
+
+func (p *System) runningActors() []*Actor {
+	p.manualMu.Lock()
+	defer p.manualMu.Unlock()
+
+	return append([]*Actor(nil), p.manualActors...)
+}
+
+func (p *System) removeRunning(thisActor *Actor) {
+	p.manualMu.Lock()
+	defer p.manualMu.Unlock()
+
+	for i, value := range p.manualActors {
+		if value == thisActor {
+			p.manualActors = append(p.manualActors[:i], p.manualActors[i+1:]...)
+			return
+		}
+	}
+}
+
+// runOnce 手动模式下处理一条消息,邮箱为空时处理退出信号.返回是否有处理
+func (p *Actor) runOnce() bool {
+	if p.processNext() {
+		return true
+	}
+
+	select {
+	case <-p.close:
+		p.setState(StopState)
+		p.system.removeRunning(p)
+		p.onStop()
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,167 @@
+package cherryActor
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+	cproto "github.com/cherry-game/cherry/net/proto"
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	SnapshotFuncName = "snapshot" // 系统actor获取节点actor快照的函数名
+)
+
+type (
+	// SystemSnapshot 节点的actor快照
+	SystemSnapshot struct {
+		NodeID     string           `json:"nodeId"`
+		Time       int64            `json:"time"`       // 快照时间(ms)
+		ActorCount int              `json:"actorCount"` // actor数量(包括子actor)
+		Actors     []*ActorSnapshot `json:"actors"`
+	}
+
+	// ActorSnapshot actor的运行状态
+	ActorSnapshot struct {
+		Path             string           `json:"path"`
+		Handler          string           `json:"handler"`          // handler类型
+		State            string           `json:"state"`            // 运行状态
+		LocalCount       int32            `json:"localCount"`       // local邮箱深度
+		RemoteCount      int32            `json:"remoteCount"`      // remote邮箱深度
+		SystemCount      int32            `json:"systemCount"`      // system邮箱深度
+		EventCount       int32            `json:"eventCount"`       // 事件队列深度
+		LocalDropped     int64            `json:"localDropped"`     // local邮箱丢弃的消息数量
+		RemoteDropped    int64            `json:"remoteDropped"`    // remote邮箱丢弃的消息数量
+		EventDropped     int64            `json:"eventDropped"`     // 丢弃的事件数量
+		LocalFuncs       []string         `json:"localFuncs"`       // 已注册的local函数
+		RemoteFuncs      []string         `json:"remoteFuncs"`      // 已注册的remote函数
+		TimerCount       int              `json:"timerCount"`       // 定时器数量
+		ChildCount       int              `json:"childCount"`       // 子actor数量
+		LastAt           int64            `json:"lastAt"`           // 最后处理消息的时间(秒)
+		ArrivalElapsed   int64            `json:"arrivalElapsed"`   // 最后一条消息的到达耗时(ms)
+		ExecutionElapsed int64            `json:"executionElapsed"` // 最后一条消息的执行耗时(ms)
+		MigratedTo       string           `json:"migratedTo,omitempty"`
+		Children         []*ActorSnapshot `json:"children,omitempty"`
+	}
+)
+
+func (s State) String() string {
+	switch s {
+	case InitState:
+		return "init"
+	case WorkerState:
+		return "worker"
+	case FreeState:
+		return "free"
+	case StopState:
+		return "stop"
+	}
+	return "unknown"
+}
+
+// Snapshot 获取本节点所有actor的运行状态
+func (p *System) Snapshot() *SystemSnapshot {
+	snapshot := &SystemSnapshot{
+		NodeID: p.NodeId(),
+		Time:   time.Now().UnixMilli(),
+	}
+
+	p.actorMap.Range(func(_, value any) bool {
+		if thisActor, ok := value.(*Actor); ok {
+			actorSnapshot := thisActor.snapshot()
+			snapshot.Actors = append(snapshot.Actors, actorSnapshot)
+			snapshot.ActorCount += 1 + actorSnapshot.ChildCount
+		}
+		return true
+	})
+
+	sortSnapshots(snapshot.Actors)
+
+	return snapshot
+}
+
+// SnapshotJSON 获取本节点所有actor的运行状态(json格式)
+func (p *System) SnapshotJSON() ([]byte, error) {
+	return jsoniter.Marshal(p.Snapshot())
+}
+
+// NodeSnapshot 获取指定节点的actor快照,通过目标节点的系统actor获取
+func (p *System) NodeSnapshot(nodeID string) (*SystemSnapshot, int32) {
+	if nodeID == "" || nodeID == p.NodeId() {
+		return p.Snapshot(), ccode.OK
+	}
+
+	source := cfacade.NewPath(p.NodeId(), SystemActorID)
+	target := cfacade.NewPath(nodeID, SystemActorID)
+
+	rsp := &cproto.Response{}
+	if code := p.CallWait(source, target, SnapshotFuncName, nil, rsp); ccode.IsFail(code) {
+		return nil, code
+	}
+
+	snapshot := &SystemSnapshot{}
+	if err := jsoniter.Unmarshal(rsp.Data, snapshot); err != nil {
+		clog.Warnf("[NodeSnapshot] Unmarshal error. [nodeID = %s, err = %v]", nodeID, err)
+		return nil, ccode.ActorUnmarshalError
+	}
+
+	return snapshot, ccode.OK
+}
+
+// snapshot 获取actor的运行状态.邮箱深度等统计数据为近似值
+// 在调用方goroutine中执行,只读取可并发访问的字段(原子变量、加锁的数据)
+func (p *Actor) snapshot() *ActorSnapshot {
+	snapshot := &ActorSnapshot{
+		Path:             p.path.String(),
+		Handler:          p.typeName,
+		State:            p.State().String(),
+		LocalCount:       p.localMail.Count(),
+		RemoteCount:      p.remoteMail.Count(),
+		SystemCount:      p.systemMail.Count(),
+		EventCount:       p.event.Count(),
+		LocalDropped:     p.localMail.Dropped(),
+		RemoteDropped:    p.remoteMail.Dropped(),
+		EventDropped:     p.event.Dropped(),
+		LocalFuncs:       p.localMail.funcNames(),
+		RemoteFuncs:      p.remoteMail.funcNames(),
+		TimerCount:       p.timer.Count(),
+		LastAt:           p.LastAt(),
+		ArrivalElapsed:   atomic.LoadInt64(&p.arrivalElapsed),
+		ExecutionElapsed: atomic.LoadInt64(&p.executionElapsed),
+		MigratedTo:       p.MigratedTo(),
+	}
+
+	if p.path.IsParent() {
+		p.child.Each(func(iActor cfacade.IActor) {
+			if childActor, ok := iActor.(*Actor); ok {
+				snapshot.Children = append(snapshot.Children, childActor.snapshot())
+			}
+		})
+
+		snapshot.ChildCount = len(snapshot.Children)
+		sortSnapshots(snapshot.Children)
+	}
+
+	return snapshot
+}
+
+func sortSnapshots(list []*ActorSnapshot) {
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Path < list[j].Path
+	})
+}
+
+// snapshot 返回本节点的actor快照(json格式,保存在Response.Data中)
+func (p *systemActor) snapshot() (*cproto.Response, int32) {
+	data, err := p.system.SnapshotJSON()
+	if err != nil {
+		clog.Warnf("[snapshot] Marshal error. [err = %v]", err)
+		return nil, ccode.ActorMarshalError
+	}
+
+	return &cproto.Response{Data: data}, ccode.OK
+}
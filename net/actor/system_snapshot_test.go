@@ -0,0 +1,72 @@
+package cherryActor
+
+import (
+	"testing"
+	"time"
+
+	ccode "github.com/cherry-game/cherry/code"
+)
+
+type testSnapshotActor struct {
+	Base
+}
+
+func (p *testSnapshotActor) OnInit() {
+	p.Remote().Register("ping", func() {})
+	p.Timer().Add(time.Minute, func() {})
+	p.Child().Create("1", &Base{})
+}
+
+func TestNodeSnapshot(t *testing.T) {
+	systems := newTestNodes("game-1", "game-2")
+	systems["game-2"].CreateActor("room", &testSnapshotActor{})
+	systems["game-2"].CallWait("game-2.tester", "game-2.room", "ping", nil, nil)
+
+	snapshot, code := systems["game-1"].NodeSnapshot("game-2")
+	if code != ccode.OK {
+		t.Fatalf("snapshot code = %d", code)
+	}
+
+	var room *ActorSnapshot
+	for _, actor := range snapshot.Actors {
+		if actor.Path == "game-2.room" {
+			room = actor
+		}
+	}
+
+	if room == nil {
+		t.Fatalf("room not found in snapshot. [actors = %d]", len(snapshot.Actors))
+	}
+
+	if room.State != "worker" || room.TimerCount != 1 || room.ChildCount != 1 || len(room.RemoteFuncs) < 1 {
+		t.Fatalf("room = %+v", room)
+	}
+
+	if room.Children[0].Path != "game-2.room.1" {
+		t.Fatalf("child path = %s", room.Children[0].Path)
+	}
+}
+
+func TestSnapshotConcurrent(t *testing.T) {
+	system := NewSystem()
+	system.SetApp(&testApp{})
+	system.CreateActor("room", &testSnapshotActor{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			system.Snapshot()
+		}
+	}()
+
+	for i := 0; i < 50; i++ {
+		system.CallWait(".tester", ".room", "ping", nil, nil)
+	}
+	<-done
+
+	snapshot := system.Snapshot()
+	if len(snapshot.Actors) != 1 || snapshot.Actors[0].Handler != "cherryActor.testSnapshotActor" {
+		t.Fatalf("actors = %+v", snapshot.Actors)
+	}
+}
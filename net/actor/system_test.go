@@ -0,0 +1,89 @@
+package cherryActor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cfacade "github.com/cherry-game/cherry/facade"
+	cserializer "github.com/cherry-game/cherry/net/serializer"
+)
+
+type testBlockActor struct {
+	Base
+}
+
+func (p *testBlockActor) OnInit() {
+	p.Remote().Register("block", p.block)
+}
+
+func (p *testBlockActor) block() {
+	time.Sleep(200 * time.Millisecond)
+}
+
+func TestCallWaitTimeout(t *testing.T) {
+	system := NewSystem()
+	system.SetCallTimeout(50 * time.Millisecond)
+	system.CreateActor("target", &testBlockActor{})
+
+	code := system.CallWait(".source", ".target", "block", nil, nil)
+	if code != ccode.ActorCallTimeout {
+		t.Fatalf("code = %d, want %d", code, ccode.ActorCallTimeout)
+	}
+}
+
+func TestCallWaitContextCanceled(t *testing.T) {
+	system := NewSystem()
+	system.SetCallTimeout(time.Second)
+	system.CreateActor("target", &testBlockActor{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	code := system.CallWaitContext(ctx, ".source", ".target", "block", nil, nil)
+	if code != ccode.ActorCallCanceled {
+		t.Fatalf("code = %d, want %d", code, ccode.ActorCallCanceled)
+	}
+}
+
+type testApp struct {
+	cfacade.IApplication
+}
+
+func (*testApp) NodeId() string {
+	return ""
+}
+
+func (*testApp) Serializer() cfacade.ISerializer {
+	return cserializer.NewJSON()
+}
+
+type testAsyncActor struct {
+	Base
+	result chan int32
+}
+
+func (p *testAsyncActor) OnInit() {
+	p.CallAsync(".target", "block", nil, nil, 30*time.Millisecond, func(_ interface{}, code int32) {
+		p.result <- code
+	})
+}
+
+func TestCallAsyncTimeout(t *testing.T) {
+	system := NewSystem()
+	system.SetApp(&testApp{})
+	system.CreateActor("target", &testBlockActor{})
+
+	caller := &testAsyncActor{result: make(chan int32, 1)}
+	system.CreateActor("caller", caller)
+
+	select {
+	case code := <-caller.result:
+		if code != ccode.ActorCallTimeout {
+			t.Fatalf("code = %d, want %d", code, ccode.ActorCallTimeout)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("callback was not invoked")
+	}
+}
@@ -0,0 +1,125 @@
+package cherryTestkit
+
+import (
+	cfacade "github.com/cherry-game/cherry/facade"
+	cserializer "github.com/cherry-game/cherry/net/serializer"
+)
+
+type (
+	// App 测试用的application,不启动组件、集群及发现服务
+	App struct {
+		nodeID     string
+		nodeType   string
+		serializer cfacade.ISerializer
+		system     cfacade.IActorSystem
+		components []cfacade.IComponent
+		dieChan    chan bool
+	}
+)
+
+// NewApp 创建测试用的application,默认使用json序列化
+func NewApp(nodeID, nodeType string) *App {
+	return &App{
+		nodeID:     nodeID,
+		nodeType:   nodeType,
+		serializer: cserializer.NewJSON(),
+		dieChan:    make(chan bool),
+	}
+}
+
+// SetSerializer 设置序列化
+func (p *App) SetSerializer(serializer cfacade.ISerializer) {
+	if serializer != nil {
+		p.serializer = serializer
+	}
+}
+
+func (p *App) NodeId() string {
+	return p.nodeID
+}
+
+func (p *App) NodeType() string {
+	return p.nodeType
+}
+
+func (p *App) Address() string {
+	return ""
+}
+
+func (p *App) RpcAddress() string {
+	return ""
+}
+
+func (p *App) Settings() cfacade.ProfileJSON {
+	return nil
+}
+
+func (p *App) Enabled() bool {
+	return true
+}
+
+func (p *App) Running() bool {
+	return true
+}
+
+func (p *App) DieChan() chan bool {
+	return p.dieChan
+}
+
+func (p *App) IsFrontend() bool {
+	return false
+}
+
+func (p *App) Register(components ...cfacade.IComponent) {
+	p.components = append(p.components, components...)
+}
+
+func (p *App) Find(name string) cfacade.IComponent {
+	for _, component := range p.components {
+		if component.Name() == name {
+			return component
+		}
+	}
+	return nil
+}
+
+func (p *App) Remove(name string) cfacade.IComponent {
+	for i, component := range p.components {
+		if component.Name() == name {
+			p.components = append(p.components[:i], p.components[i+1:]...)
+			return component
+		}
+	}
+	return nil
+}
+
+func (p *App) All() []cfacade.IComponent {
+	return p.components
+}
+
+func (p *App) OnShutdown(_ ...func()) {
+}
+
+func (p *App) Startup() {
+}
+
+func (p *App) Shutdown() {
+}
+
+func (p *App) Serializer() cfacade.ISerializer {
+	return p.serializer
+}
+
+// Discovery 测试环境没有发现服务
+func (p *App) Discovery() cfacade.IDiscovery {
+	return nil
+}
+
+// Cluster 测试环境没有集群服务,发往其他节点的调用由Kit捕获
+func (p *App) Cluster() cfacade.ICluster {
+	return nil
+}
+
+func (p *App) ActorSystem() cfacade.IActorSystem {
+	return p.system
+}
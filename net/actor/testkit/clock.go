@@ -0,0 +1,166 @@
+package cherryTestkit
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	cactor "github.com/cherry-game/cherry/net/actor"
+)
+
+var (
+	// DefaultStartTime 虚拟时钟的默认起始时间
+	DefaultStartTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+)
+
+type (
+	// Clock 虚拟时钟,实现cherryActor.ITimerScheduler
+	// 定时器只在Advance时按到期时间顺序触发,不受真实时间影响
+	Clock struct {
+		sync.Mutex
+		now   time.Time
+		seq   uint64
+		tasks []*clockTask
+	}
+
+	clockTask struct {
+		clock    *Clock
+		id       uint64
+		seq      uint64 // 到期时间相同时按添加顺序触发
+		at       time.Time
+		fn       func()
+		schedule cactor.ITimerSchedule // 为nil时只执行一次
+	}
+)
+
+func NewClock(start time.Time) *Clock {
+	return &Clock{
+		now: start,
+	}
+}
+
+// Now 虚拟时钟的当前时间
+func (c *Clock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.now
+}
+
+// Pending 未触发的定时任务数量
+func (c *Clock) Pending() int {
+	c.Lock()
+	defer c.Unlock()
+
+	return len(c.tasks)
+}
+
+func (c *Clock) AfterFunc(id uint64, delay time.Duration, fn func(), _ ...bool) cactor.ITimerTask {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.add(&clockTask{id: id, at: c.now.Add(delay), fn: fn})
+}
+
+func (c *Clock) ScheduleFunc(id uint64, s cactor.ITimerSchedule, fn func(), _ ...bool) cactor.ITimerTask {
+	c.Lock()
+	defer c.Unlock()
+
+	at := s.Next(c.now)
+	if at.IsZero() {
+		return nil
+	}
+
+	return c.add(&clockTask{id: id, at: at, fn: fn, schedule: s})
+}
+
+// Advance 时钟前进d,按到期时间顺序触发期间的定时任务,返回触发的数量
+func (c *Clock) Advance(d time.Duration) int {
+	return c.advance(c.Now().Add(d), nil)
+}
+
+// AdvanceTo 时钟前进到t(早于当前时间则不处理)
+func (c *Clock) AdvanceTo(t time.Time) int {
+	return c.advance(t, nil)
+}
+
+// advance 依次触发到期的任务,每次触发后调用after
+func (c *Clock) advance(target time.Time, after func()) int {
+	count := 0
+	for {
+		task := c.popExpired(target)
+		if task == nil {
+			break
+		}
+
+		task.fn()
+		count++
+
+		if after != nil {
+			after()
+		}
+	}
+
+	c.Lock()
+	if target.After(c.now) {
+		c.now = target
+	}
+	c.Unlock()
+
+	return count
+}
+
+// popExpired 取出最早到期的任务,循环任务重新加入调度
+func (c *Clock) popExpired(target time.Time) *clockTask {
+	c.Lock()
+	defer c.Unlock()
+
+	if len(c.tasks) < 1 || c.tasks[0].at.After(target) {
+		return nil
+	}
+
+	task := c.tasks[0]
+	c.tasks = c.tasks[1:]
+	c.now = task.at
+
+	if task.schedule != nil {
+		if next := task.schedule.Next(task.at); !next.IsZero() && next.After(task.at) {
+			c.add(&clockTask{id: task.id, at: next, fn: task.fn, schedule: task.schedule})
+		}
+	}
+
+	return task
+}
+
+func (c *Clock) add(task *clockTask) *clockTask {
+	c.seq++
+	task.clock = c
+	task.seq = c.seq
+
+	index := sort.Search(len(c.tasks), func(i int) bool {
+		other := c.tasks[i]
+		return other.at.After(task.at) || (other.at.Equal(task.at) && other.seq > task.seq)
+	})
+
+	c.tasks = append(c.tasks, nil)
+	copy(c.tasks[index+1:], c.tasks[index:])
+	c.tasks[index] = task
+
+	return task
+}
+
+// Stop 停止定时任务(循环任务的后续调度也会停止)
+func (t *clockTask) Stop() bool {
+	c := t.clock
+	c.Lock()
+	defer c.Unlock()
+
+	for i, task := range c.tasks {
+		if task.id == t.id {
+			c.tasks = append(c.tasks[:i], c.tasks[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
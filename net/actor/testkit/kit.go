@@ -0,0 +1,504 @@
+// Package cherryTestkit actor的确定性测试工具
+// 在单个goroutine中同步驱动actor:注入local/remote/event消息,捕获actor发出的Call/CallWait
+// (包括Response/Push/Kick),模拟CallWait的回复,并通过虚拟时钟触发定时器
+package cherryTestkit
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cfacade "github.com/cherry-game/cherry/facade"
+	cactor "github.com/cherry-game/cherry/net/actor"
+	cproto "github.com/cherry-game/cherry/net/proto"
+)
+
+const (
+	NodeID         = "test"                 // 默认的节点id
+	NodeType       = "test"                 // 默认的节点类型
+	AgentPath      = "gate.agent"           // NewSession创建的session所属的网关actor
+	SourceID       = "testkit"              // 注入消息的来源actor id
+	NotStubbedCode = ccode.ActorCallTimeout // 未设置模拟回复的CallWait返回码
+)
+
+type (
+	// Kit actor测试工具.actor运行在手动驱动模式下,所有消息在调用方的goroutine中同步处理
+	// 发往当前节点的Call会投递到对应的actor,发往其他节点的Call及所有CallWait只记录不发送
+	Kit struct {
+		t      testing.TB
+		app    *App
+		system *cactor.System
+		clock  *Clock
+		mu     sync.Mutex
+		calls  []*Call
+		stubs  []*stub
+	}
+
+	// Call actor发出的调用
+	Call struct {
+		Source   string      // 来源actor path
+		Target   string      // 目标actor path
+		FuncName string      // 函数名
+		Arg      interface{} // 参数
+		Wait     bool        // 是否为CallWait
+		Code     int32       // CallWait的返回码
+		kit      *Kit
+	}
+
+	// StubFunc 模拟CallWait的回复,reply会序列化后填充到调用方的reply对象
+	StubFunc func(call *Call) (reply interface{}, code int32)
+
+	stub struct {
+		target   string
+		funcName string
+		fn       StubFunc
+	}
+)
+
+// New 创建测试工具,nodeID为空时使用NodeID.测试结束时自动停止所有actor
+func New(t testing.TB, nodeID ...string) *Kit {
+	id := NodeID
+	if len(nodeID) > 0 && nodeID[0] != "" {
+		id = nodeID[0]
+	}
+
+	kit := &Kit{
+		t:      t,
+		app:    NewApp(id, NodeType),
+		system: cactor.NewSystem(),
+		clock:  NewClock(DefaultStartTime),
+	}
+
+	kit.app.system = kit.system
+	kit.system.SetApp(kit.app)
+	kit.system.SetManualRun(true)
+	kit.system.SetTimerScheduler(kit.clock)
+	kit.system.SetOutbound(kit.outbound)
+
+	t.Cleanup(kit.system.Stop)
+
+	return kit
+}
+
+func (k *Kit) App() *App {
+	return k.app
+}
+
+func (k *Kit) System() *cactor.System {
+	return k.system
+}
+
+func (k *Kit) Clock() *Clock {
+	return k.clock
+}
+
+// Spawn 创建actor并同步执行OnInit
+func (k *Kit) Spawn(actorID string, handler cfacade.IActorHandler) *cactor.Actor {
+	k.t.Helper()
+
+	iActor, err := k.system.CreateActor(actorID, handler)
+	if err != nil {
+		k.t.Fatalf("[testkit] Spawn actor fail. [actorID = %s, err = %v]", actorID, err)
+	}
+
+	k.Run()
+
+	return iActor.(*cactor.Actor)
+}
+
+// Path 当前节点的actor path
+func (k *Kit) Path(actorID string, childID ...string) string {
+	if len(childID) > 0 && childID[0] != "" {
+		return cfacade.NewChildPath(k.app.NodeId(), actorID, childID[0])
+	}
+	return cfacade.NewPath(k.app.NodeId(), actorID)
+}
+
+// NewSession 创建网关session,actor通过该session发送的Response/Push/Kick会被捕获
+func (k *Kit) NewSession(sid string, uid int64) *cproto.Session {
+	return &cproto.Session{
+		Sid:       sid,
+		Uid:       uid,
+		AgentPath: AgentPath,
+		Data:      map[string]string{},
+	}
+}
+
+// Run 处理所有待处理的消息,返回处理的数量
+func (k *Kit) Run() int {
+	return k.system.RunPending()
+}
+
+// Local 注入客户端消息(模拟网关转发),arg会先序列化为[]byte
+func (k *Kit) Local(target, funcName string, session *cproto.Session, arg interface{}) int32 {
+	k.t.Helper()
+
+	message := k.message(target, funcName)
+	message.Session = session
+	message.Args = k.marshal(arg)
+
+	code := k.system.PostLocal(&message)
+	k.Run()
+
+	return code
+}
+
+// Remote 注入remote消息(不等待回复)
+func (k *Kit) Remote(target, funcName string, arg interface{}) int32 {
+	message := k.message(target, funcName)
+	message.Args = arg
+
+	code := k.system.PostRemote(&message)
+	k.Run()
+
+	return code
+}
+
+// Ask 注入remote消息并同步获取回复,处理完所有消息后仍未回复则返回ccode.ActorCallTimeout
+func (k *Kit) Ask(target, funcName string, arg, reply interface{}) int32 {
+	k.t.Helper()
+
+	message := k.message(target, funcName)
+	message.Args = arg
+	message.ChanResult = make(chan interface{}, 1)
+
+	if code := k.system.PostRemote(&message); ccode.IsFail(code) {
+		return code
+	}
+
+	k.Run()
+
+	var result interface{}
+	select {
+	case result = <-message.ChanResult:
+	default:
+		return ccode.ActorCallTimeout
+	}
+
+	rsp, ok := result.(*cproto.Response)
+	if !ok || rsp == nil {
+		return ccode.RPCRemoteExecuteError
+	}
+
+	if ccode.IsFail(rsp.Code) {
+		return rsp.Code
+	}
+
+	if reply != nil && rsp.Data != nil {
+		if err := k.app.Serializer().Unmarshal(rsp.Data, reply); err != nil {
+			k.t.Fatalf("[testkit] Unmarshal reply fail. [target = %s -> %s, err = %v]", target, funcName, err)
+		}
+	}
+
+	return ccode.OK
+}
+
+// Event 注入事件
+func (k *Kit) Event(data cfacade.IEventData) {
+	k.system.PostEvent(data)
+	k.Run()
+}
+
+// Advance 虚拟时钟前进d,按顺序触发到期的定时器,每次触发后处理所有消息
+func (k *Kit) Advance(d time.Duration) int {
+	return k.clock.advance(k.clock.Now().Add(d), func() {
+		k.Run()
+	})
+}
+
+// Now 虚拟时钟的当前时间
+func (k *Kit) Now() time.Time {
+	return k.clock.Now()
+}
+
+// Stub 设置CallWait的模拟回复,target或funcName为空时匹配任意值,后设置的优先
+func (k *Kit) Stub(target, funcName string, fn StubFunc) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.stubs = append(k.stubs, &stub{
+		target:   target,
+		funcName: funcName,
+		fn:       fn,
+	})
+}
+
+// StubReply 设置CallWait返回固定的回复
+func (k *Kit) StubReply(target, funcName string, reply interface{}, code int32) {
+	k.Stub(target, funcName, func(_ *Call) (interface{}, int32) {
+		return reply, code
+	})
+}
+
+// Calls 捕获的调用(不包括actor内部函数)
+func (k *Kit) Calls() []*Call {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return append([]*Call(nil), k.calls...)
+}
+
+// Find 查找捕获的调用,target或funcName为空时匹配任意值
+func (k *Kit) Find(target, funcName string) []*Call {
+	var list []*Call
+	for _, call := range k.Calls() {
+		if match(target, call.Target) && match(funcName, call.FuncName) {
+			list = append(list, call)
+		}
+	}
+	return list
+}
+
+// ExpectCall 断言存在匹配的调用,返回最后一个
+func (k *Kit) ExpectCall(target, funcName string) *Call {
+	k.t.Helper()
+
+	list := k.Find(target, funcName)
+	if len(list) < 1 {
+		k.t.Fatalf("[testkit] Call not found. [target = %s, funcName = %s, calls = %s]", target, funcName, k.callNames())
+		return nil
+	}
+
+	return list[len(list)-1]
+}
+
+// ExpectNoCall 断言不存在匹配的调用
+func (k *Kit) ExpectNoCall(target, funcName string) {
+	k.t.Helper()
+
+	if list := k.Find(target, funcName); len(list) > 0 {
+		k.t.Fatalf("[testkit] Unexpected call. [target = %s, funcName = %s, count = %d]", target, funcName, len(list))
+	}
+}
+
+// Responses 捕获的客户端回复
+func (k *Kit) Responses() []*cproto.PomeloResponse {
+	return findArgs[*cproto.PomeloResponse](k, cactor.ResponseFuncName)
+}
+
+// Pushes 捕获的客户端推送
+func (k *Kit) Pushes() []*cproto.PomeloPush {
+	return findArgs[*cproto.PomeloPush](k, cactor.PushFuncName)
+}
+
+// Kicks 捕获的踢下线
+func (k *Kit) Kicks() []*cproto.PomeloKick {
+	return findArgs[*cproto.PomeloKick](k, cactor.KickFuncName)
+}
+
+// ExpectResponse 断言存在客户端回复,并将最后一个回复的数据解析到v(v为nil时不解析)
+func (k *Kit) ExpectResponse(v interface{}) *cproto.PomeloResponse {
+	k.t.Helper()
+
+	list := k.Responses()
+	if len(list) < 1 {
+		k.t.Fatalf("[testkit] Response not found. [calls = %s]", k.callNames())
+		return nil
+	}
+
+	rsp := list[len(list)-1]
+	k.Unmarshal(rsp.Data, v)
+
+	return rsp
+}
+
+// ExpectPush 断言存在route的推送,并将最后一个推送的数据解析到v(v为nil时不解析)
+func (k *Kit) ExpectPush(route string, v interface{}) *cproto.PomeloPush {
+	k.t.Helper()
+
+	var found *cproto.PomeloPush
+	for _, push := range k.Pushes() {
+		if push.Route == route {
+			found = push
+		}
+	}
+
+	if found == nil {
+		k.t.Fatalf("[testkit] Push not found. [route = %s, calls = %s]", route, k.callNames())
+		return nil
+	}
+
+	k.Unmarshal(found.Data, v)
+
+	return found
+}
+
+// ExpectKick 断言存在踢下线
+func (k *Kit) ExpectKick() *cproto.PomeloKick {
+	k.t.Helper()
+
+	list := k.Kicks()
+	if len(list) < 1 {
+		k.t.Fatalf("[testkit] Kick not found. [calls = %s]", k.callNames())
+		return nil
+	}
+
+	return list[len(list)-1]
+}
+
+// ResetCalls 清空捕获的调用
+func (k *Kit) ResetCalls() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.calls = nil
+}
+
+// Unmarshal 使用application的序列化解析数据,v为nil时不处理
+func (k *Kit) Unmarshal(data []byte, v interface{}) {
+	k.t.Helper()
+
+	if v == nil {
+		return
+	}
+
+	if err := k.app.Serializer().Unmarshal(data, v); err != nil {
+		k.t.Fatalf("[testkit] Unmarshal fail. [err = %v]", err)
+	}
+}
+
+// Decode 将调用的参数解析到v
+func (c *Call) Decode(v interface{}) {
+	c.kit.t.Helper()
+
+	data, ok := c.Arg.([]byte)
+	if !ok {
+		data = c.kit.marshal(c.Arg)
+	}
+
+	c.kit.Unmarshal(data, v)
+}
+
+// outbound 捕获actor发出的调用
+func (k *Kit) outbound(_ context.Context, source, target, funcName string, arg, reply interface{}, wait bool) (int32, bool) {
+	// actor内部函数(定时器、异步回调等)直接投递
+	if isInternalFunc(funcName) {
+		return ccode.OK, false
+	}
+
+	call := &Call{
+		Source:   source,
+		Target:   target,
+		FuncName: funcName,
+		Arg:      arg,
+		Wait:     wait,
+		kit:      k,
+	}
+
+	if !wait {
+		k.record(call)
+		// 发往当前节点的消息正常投递,其他节点的只记录
+		return ccode.OK, !k.isLocal(target)
+	}
+
+	call.Code = k.stubReply(call, reply)
+	k.record(call)
+
+	return call.Code, true
+}
+
+func (k *Kit) stubReply(call *Call, reply interface{}) int32 {
+	fn := k.findStub(call.Target, call.FuncName)
+	if fn == nil {
+		k.t.Logf("[testkit] CallWait not stubbed. [target = %s, funcName = %s]", call.Target, call.FuncName)
+		return NotStubbedCode
+	}
+
+	value, code := fn(call)
+	if ccode.IsFail(code) || reply == nil || value == nil {
+		return code
+	}
+
+	if err := k.app.Serializer().Unmarshal(k.marshal(value), reply); err != nil {
+		k.t.Errorf("[testkit] Stub reply unmarshal fail. [target = %s, funcName = %s, err = %v]", call.Target, call.FuncName, err)
+		return ccode.ActorUnmarshalError
+	}
+
+	return code
+}
+
+func (k *Kit) findStub(target, funcName string) StubFunc {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for i := len(k.stubs) - 1; i >= 0; i-- {
+		s := k.stubs[i]
+		if match(s.target, target) && match(s.funcName, funcName) {
+			return s.fn
+		}
+	}
+
+	return nil
+}
+
+func (k *Kit) record(call *Call) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.calls = append(k.calls, call)
+}
+
+func (k *Kit) isLocal(target string) bool {
+	path, err := cfacade.ToActorPath(target)
+	if err != nil {
+		return false
+	}
+	return path.NodeID == "" || path.NodeID == k.app.NodeId()
+}
+
+func (k *Kit) message(target, funcName string) cfacade.Message {
+	message := cfacade.GetMessage()
+	message.Source = k.Path(SourceID)
+	message.Target = target
+	message.FuncName = funcName
+	return message
+}
+
+func (k *Kit) marshal(v interface{}) []byte {
+	k.t.Helper()
+
+	if v == nil {
+		return nil
+	}
+
+	if data, ok := v.([]byte); ok {
+		return data
+	}
+
+	data, err := k.app.Serializer().Marshal(v)
+	if err != nil {
+		k.t.Fatalf("[testkit] Marshal fail. [v = %+v, err = %v]", v, err)
+	}
+
+	return data
+}
+
+func (k *Kit) callNames() string {
+	var names []string
+	for _, call := range k.Calls() {
+		names = append(names, call.Target+"->"+call.FuncName)
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
+
+func findArgs[T any](k *Kit, funcName string) []T {
+	var list []T
+	for _, call := range k.Find("", funcName) {
+		if arg, ok := call.Arg.(T); ok {
+			list = append(list, arg)
+		}
+	}
+	return list
+}
+
+func match(pattern, value string) bool {
+	return pattern == "" || pattern == value
+}
+
+// isInternalFunc 与cherryActor一致,_xxx_格式的函数为actor内部使用
+func isInternalFunc(funcName string) bool {
+	return len(funcName) > 1 && strings.HasPrefix(funcName, "_") && strings.HasSuffix(funcName, "_")
+}
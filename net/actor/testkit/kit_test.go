@@ -0,0 +1,114 @@
+package cherryTestkit
+
+import (
+	"testing"
+	"time"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cactor "github.com/cherry-game/cherry/net/actor"
+	cproto "github.com/cherry-game/cherry/net/proto"
+)
+
+type (
+	testPlayerActor struct {
+		cactor.PomeloActorBase
+		ticks int32
+	}
+
+	testLogin struct {
+		Token string `json:"token"`
+	}
+
+	testAuth struct {
+		UID  int64  `json:"uid"`
+		Name string `json:"name"`
+	}
+)
+
+func (p *testPlayerActor) OnInit() {
+	p.Local().Register("login", p.login)
+	p.Remote().Register("ticks", p.getTicks)
+	p.Timer().Add(10*time.Second, func() {
+		p.ticks++
+	})
+}
+
+func (p *testPlayerActor) login(session *cproto.Session, req *testLogin) {
+	auth := &testAuth{}
+	if code := p.CallWait("center-1.account", "auth", req, auth); ccode.IsFail(code) {
+		p.ResponseCode(session, code)
+		return
+	}
+
+	p.Response(session, auth)
+	p.Push(session, "onLogin", auth)
+	p.Call("center-1.account", "online", auth.UID)
+
+	p.Timer().AddOnce(time.Minute, func() {
+		p.Kick(session, "timeout", true)
+	})
+}
+
+func (p *testPlayerActor) getTicks() (*int32, int32) {
+	return &p.ticks, ccode.OK
+}
+
+func TestKit(t *testing.T) {
+	kit := New(t, "game-1")
+	kit.Spawn("player", &testPlayerActor{})
+	kit.StubReply("center-1.account", "auth", &testAuth{UID: 1001, Name: "cherry"}, ccode.OK)
+
+	session := kit.NewSession("sid-1", 1001)
+	kit.Local(kit.Path("player"), "login", session, &testLogin{Token: "t"})
+
+	auth := &testAuth{}
+	kit.ExpectResponse(auth)
+	if auth.UID != 1001 || auth.Name != "cherry" {
+		t.Fatalf("response = %+v", auth)
+	}
+
+	kit.ExpectPush("onLogin", nil)
+
+	req := &testLogin{}
+	kit.ExpectCall("center-1.account", "auth").Decode(req)
+	if req.Token != "t" {
+		t.Fatalf("auth arg = %+v", req)
+	}
+
+	var uid int64
+	kit.ExpectCall("center-1.account", "online").Decode(&uid)
+	if uid != 1001 {
+		t.Fatalf("online uid = %d", uid)
+	}
+
+	// 虚拟时钟驱动定时器
+	kit.Advance(59 * time.Second)
+	if len(kit.Kicks()) != 0 {
+		t.Fatal("kick before timeout")
+	}
+
+	var ticks int32
+	if code := kit.Ask(kit.Path("player"), "ticks", nil, &ticks); code != ccode.OK || ticks != 5 {
+		t.Fatalf("ticks = %d, code = %d", ticks, code)
+	}
+
+	kit.Advance(time.Second)
+	kit.ExpectKick()
+
+	if kit.Ask(kit.Path("player"), "ticks", nil, &ticks); ticks != 6 {
+		t.Fatalf("ticks = %d", ticks)
+	}
+}
+
+func TestKitNotStubbed(t *testing.T) {
+	kit := New(t)
+	kit.Spawn("player", &testPlayerActor{})
+
+	kit.Local(kit.Path("player"), "login", kit.NewSession("sid-1", 1), &testLogin{})
+
+	if rsp := kit.ExpectResponse(nil); rsp.Code != NotStubbedCode {
+		t.Fatalf("response code = %d", rsp.Code)
+	}
+
+	kit.ExpectNoCall("", "online")
+}
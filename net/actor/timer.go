@@ -7,9 +7,42 @@ import (
 )
 
 var (
-	globalTimer = ctimeWheel.NewTimeWheel(10*time.Millisecond, 3600)
+	globalTimer     = ctimeWheel.NewTimeWheel(10*time.Millisecond, 3600)
+	globalScheduler = &timeWheelScheduler{wheel: globalTimer}
+)
+
+type (
+	// ITimerScheduler actor定时器的调度器,默认使用全局时间轮
+	// 定时器到期后fn会投递消息到actor,由actor串行执行定时器函数
+	ITimerScheduler interface {
+		AfterFunc(id uint64, delay time.Duration, fn func(), async ...bool) ITimerTask
+		ScheduleFunc(id uint64, s ITimerSchedule, fn func(), async ...bool) ITimerTask
+	}
+
+	// ITimerTask 调度中的定时任务
+	ITimerTask interface {
+		Stop() bool
+	}
+
+	timeWheelScheduler struct {
+		wheel *ctimeWheel.TimeWheel
+	}
 )
 
 func init() {
 	globalTimer.Start()
 }
+
+func (p *timeWheelScheduler) AfterFunc(id uint64, delay time.Duration, fn func(), async ...bool) ITimerTask {
+	if timer := p.wheel.AfterFunc(id, delay, fn, async...); timer != nil {
+		return timer
+	}
+	return nil
+}
+
+func (p *timeWheelScheduler) ScheduleFunc(id uint64, s ITimerSchedule, fn func(), async ...bool) ITimerTask {
+	if timer := p.wheel.ScheduleFunc(id, s, fn, async...); timer != nil {
+		return timer
+	}
+	return nil
+}
@@ -1,8 +1,16 @@
 package cherryCluster
 
 import (
+	"time"
+
 	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+	cherryLoopbackCluster "github.com/cherry-game/cherry/net/cluster/loopback_cluster"
 	cherryNatsCluster "github.com/cherry-game/cherry/net/cluster/nats_cluster"
+	cherryPolicy "github.com/cherry-game/cherry/net/cluster/policy"
+	cherryTCPCluster "github.com/cherry-game/cherry/net/cluster/tcp_cluster"
+	cproto "github.com/cherry-game/cherry/net/proto"
+	cprofile "github.com/cherry-game/cherry/profile"
 )
 
 const (
@@ -12,6 +20,7 @@ const (
 type Component struct {
 	cfacade.Component
 	cfacade.ICluster
+	policy *cherryPolicy.Cluster
 }
 
 func New() *Component {
@@ -23,7 +32,11 @@ func (c *Component) Name() string {
 }
 
 func (c *Component) Init() {
-	c.ICluster = c.loadCluster()
+	// 按profile中cluster->policy配置的策略执行远程调用
+	c.policy = cherryPolicy.New(c.App(), c.loadCluster())
+	c.policy.LoadConfig(cprofile.GetConfig("cluster").GetConfig("policy"))
+
+	c.ICluster = c.policy
 	c.ICluster.Init()
 }
 
@@ -31,6 +44,38 @@ func (c *Component) OnStop() {
 	c.ICluster.Stop()
 }
 
+// Policy 集群的调用策略,可通过SetPolicy()设置目标节点类型的默认策略
+func (c *Component) Policy() *cherryPolicy.Cluster {
+	return c.policy
+}
+
+func (c *Component) PublishRemoteWithPolicy(policies cfacade.Policies, nodeId string, packet *cproto.ClusterPacket) error {
+	return c.policy.PublishRemoteWithPolicy(policies, nodeId, packet)
+}
+
+func (c *Component) RequestRemoteWithPolicy(policies cfacade.Policies, nodeId string, packet *cproto.ClusterPacket, timeout ...time.Duration) cproto.Response {
+	return c.policy.RequestRemoteWithPolicy(policies, nodeId, packet, timeout...)
+}
+
+// Available 节点是否可用,集群初始化前均视为可用
+func (c *Component) Available(nodeId string) bool {
+	if c.policy == nil {
+		return true
+	}
+	return c.policy.Available(nodeId)
+}
+
+// loadCluster 根据profile中cluster->mode创建集群,默认为nats
 func (c *Component) loadCluster() cfacade.ICluster {
-	return cherryNatsCluster.New(c.App())
+	mode := cprofile.GetConfig("cluster").GetString("mode", "nats")
+	clog.Infof("Select cluster [mode = %s].", mode)
+
+	switch mode {
+	case cherryTCPCluster.Mode:
+		return cherryTCPCluster.New(c.App())
+	case cherryLoopbackCluster.Mode:
+		return cherryLoopbackCluster.New(c.App(), cherryLoopbackCluster.DefaultHub())
+	default:
+		return cherryNatsCluster.New(c.App())
+	}
 }
@@ -0,0 +1,207 @@
+package cherryLoopbackCluster
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cerr "github.com/cherry-game/cherry/error"
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+	cproto "github.com/cherry-game/cherry/net/proto"
+)
+
+const (
+	Mode = "loopback" // profile中cluster->mode及discovery->mode的值
+)
+
+const (
+	defaultRequestTimeout = 3 * time.Second
+)
+
+type (
+	// Cluster 进程内的集群,通过Hub在同一进程的多个application之间传递消息
+	// ClusterPacket经过序列化后投递,与跨进程通信的行为一致
+	Cluster struct {
+		hub *Hub
+		app cfacade.IApplication
+	}
+
+	// reply 返回请求结果(实现cfacade.IRespond)
+	reply struct {
+		hub      *Hub
+		nodeID   string // 处理请求的节点
+		callerID string // 发起请求的节点
+		ch       chan []byte
+	}
+)
+
+// New 创建进程内的集群,hub为nil时使用DefaultHub()
+func New(app cfacade.IApplication, hub *Hub) *Cluster {
+	if hub == nil {
+		hub = defaultHub
+	}
+
+	return &Cluster{
+		hub: hub,
+		app: app,
+	}
+}
+
+func (p *Cluster) Init() {
+	p.hub.register(p)
+	clog.Info("loopback cluster execute OnInit().")
+}
+
+func (p *Cluster) Stop() {
+	p.hub.unregister(p)
+	clog.Info("loopback cluster execute OnStop().")
+}
+
+func (p *Cluster) PublishLocal(nodeId string, packet *cproto.ClusterPacket) error {
+	defer packet.Recycle()
+	return p.publish(nodeId, packet, true)
+}
+
+func (p *Cluster) PublishRemote(nodeId string, packet *cproto.ClusterPacket) error {
+	defer packet.Recycle()
+	return p.publish(nodeId, packet, false)
+}
+
+func (p *Cluster) RequestRemote(nodeId string, packet *cproto.ClusterPacket, timeout ...time.Duration) cproto.Response {
+	defer packet.Recycle()
+
+	nodeId, found := p.hub.resolve(p.app.NodeId(), nodeId)
+	if !found {
+		return cproto.Response{Code: ccode.DiscoveryNotFoundNode}
+	}
+
+	bytes, err := proto.Marshal(packet)
+	if err != nil {
+		return cproto.Response{Code: ccode.RPCMarshalError}
+	}
+
+	r := &reply{
+		hub:      p.hub,
+		nodeID:   nodeId,
+		callerID: p.app.NodeId(),
+		ch:       make(chan []byte, 1),
+	}
+
+	p.deliver(nodeId, bytes, false, r)
+
+	requestTimeout := defaultRequestTimeout
+	if len(timeout) > 0 && timeout[0] > 0 {
+		requestTimeout = timeout[0]
+	}
+
+	timer := time.NewTimer(requestTimeout)
+	defer timer.Stop()
+
+	select {
+	case data := <-r.ch:
+		rsp := &cproto.Response{}
+		if err = proto.Unmarshal(data, rsp); err != nil {
+			return cproto.Response{Code: ccode.RPCUnmarshalError}
+		}
+		return cproto.Response{Code: rsp.Code, Data: rsp.Data}
+	case <-timer.C:
+		clog.Warnf("[RequestRemote] Request timeout. [nodeId = %s, %s, timeout = %s]",
+			nodeId,
+			packet.PrintLog(),
+			requestTimeout,
+		)
+		return cproto.Response{Code: ccode.RPCNetError}
+	}
+}
+
+func (p *Cluster) publish(nodeId string, packet *cproto.ClusterPacket, isLocal bool) error {
+	if !p.app.Running() {
+		return cerr.ClusterRPCClientIsStop
+	}
+
+	nodeId, found := p.hub.resolve(p.app.NodeId(), nodeId)
+	if !found {
+		return cerr.Errorf("nodeId = %s not found.", nodeId)
+	}
+
+	bytes, err := proto.Marshal(packet)
+	if err != nil {
+		return err
+	}
+
+	p.deliver(nodeId, bytes, isLocal, nil)
+	return nil
+}
+
+// deliver 投递到目标节点的actor system,被丢弃的消息不返回错误(与网络丢包一致)
+func (p *Cluster) deliver(nodeId string, bytes []byte, isLocal bool, r *reply) {
+	target, latency, ok := p.hub.route(p.app.NodeId(), nodeId)
+	if !ok {
+		return
+	}
+
+	after(latency, func() {
+		target.receive(bytes, isLocal, r)
+	})
+}
+
+// receive 接收其他节点发送的消息
+func (p *Cluster) receive(bytes []byte, isLocal bool, r *reply) {
+	packet := cproto.GetClusterPacket()
+	defer packet.Recycle()
+
+	if err := proto.Unmarshal(bytes, packet); err != nil {
+		clog.Warnf("[receive] Unmarshal fail. [err = %v]", err)
+		return
+	}
+
+	message := cfacade.GetMessage()
+	message.BuildTime = packet.BuildTime
+	message.Source = packet.SourcePath
+	message.Target = packet.TargetPath
+	message.FuncName = packet.FuncName
+	message.IsCluster = true
+	message.TraceParent = packet.TraceParent
+	message.MessageID = packet.MessageId
+	if packet.ArgBytes != nil {
+		message.Args = packet.ArgBytes
+	}
+
+	if isLocal {
+		message.Session = packet.Session
+		p.app.ActorSystem().PostLocal(&message)
+		return
+	}
+
+	if r != nil {
+		message.ClusterReply = r
+	}
+
+	p.app.ActorSystem().PostRemote(&message)
+}
+
+func (p *reply) Respond(data []byte) error {
+	_, latency, ok := p.hub.route(p.nodeID, p.callerID)
+	if !ok {
+		return nil
+	}
+
+	after(latency, func() {
+		select {
+		case p.ch <- data:
+		default:
+		}
+	})
+
+	return nil
+}
+
+func after(latency time.Duration, fn func()) {
+	if latency > 0 {
+		time.AfterFunc(latency, fn)
+		return
+	}
+	fn()
+}
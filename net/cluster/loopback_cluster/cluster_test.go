@@ -0,0 +1,173 @@
+package cherryLoopbackCluster
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cfacade "github.com/cherry-game/cherry/facade"
+	cactor "github.com/cherry-game/cherry/net/actor"
+	cserializer "github.com/cherry-game/cherry/net/serializer"
+)
+
+type (
+	testApp struct {
+		cfacade.IApplication
+		nodeID    string
+		nodeType  string
+		system    *cactor.System
+		cluster   *Cluster
+		discovery *Discovery
+	}
+
+	testCounterActor struct {
+		cactor.Base
+		count int32
+	}
+)
+
+func (p *testApp) NodeId() string                    { return p.nodeID }
+func (p *testApp) NodeType() string                  { return p.nodeType }
+func (p *testApp) RpcAddress() string                { return "" }
+func (p *testApp) Running() bool                     { return true }
+func (p *testApp) Serializer() cfacade.ISerializer   { return cserializer.NewJSON() }
+func (p *testApp) Cluster() cfacade.ICluster         { return p.cluster }
+func (p *testApp) Discovery() cfacade.IDiscovery     { return p.discovery }
+func (p *testApp) ActorSystem() cfacade.IActorSystem { return p.system }
+
+func (p *testCounterActor) OnInit() {
+	p.Remote().Register("add", p.add)
+	p.Remote().Register("count", p.getCount)
+}
+
+func (p *testCounterActor) add() {
+	p.count++
+}
+
+func (p *testCounterActor) getCount() (*int32, int32) {
+	return &p.count, ccode.OK
+}
+
+func newTestApp(t *testing.T, hub *Hub, nodeID string) *testApp {
+	app := &testApp{nodeID: nodeID, nodeType: strings.Split(nodeID, "-")[0]}
+	app.cluster = New(app, hub)
+	app.discovery = NewDiscovery(hub)
+	app.system = cactor.NewSystem()
+	app.system.SetApp(app)
+	app.system.SetCallTimeout(200 * time.Millisecond)
+
+	app.cluster.Init()
+	app.discovery.Load(app)
+
+	t.Cleanup(func() {
+		app.system.Stop()
+		app.discovery.Stop()
+		app.cluster.Stop()
+	})
+
+	return app
+}
+
+func TestLoopbackCall(t *testing.T) {
+	hub := NewHub()
+	game1 := newTestApp(t, hub, "game-1")
+	game2 := newTestApp(t, hub, "game-2")
+
+	if _, err := game2.system.CreateActor("counter", &testCounterActor{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if members := game1.discovery.ListByType("game"); len(members) != 2 {
+		t.Fatalf("members = %v", members)
+	}
+
+	if code := game1.system.Call("game-1.tester", "game-2.counter", "add", nil); code != ccode.OK {
+		t.Fatalf("call code = %d", code)
+	}
+
+	var count int32
+	if code := game1.system.CallWait("game-1.tester", "game-2.counter", "count", nil, &count); code != ccode.OK || count != 1 {
+		t.Fatalf("code = %d, count = %d", code, count)
+	}
+}
+
+func TestLoopbackPartition(t *testing.T) {
+	hub := NewHub()
+	game1 := newTestApp(t, hub, "game-1")
+	game2 := newTestApp(t, hub, "game-2")
+
+	if _, err := game2.system.CreateActor("counter", &testCounterActor{}); err != nil {
+		t.Fatal(err)
+	}
+
+	hub.Partition([]string{"game-1"}, []string{"game-2"})
+
+	var count int32
+	if code := game1.system.CallWait("game-1.tester", "game-2.counter", "count", nil, &count); code != ccode.RPCNetError {
+		t.Fatalf("partition code = %d", code)
+	}
+
+	hub.Heal()
+	hub.SetLatency(10 * time.Millisecond)
+
+	if code := game1.system.CallWait("game-1.tester", "game-2.counter", "count", nil, &count); code != ccode.OK {
+		t.Fatalf("heal code = %d", code)
+	}
+}
+
+func TestLoopbackMember(t *testing.T) {
+	hub := NewHub()
+	game1 := newTestApp(t, hub, "game-1")
+
+	removed := make(chan string, 1)
+	game1.discovery.OnRemoveMember(func(member cfacade.IMember) {
+		removed <- member.GetNodeId()
+	})
+
+	game2 := newTestApp(t, hub, "game-2")
+	if _, found := game1.discovery.GetMember("game-2"); !found {
+		t.Fatal("game-2 not found")
+	}
+
+	game2.discovery.Stop()
+
+	if nodeID := <-removed; nodeID != "game-2" {
+		t.Fatalf("removed = %s", nodeID)
+	}
+}
+
+func TestLoopbackNodeType(t *testing.T) {
+	hub := NewHub()
+	game := newTestApp(t, hub, "game-1")
+
+	var counters []*testCounterActor
+	for _, nodeID := range []string{"web-1", "web-2"} {
+		app := newTestApp(t, hub, nodeID)
+
+		counter := &testCounterActor{}
+		if _, err := app.system.CreateActor("counter", counter); err != nil {
+			t.Fatal(err)
+		}
+		counters = append(counters, counter)
+	}
+
+	// web-2不可连通,按类型寻址的消息只投递到web-1
+	hub.Partition([]string{"game-1", "web-1"}, []string{"web-2"})
+
+	target := cfacade.NewTypePath("web", "counter")
+	for i := 0; i < 10; i++ {
+		if code := game.system.Call("game-1.tester", target, "add", nil); code != ccode.OK {
+			t.Fatalf("call code = %d", code)
+		}
+	}
+
+	var count int32
+	if code := game.system.CallWait("game-1.tester", target, "count", nil, &count); code != ccode.OK || count != 10 {
+		t.Fatalf("code = %d, count = %d", code, count)
+	}
+
+	if counters[1].count != 0 {
+		t.Fatalf("web-2 count = %d", counters[1].count)
+	}
+}
@@ -0,0 +1,163 @@
+package cherryLoopbackCluster
+
+import (
+	"math/rand"
+	"sync"
+
+	cerr "github.com/cherry-game/cherry/error"
+	cslice "github.com/cherry-game/cherry/extend/slice"
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+	cherryDiscovery "github.com/cherry-game/cherry/net/discovery"
+	cproto "github.com/cherry-game/cherry/net/proto"
+)
+
+type (
+	// Discovery 进程内的发现服务,每个application一个实例,成员信息由Hub共享
+	Discovery struct {
+		sync.RWMutex
+		hub              *Hub
+		app              cfacade.IApplication
+		onAddListener    []cfacade.MemberListener
+		onRemoveListener []cfacade.MemberListener
+	}
+)
+
+func init() {
+	// profile中discovery->mode为loopback时使用DefaultHub()
+	cherryDiscovery.Register(NewDiscovery(nil))
+}
+
+// NewDiscovery 创建进程内的发现服务,hub为nil时使用DefaultHub()
+func NewDiscovery(hub *Hub) *Discovery {
+	if hub == nil {
+		hub = defaultHub
+	}
+
+	return &Discovery{
+		hub: hub,
+	}
+}
+
+// Load 注册当前节点
+func (p *Discovery) Load(app cfacade.IApplication) {
+	p.app = app
+	p.hub.addDiscovery(p)
+
+	p.hub.addMember(&cproto.Member{
+		NodeId:   app.NodeId(),
+		NodeType: app.NodeType(),
+		Address:  app.RpcAddress(),
+		Settings: make(map[string]string),
+	})
+
+	clog.Infof("[discovery = %s] is running.", p.Name())
+}
+
+// NewDiscovery 每个application使用同一个hub的新实例
+func (p *Discovery) NewDiscovery() cfacade.IDiscovery {
+	return NewDiscovery(p.hub)
+}
+
+func (p *Discovery) Name() string {
+	return Mode
+}
+
+func (p *Discovery) Map() map[string]cfacade.IMember {
+	memberMap := make(map[string]cfacade.IMember)
+	for _, member := range p.hub.memberList() {
+		memberMap[member.GetNodeId()] = member
+	}
+	return memberMap
+}
+
+func (p *Discovery) ListByType(nodeType string, filterNodeId ...string) []cfacade.IMember {
+	var memberList []cfacade.IMember
+
+	for _, member := range p.hub.memberList() {
+		if member.GetNodeType() != nodeType {
+			continue
+		}
+
+		if _, ok := cslice.StringIn(member.GetNodeId(), filterNodeId); !ok {
+			memberList = append(memberList, member)
+		}
+	}
+
+	return memberList
+}
+
+func (p *Discovery) Random(nodeType string) (cfacade.IMember, bool) {
+	memberList := p.ListByType(nodeType)
+	if len(memberList) < 1 {
+		return nil, false
+	}
+
+	return memberList[rand.Intn(len(memberList))], true
+}
+
+func (p *Discovery) GetType(nodeId string) (nodeType string, err error) {
+	member, found := p.GetMember(nodeId)
+	if !found {
+		return "", cerr.Errorf("nodeId = %s not found.", nodeId)
+	}
+	return member.GetNodeType(), nil
+}
+
+func (p *Discovery) GetMember(nodeId string) (cfacade.IMember, bool) {
+	if nodeId == "" {
+		return nil, false
+	}
+	return p.hub.getMember(nodeId)
+}
+
+func (p *Discovery) AddMember(member cfacade.IMember) {
+	p.hub.addMember(member)
+}
+
+func (p *Discovery) RemoveMember(nodeId string) {
+	p.hub.removeMember(nodeId)
+}
+
+func (p *Discovery) OnAddMember(listener cfacade.MemberListener) {
+	if listener == nil {
+		return
+	}
+
+	p.Lock()
+	p.onAddListener = append(p.onAddListener, listener)
+	p.Unlock()
+}
+
+func (p *Discovery) OnRemoveMember(listener cfacade.MemberListener) {
+	if listener == nil {
+		return
+	}
+
+	p.Lock()
+	p.onRemoveListener = append(p.onRemoveListener, listener)
+	p.Unlock()
+}
+
+// Stop 注销当前节点
+func (p *Discovery) Stop() {
+	p.hub.removeDiscovery(p)
+
+	if p.app != nil {
+		p.hub.removeMember(p.app.NodeId())
+	}
+}
+
+// notify 成员变化时执行监听函数
+func (p *Discovery) notify(member cfacade.IMember, isAdd bool) {
+	p.RLock()
+	listeners := p.onRemoveListener
+	if isAdd {
+		listeners = p.onAddListener
+	}
+	p.RUnlock()
+
+	for _, listener := range listeners {
+		listener(member)
+	}
+}
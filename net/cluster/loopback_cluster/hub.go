@@ -0,0 +1,226 @@
+package cherryLoopbackCluster
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+)
+
+var (
+	defaultHub = NewHub()
+)
+
+type (
+	// Hub 进程内的集群中心,在同一进程的多个application之间路由ClusterPacket并同步成员信息
+	// 可模拟网络延迟、丢包及网络分区,用于集成测试及本地开发
+	Hub struct {
+		sync.RWMutex
+		clusters    map[string]*Cluster        // key:nodeID, 已注册的集群
+		members     map[string]cfacade.IMember // key:nodeID, 已注册的成员
+		discoveries []*Discovery               // 成员变化时通知
+		latency     time.Duration              // 消息延迟
+		dropRate    float64                    // 丢包率[0,1]
+		partitions  map[string]int             // key:nodeID, value:分区编号
+		rand        *rand.Rand
+	}
+)
+
+// NewHub 创建集群中心
+func NewHub() *Hub {
+	return &Hub{
+		clusters:   make(map[string]*Cluster),
+		members:    make(map[string]cfacade.IMember),
+		partitions: make(map[string]int),
+		rand:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// DefaultHub 默认的集群中心,profile中使用loopback模式时使用
+func DefaultHub() *Hub {
+	return defaultHub
+}
+
+// SetLatency 设置消息延迟
+func (p *Hub) SetLatency(latency time.Duration) {
+	p.Lock()
+	defer p.Unlock()
+
+	if latency >= 0 {
+		p.latency = latency
+	}
+}
+
+// SetDropRate 设置丢包率[0,1],被丢弃的请求等待超时
+func (p *Hub) SetDropRate(rate float64) {
+	p.Lock()
+	defer p.Unlock()
+
+	if rate >= 0 && rate <= 1 {
+		p.dropRate = rate
+	}
+}
+
+// Partition 按分组划分网络,不同分组的节点之间的消息被丢弃,未指定的节点属于分组0
+func (p *Hub) Partition(groups ...[]string) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.partitions = make(map[string]int)
+	for i, group := range groups {
+		for _, nodeID := range group {
+			p.partitions[nodeID] = i + 1
+		}
+	}
+}
+
+// Heal 恢复网络分区
+func (p *Hub) Heal() {
+	p.Partition()
+}
+
+// register 注册集群
+func (p *Hub) register(cluster *Cluster) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.clusters[cluster.app.NodeId()] = cluster
+}
+
+func (p *Hub) unregister(cluster *Cluster) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.clusters[cluster.app.NodeId()] == cluster {
+		delete(p.clusters, cluster.app.NodeId())
+	}
+}
+
+// route 获取目标集群及消息延迟,消息被丢弃时返回false
+func (p *Hub) route(sourceID, targetID string) (*Cluster, time.Duration, bool) {
+	p.Lock()
+	defer p.Unlock()
+
+	target, found := p.clusters[targetID]
+	if !found {
+		return nil, 0, false
+	}
+
+	if p.partitions[sourceID] != p.partitions[targetID] {
+		return nil, 0, false
+	}
+
+	if p.dropRate > 0 && p.rand.Float64() < p.dropRate {
+		return nil, 0, false
+	}
+
+	return target, p.latency, true
+}
+
+// addMember 添加成员并通知所有发现服务
+func (p *Hub) addMember(member cfacade.IMember) {
+	p.Lock()
+	if _, found := p.members[member.GetNodeId()]; found {
+		p.Unlock()
+		clog.Warnf("[loopback] Duplicate nodeId. [nodeId = %s]", member.GetNodeId())
+		return
+	}
+
+	p.members[member.GetNodeId()] = member
+	discoveries := p.discoveries
+	p.Unlock()
+
+	for _, discovery := range discoveries {
+		discovery.notify(member, true)
+	}
+}
+
+// removeMember 移除成员并通知所有发现服务
+func (p *Hub) removeMember(nodeID string) {
+	p.Lock()
+	member, found := p.members[nodeID]
+	delete(p.members, nodeID)
+	discoveries := p.discoveries
+	p.Unlock()
+
+	if !found {
+		return
+	}
+
+	for _, discovery := range discoveries {
+		discovery.notify(member, false)
+	}
+}
+
+func (p *Hub) getMember(nodeID string) (cfacade.IMember, bool) {
+	p.RLock()
+	defer p.RUnlock()
+
+	member, found := p.members[nodeID]
+	return member, found
+}
+
+// resolve 获取目标节点id,nodeID为 @nodeType 时在该类型可连通的节点中随机选择一个(与nats队列组一致)
+func (p *Hub) resolve(sourceID, nodeID string) (string, bool) {
+	nodeType, ok := cfacade.NodeTypeOf(nodeID)
+	if !ok {
+		_, found := p.getMember(nodeID)
+		return nodeID, found
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	var list []string
+	for _, member := range p.members {
+		if member.GetNodeType() != nodeType {
+			continue
+		}
+
+		if _, found := p.clusters[member.GetNodeId()]; !found {
+			continue
+		}
+
+		if p.partitions[sourceID] == p.partitions[member.GetNodeId()] {
+			list = append(list, member.GetNodeId())
+		}
+	}
+
+	if len(list) < 1 {
+		return nodeID, false
+	}
+
+	return list[p.rand.Intn(len(list))], true
+}
+
+func (p *Hub) memberList() []cfacade.IMember {
+	p.RLock()
+	defer p.RUnlock()
+
+	list := make([]cfacade.IMember, 0, len(p.members))
+	for _, member := range p.members {
+		list = append(list, member)
+	}
+	return list
+}
+
+func (p *Hub) addDiscovery(discovery *Discovery) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.discoveries = append(p.discoveries, discovery)
+}
+
+func (p *Hub) removeDiscovery(discovery *Discovery) {
+	p.Lock()
+	defer p.Unlock()
+
+	for i, item := range p.discoveries {
+		if item == discovery {
+			p.discoveries = append(p.discoveries[:i:i], p.discoveries[i+1:]...)
+			return
+		}
+	}
+}
@@ -23,16 +23,16 @@ type (
 		prefix     string
 		local      *natsSubject
 		remote     *natsSubject
+		queue      *natsSubject // 同类型节点共享的队列组,按节点类型寻址(@nodeType)的消息只投递到其中一个节点
+		conn       *cnats.Conn  // nats连接
+		reliable   *reliable    // 可靠投递(JetStream)
 	}
 
 	OptionFunc func(o *Cluster)
 )
 
 func New(app cfacade.IApplication, options ...OptionFunc) cfacade.ICluster {
-	cluster := &Cluster{
-		app:        app,
-		bufferSize: 1024,
-	}
+	cluster := newCluster(app)
 
 	for _, option := range options {
 		option(cluster)
@@ -43,29 +43,52 @@ func New(app cfacade.IApplication, options ...OptionFunc) cfacade.ICluster {
 	return cluster
 }
 
+func newCluster(app cfacade.IApplication) *Cluster {
+	return &Cluster{
+		app:        app,
+		bufferSize: 1024,
+		reliable:   newReliable(),
+	}
+}
+
 func (p *Cluster) loadConfig() {
 	natsConfig := cprofile.GetConfig("cluster").GetConfig("nats")
 	if natsConfig.LastError() != nil {
 		panic("cluster->nats config not found.")
 	}
 
-	natsConn := cnats.NewFromConfig(natsConfig)
-	cnats.SetInstance(natsConn)
+	p.conn = cnats.NewFromConfig(natsConfig)
+	cnats.SetInstance(p.conn)
 
 	p.prefix = natsConfig.GetString("prefix", "node")
+	p.reliable.loadConfig(natsConfig.GetConfig("reliable"))
+	p.initSubjects()
+}
 
+func (p *Cluster) initSubjects() {
 	localSubject := getLocalSubject(p.prefix, p.app.NodeType(), p.app.NodeId())
 	p.local = newNatsSubject(localSubject, p.bufferSize)
 
 	remoteSubject := getRemoteSubject(p.prefix, p.app.NodeType(), p.app.NodeId())
 	p.remote = newNatsSubject(remoteSubject, p.bufferSize)
+
+	queueSubject := getQueueSubject(p.prefix, p.app.NodeType())
+	p.queue = newNatsSubject(queueSubject, p.bufferSize)
+	p.queue.group = p.app.NodeType()
 }
 
 func (p *Cluster) Init() {
-	cnats.Get().Connect()
+	p.conn.Connect()
+
+	p.subscribe(p.local)
+	p.subscribe(p.remote)
+	p.subscribe(p.queue)
 
 	go p.localProcess()
-	go p.remoteProcess()
+	go p.remoteProcess(p.remote)
+	go p.remoteProcess(p.queue)
+
+	p.reliableInit()
 
 	clog.Info("nats cluster execute OnInit().")
 }
@@ -73,20 +96,29 @@ func (p *Cluster) Init() {
 func (p *Cluster) Stop() {
 	p.local.stop()
 	p.remote.stop()
+	p.queue.stop()
+	p.reliableStop()
 
-	cnats.Get().Close()
+	p.conn.Close()
 
 	clog.Info("nats cluster execute OnStop().")
 }
 
-func (p *Cluster) localProcess() {
+// subscribe 订阅subject,消息写入subject.ch
+func (p *Cluster) subscribe(subject *natsSubject) {
 	var err error
-	p.local.subscription, err = cnats.Get().ChanSubscribe(p.local.subject, p.local.ch)
+	if subject.group != "" {
+		subject.subscription, err = p.conn.ChanQueueSubscribe(subject.subject, subject.group, subject.ch)
+	} else {
+		subject.subscription, err = p.conn.ChanSubscribe(subject.subject, subject.ch)
+	}
+
 	if err != nil {
-		clog.Errorf("[localProcess] Subscribe fail. [subject = %s, err = %s]", p.local.subject, err)
-		return
+		clog.Errorf("[subscribe] Subscribe fail. [subject = %s, err = %v]", subject.subject, err)
 	}
+}
 
+func (p *Cluster) localProcess() {
 	process := func(natsMsg *nats.Msg) {
 		if dropped, err := p.local.subscription.Dropped(); err != nil {
 			clog.Errorf("[localProcess] Dropped messages. [subject = %s, dropped = %d, err = %v]",
@@ -99,7 +131,7 @@ func (p *Cluster) localProcess() {
 		packet := cproto.GetClusterPacket()
 		defer packet.Recycle()
 
-		err = proto.Unmarshal(natsMsg.Data, packet)
+		err := proto.Unmarshal(natsMsg.Data, packet)
 		if err != nil {
 			clog.Warnf("[localProcess] Unmarshal fail. [subject = %s, %s, err = %s]",
 				natsMsg.Subject,
@@ -117,6 +149,8 @@ func (p *Cluster) localProcess() {
 		message.IsCluster = true
 		message.Session = packet.Session
 		message.Args = packet.ArgBytes
+		message.TraceParent = packet.TraceParent
+		message.MessageID = packet.MessageId
 
 		p.app.ActorSystem().PostLocal(&message)
 	}
@@ -126,18 +160,11 @@ func (p *Cluster) localProcess() {
 	}
 }
 
-func (p *Cluster) remoteProcess() {
-	var err error
-	p.remote.subscription, err = cnats.Get().ChanSubscribe(p.remote.subject, p.remote.ch)
-	if err != nil {
-		clog.Errorf("[remoteProcess] Subscribe fail. [subject = %s, err = %s]", p.remote.subject, err)
-		return
-	}
-
+func (p *Cluster) remoteProcess(remote *natsSubject) {
 	process := func(natsMsg *nats.Msg) {
-		if dropped, err := p.remote.subscription.Dropped(); err != nil {
+		if dropped, err := remote.subscription.Dropped(); err != nil {
 			clog.Errorf("[remoteProcess] Dropped messages. [subject = %s, dropped = %d, err = %v]",
-				p.remote.subject,
+				remote.subject,
 				dropped,
 				err,
 			)
@@ -146,7 +173,7 @@ func (p *Cluster) remoteProcess() {
 		packet := cproto.GetClusterPacket()
 		defer packet.Recycle()
 
-		err = proto.Unmarshal(natsMsg.Data, packet)
+		err := proto.Unmarshal(natsMsg.Data, packet)
 		if err != nil {
 			clog.Warnf("[remoteProcess] Unmarshal fail. [subject = %s, %s, err = %v]",
 				natsMsg.Subject,
@@ -156,16 +183,7 @@ func (p *Cluster) remoteProcess() {
 			return
 		}
 
-		message := cfacade.GetMessage()
-		message.BuildTime = packet.BuildTime
-		message.Source = packet.SourcePath
-		message.Target = packet.TargetPath
-		message.FuncName = packet.FuncName
-		if packet.ArgBytes != nil {
-			message.Args = packet.ArgBytes
-		}
-
-		message.IsCluster = true
+		message := buildRemoteMessage(packet)
 		if len(natsMsg.Reply) > 0 {
 			message.ClusterReply = natsMsg
 		}
@@ -173,7 +191,7 @@ func (p *Cluster) remoteProcess() {
 		p.app.ActorSystem().PostRemote(&message)
 	}
 
-	for msg := range p.remote.ch {
+	for msg := range remote.ch {
 		process(msg)
 	}
 }
@@ -211,7 +229,11 @@ func (p *Cluster) PublishLocal(nodeId string, request *cproto.ClusterPacket) err
 func (p *Cluster) PublishRemote(nodeId string, request *cproto.ClusterPacket) error {
 	defer request.Recycle()
 
-	nodeType, err := p.app.Discovery().GetType(nodeId)
+	if p.isReliable(request) {
+		return p.publishReliable(nodeId, request)
+	}
+
+	subject, err := p.remoteSubject(nodeId)
 	if err != nil {
 		clog.Debugf("[PublishRemote] Get node type fail. [nodeId = %s, %s, err = %v]",
 			nodeId,
@@ -221,7 +243,6 @@ func (p *Cluster) PublishRemote(nodeId string, request *cproto.ClusterPacket) er
 		return err
 	}
 
-	subject := getRemoteSubject(p.prefix, nodeType, nodeId)
 	bytes, err := proto.Marshal(request)
 	if err != nil {
 		clog.Warn(err)
@@ -236,7 +257,7 @@ func (p *Cluster) RequestRemote(nodeId string, request *cproto.ClusterPacket, ti
 	defer request.Recycle()
 
 	rsp := cproto.Response{}
-	nodeType, err := p.app.Discovery().GetType(nodeId)
+	subject, err := p.remoteSubject(nodeId)
 	if err != nil {
 		clog.Debugf("[PublishRemote] Get node type fail. [nodeId = %s, %s, err = %v]",
 			nodeId,
@@ -260,8 +281,7 @@ func (p *Cluster) RequestRemote(nodeId string, request *cproto.ClusterPacket, ti
 		return rsp
 	}
 
-	subject := getRemoteSubject(p.prefix, nodeType, nodeId)
-	natsMsg, err := cnats.Get().Request(subject, msg, timeout...)
+	natsMsg, err := p.conn.Request(subject, msg, timeout...)
 	if err != nil {
 		clog.Warnf("[RequestRemote] nats request fail. [nodeId = %s, %s, err = %v]",
 			nodeId,
@@ -288,12 +308,43 @@ func (p *Cluster) RequestRemote(nodeId string, request *cproto.ClusterPacket, ti
 	return rsp
 }
 
+// remoteSubject 获取远程消息的subject,nodeId为 @nodeType 时投递到该类型的队列组
+func (p *Cluster) remoteSubject(nodeId string) (string, error) {
+	if nodeType, ok := cfacade.NodeTypeOf(nodeId); ok {
+		return getQueueSubject(p.prefix, nodeType), nil
+	}
+
+	nodeType, err := p.app.Discovery().GetType(nodeId)
+	if err != nil {
+		return "", err
+	}
+
+	return getRemoteSubject(p.prefix, nodeType, nodeId), nil
+}
+
+// buildRemoteMessage 将集群包转换为远程消息
+func buildRemoteMessage(packet *cproto.ClusterPacket) cfacade.Message {
+	message := cfacade.GetMessage()
+	message.BuildTime = packet.BuildTime
+	message.Source = packet.SourcePath
+	message.Target = packet.TargetPath
+	message.FuncName = packet.FuncName
+	if packet.ArgBytes != nil {
+		message.Args = packet.ArgBytes
+	}
+
+	message.IsCluster = true
+	message.TraceParent = packet.TraceParent
+	message.MessageID = packet.MessageId
+	return message
+}
+
 func (p *Cluster) Publish(subject string, data []byte) error {
 	if !p.app.Running() {
 		return cerr.ClusterRPCClientIsStop
 	}
 
-	return cnats.Get().Publish(subject, data)
+	return p.conn.Publish(subject, data)
 }
 
 func WithBufferSize(size int) OptionFunc {
@@ -7,6 +7,11 @@ import (
 const (
 	remoteSubjectFormat = "cherry.%s.remote.%s.%s" // nodeType.nodeId
 	localSubjectFormat  = "cherry.%s.local.%s.%s"  // nodeType.nodeId
+	queueSubjectFormat  = "cherry.%s.queue.%s"     // nodeType
+
+	reliableNodeSubjectFormat = "cherry.%s.reliable.node.%s" // nodeId
+	reliableTypeSubjectFormat = "cherry.%s.reliable.type.%s" // nodeType
+	reliableStreamSubject     = "cherry.%s.reliable.>"
 )
 
 // getLocalSubject local message nats chan
@@ -14,7 +19,22 @@ func getLocalSubject(prefix, nodeType, nodeId string) string {
 	return fmt.Sprintf(localSubjectFormat, prefix, nodeType, nodeId)
 }
 
+// getQueueSubject 同类型节点共享的队列组 nats chan
+func getQueueSubject(prefix, nodeType string) string {
+	return fmt.Sprintf(queueSubjectFormat, prefix, nodeType)
+}
+
 // getRemoteSubject remote message nats chan
 func getRemoteSubject(prefix, nodeType, nodeId string) string {
 	return fmt.Sprintf(remoteSubjectFormat, prefix, nodeType, nodeId)
 }
+
+// getReliableNodeSubject 可靠投递到指定节点的subject(不依赖发现服务,节点离线时消息保存在stream中)
+func getReliableNodeSubject(prefix, nodeId string) string {
+	return fmt.Sprintf(reliableNodeSubjectFormat, prefix, nodeId)
+}
+
+// getReliableTypeSubject 可靠投递到同类型任意一个节点的subject
+func getReliableTypeSubject(prefix, nodeType string) string {
+	return fmt.Sprintf(reliableTypeSubjectFormat, prefix, nodeType)
+}
@@ -0,0 +1,436 @@
+package cherryNatsCluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cerr "github.com/cherry-game/cherry/error"
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+	cproto "github.com/cherry-game/cherry/net/proto"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nuid"
+)
+
+const (
+	defaultMaxDeliver      = 10
+	defaultAckWait         = 30 * time.Second
+	defaultNakDelay        = time.Second
+	defaultDuplicateWindow = 2 * time.Minute
+	reliableFetchSize      = 64
+	reliableFetchWait      = time.Second
+)
+
+var (
+	nameReplacer = strings.NewReplacer(".", "_", "*", "_", ">", "_", " ", "_")
+
+	// reliableRetryCodes 函数未执行(actor不在工作状态、邮箱已满、函数不存在等)或执行panic的错误码,收到时重新投递
+	reliableRetryCodes = map[int32]struct{}{
+		ccode.RPCRemoteExecuteError: {},
+		ccode.ActorCallFail:         {},
+		ccode.ActorMailboxFull:      {},
+		ccode.ActorChildIDNotFound:  {},
+		ccode.ActorFuncNameError:    {},
+	}
+)
+
+type (
+	// reliable 可靠投递(至少一次)
+	// 指定函数名或actorID的Call消息通过JetStream持久化,目标节点执行函数后才确认(ack),
+	// 节点重启、执行panic或投递失败时重新投递,重复投递的消息携带相同的幂等键(ClusterPacket.MessageId)
+	reliable struct {
+		enable          bool
+		stream          string              // stream名称,默认为cherry_{prefix}
+		funcs           map[string]struct{} // 可靠投递的函数名
+		actors          map[string]struct{} // 可靠投递的actorID
+		maxDeliver      int                 // 最大投递次数
+		ackWait         time.Duration       // 等待确认的时间,超时后重新投递
+		nakDelay        time.Duration       // 执行失败后重新投递的延迟
+		duplicateWindow time.Duration       // 发布方重复发布相同幂等键时的去重窗口
+		js              nats.JetStreamContext
+		subs            []*nats.Subscription
+		die             chan struct{}
+		wg              sync.WaitGroup
+	}
+
+	// reliableConfig profile中cluster->nats->reliable节点,时间单位为秒
+	reliableConfig struct {
+		Stream          string   `json:"stream"`
+		Funcs           []string `json:"funcs"`
+		Actors          []string `json:"actors"`
+		MaxDeliver      int      `json:"max_deliver"`
+		AckWait         int      `json:"ack_wait"`
+		NakDelay        int      `json:"nak_delay"`
+		DuplicateWindow int      `json:"duplicate_window"`
+	}
+
+	// reliableReply 函数执行完成后确认消息(实现cfacade.IRespond)
+	reliableReply struct {
+		msg        *nats.Msg
+		once       sync.Once
+		maxDeliver int
+		nakDelay   time.Duration
+	}
+)
+
+func newReliable() *reliable {
+	return &reliable{
+		funcs:           make(map[string]struct{}),
+		actors:          make(map[string]struct{}),
+		maxDeliver:      defaultMaxDeliver,
+		ackWait:         defaultAckWait,
+		nakDelay:        defaultNakDelay,
+		duplicateWindow: defaultDuplicateWindow,
+	}
+}
+
+func (p *reliable) loadConfig(config cfacade.ProfileJSON) {
+	if config.LastError() != nil {
+		return
+	}
+
+	cfg := reliableConfig{}
+	if err := config.Unmarshal(&cfg); err != nil {
+		clog.Warnf("[reliable] Unmarshal config fail. [err = %v]", err)
+		return
+	}
+
+	p.enable = true
+	p.addFuncs(cfg.Funcs...)
+	p.addActors(cfg.Actors...)
+
+	if cfg.Stream != "" {
+		p.stream = cfg.Stream
+	}
+
+	if cfg.MaxDeliver > 0 {
+		p.maxDeliver = cfg.MaxDeliver
+	}
+
+	if cfg.AckWait > 0 {
+		p.ackWait = time.Duration(cfg.AckWait) * time.Second
+	}
+
+	if cfg.NakDelay > 0 {
+		p.nakDelay = time.Duration(cfg.NakDelay) * time.Second
+	}
+
+	if cfg.DuplicateWindow > 0 {
+		p.duplicateWindow = time.Duration(cfg.DuplicateWindow) * time.Second
+	}
+}
+
+func (p *reliable) addFuncs(funcNames ...string) {
+	for _, funcName := range funcNames {
+		p.funcs[funcName] = struct{}{}
+	}
+}
+
+func (p *reliable) addActors(actorIDs ...string) {
+	for _, actorID := range actorIDs {
+		p.actors[actorID] = struct{}{}
+	}
+}
+
+// isReliable 消息是否使用可靠投递(按函数名或目标actorID匹配,子actor按父actorID匹配)
+func (p *Cluster) isReliable(packet *cproto.ClusterPacket) bool {
+	if !p.reliable.enable {
+		return false
+	}
+
+	if _, found := p.reliable.funcs[packet.FuncName]; found {
+		return true
+	}
+
+	if len(p.reliable.actors) < 1 {
+		return false
+	}
+
+	targetPath, err := cfacade.ToActorPath(packet.TargetPath)
+	if err != nil {
+		return false
+	}
+
+	_, found := p.reliable.actors[targetPath.ActorID]
+	return found
+}
+
+// reliableSubject 可靠投递的subject,nodeId为 @nodeType 时投递到该类型的任意一个节点
+func (p *Cluster) reliableSubject(nodeId string) string {
+	if nodeType, ok := cfacade.NodeTypeOf(nodeId); ok {
+		return getReliableTypeSubject(p.prefix, nodeType)
+	}
+	return getReliableNodeSubject(p.prefix, nodeId)
+}
+
+// reliableInit 创建stream及当前节点的consumer,开始拉取消息
+func (p *Cluster) reliableInit() {
+	if !p.reliable.enable {
+		return
+	}
+
+	if p.reliable.stream == "" {
+		p.reliable.stream = nameReplacer.Replace("cherry_" + p.prefix)
+	}
+
+	js, err := p.conn.JetStream()
+	if err != nil {
+		clog.Panicf("[reliable] Get JetStream context fail. [err = %v]", err)
+	}
+	p.reliable.js = js
+
+	if err = p.ensureStream(); err != nil {
+		clog.Panicf("[reliable] Create stream fail. [stream = %s, err = %v]", p.reliable.stream, err)
+	}
+
+	p.reliable.die = make(chan struct{})
+
+	// 当前节点的consumer及同类型节点共享的consumer
+	p.reliableSubscribe("node_"+p.app.NodeId(), getReliableNodeSubject(p.prefix, p.app.NodeId()))
+	p.reliableSubscribe("type_"+p.app.NodeType(), getReliableTypeSubject(p.prefix, p.app.NodeType()))
+
+	clog.Infof("[reliable] JetStream delivery is enabled. [stream = %s, maxDeliver = %d, ackWait = %s]",
+		p.reliable.stream,
+		p.reliable.maxDeliver,
+		p.reliable.ackWait,
+	)
+}
+
+func (p *Cluster) reliableStop() {
+	if p.reliable.die == nil {
+		return
+	}
+
+	close(p.reliable.die)
+	p.reliable.wg.Wait()
+
+	// 绑定已存在的consumer,取消订阅时不会删除consumer,未确认的消息在节点重启后继续投递
+	for _, sub := range p.reliable.subs {
+		if err := sub.Unsubscribe(); err != nil {
+			clog.Warnf("[reliable] Unsubscribe error. [subject = %s, err = %v]", sub.Subject, err)
+		}
+	}
+
+	p.reliable.subs = nil
+	p.reliable.die = nil
+}
+
+// ensureStream 创建stream(已存在时不修改配置)
+// 使用WorkQueue保留策略,消息确认后从stream中删除
+func (p *Cluster) ensureStream() error {
+	_, err := p.reliable.js.StreamInfo(p.reliable.stream)
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, nats.ErrStreamNotFound) {
+		return err
+	}
+
+	_, err = p.reliable.js.AddStream(&nats.StreamConfig{
+		Name:       p.reliable.stream,
+		Subjects:   []string{fmt.Sprintf(reliableStreamSubject, p.prefix)},
+		Retention:  nats.WorkQueuePolicy,
+		Storage:    nats.FileStorage,
+		Duplicates: p.reliable.duplicateWindow,
+	})
+
+	return err
+}
+
+// ensureConsumer 创建或更新持久化的pull consumer
+func (p *Cluster) ensureConsumer(durable, subject string) error {
+	cfg := &nats.ConsumerConfig{
+		Durable:       durable,
+		FilterSubject: subject,
+		AckPolicy:     nats.AckExplicitPolicy,
+		AckWait:       p.reliable.ackWait,
+		MaxDeliver:    p.reliable.maxDeliver,
+		DeliverPolicy: nats.DeliverAllPolicy,
+	}
+
+	_, err := p.reliable.js.ConsumerInfo(p.reliable.stream, durable)
+	if err == nil {
+		_, err = p.reliable.js.UpdateConsumer(p.reliable.stream, cfg)
+		return err
+	}
+
+	if !errors.Is(err, nats.ErrConsumerNotFound) {
+		return err
+	}
+
+	_, err = p.reliable.js.AddConsumer(p.reliable.stream, cfg)
+	return err
+}
+
+func (p *Cluster) reliableSubscribe(durable, subject string) {
+	durable = nameReplacer.Replace(durable)
+
+	if err := p.ensureConsumer(durable, subject); err != nil {
+		clog.Panicf("[reliable] Create consumer fail. [durable = %s, subject = %s, err = %v]", durable, subject, err)
+	}
+
+	sub, err := p.reliable.js.PullSubscribe(subject, durable, nats.Bind(p.reliable.stream, durable))
+	if err != nil {
+		clog.Panicf("[reliable] Subscribe fail. [durable = %s, subject = %s, err = %v]", durable, subject, err)
+	}
+
+	p.reliable.subs = append(p.reliable.subs, sub)
+	p.reliable.wg.Add(1)
+	go p.reliableProcess(sub)
+}
+
+func (p *Cluster) reliableProcess(sub *nats.Subscription) {
+	defer p.reliable.wg.Done()
+
+	for {
+		select {
+		case <-p.reliable.die:
+			return
+		default:
+		}
+
+		msgs, err := sub.Fetch(reliableFetchSize, nats.MaxWait(reliableFetchWait))
+		if err != nil {
+			if errors.Is(err, nats.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+				continue
+			}
+
+			clog.Warnf("[reliable] Fetch fail. [subject = %s, err = %v]", sub.Subject, err)
+
+			select {
+			case <-p.reliable.die:
+				return
+			case <-time.After(reliableFetchWait):
+			}
+			continue
+		}
+
+		for _, msg := range msgs {
+			p.reliableReceive(msg)
+		}
+	}
+}
+
+func (p *Cluster) reliableReceive(natsMsg *nats.Msg) {
+	packet := cproto.GetClusterPacket()
+	defer packet.Recycle()
+
+	if err := proto.Unmarshal(natsMsg.Data, packet); err != nil {
+		clog.Warnf("[reliable] Unmarshal fail. [subject = %s, err = %v]", natsMsg.Subject, err)
+		// 无法解析的消息不再投递
+		_ = natsMsg.Term()
+		return
+	}
+
+	reply := &reliableReply{
+		msg:        natsMsg,
+		maxDeliver: p.reliable.maxDeliver,
+		nakDelay:   p.reliable.nakDelay,
+	}
+
+	message := buildRemoteMessage(packet)
+	message.ClusterReply = reply
+
+	// 投递失败时可能已通过死信回复了错误码(同样重新投递),nak只执行一次
+	if code := p.app.ActorSystem().PostRemote(&message); ccode.IsFail(code) {
+		reply.nak(code)
+	}
+}
+
+// publishReliable 发布到JetStream,等待stream确认保存后返回
+func (p *Cluster) publishReliable(nodeId string, request *cproto.ClusterPacket) error {
+	if !p.app.Running() {
+		return cerr.ClusterRPCClientIsStop
+	}
+
+	if request.MessageId == "" {
+		request.MessageId = nuid.Next()
+	}
+
+	bytes, err := proto.Marshal(request)
+	if err != nil {
+		clog.Warn(err)
+		return err
+	}
+
+	msg := nats.NewMsg(p.reliableSubject(nodeId))
+	msg.Header.Set(nats.MsgIdHdr, request.MessageId)
+	msg.Data = bytes
+
+	if _, err = p.reliable.js.PublishMsg(msg); err != nil {
+		clog.Warnf("[reliable] Publish fail. [nodeId = %s, %s, messageId = %s, err = %v]",
+			nodeId,
+			request.PrintLog(),
+			request.MessageId,
+			err,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// Respond 函数执行完成后确认消息(包括业务错误码),函数未执行或执行panic时重新投递
+func (p *reliableReply) Respond(data []byte) error {
+	rsp := &cproto.Response{}
+	if err := proto.Unmarshal(data, rsp); err != nil {
+		return err
+	}
+
+	if _, retry := reliableRetryCodes[rsp.Code]; retry {
+		p.nak(rsp.Code)
+		return nil
+	}
+
+	p.once.Do(func() {
+		if err := p.msg.Ack(); err != nil {
+			clog.Warnf("[reliable] Ack fail. [subject = %s, err = %v]", p.msg.Subject, err)
+		}
+	})
+
+	return nil
+}
+
+// nak 延迟后重新投递,达到最大投递次数时终止投递
+func (p *reliableReply) nak(code int32) {
+	p.once.Do(func() {
+		meta, err := p.msg.Metadata()
+		if err == nil && meta.NumDelivered >= uint64(p.maxDeliver) {
+			clog.Errorf("[reliable] Max deliver reached, message is discarded. [subject = %s, delivered = %d, code = %d]",
+				p.msg.Subject,
+				meta.NumDelivered,
+				code,
+			)
+			_ = p.msg.Term()
+			return
+		}
+
+		if err = p.msg.NakWithDelay(p.nakDelay); err != nil {
+			clog.Warnf("[reliable] Nak fail. [subject = %s, err = %v]", p.msg.Subject, err)
+		}
+	})
+}
+
+// WithReliableFunc 指定函数名的Call消息使用可靠投递
+func WithReliableFunc(funcNames ...string) OptionFunc {
+	return func(o *Cluster) {
+		o.reliable.enable = true
+		o.reliable.addFuncs(funcNames...)
+	}
+}
+
+// WithReliableActor 发送到指定actorID(子actor按父actorID)的Call消息使用可靠投递
+func WithReliableActor(actorIDs ...string) OptionFunc {
+	return func(o *Cluster) {
+		o.reliable.enable = true
+		o.reliable.addActors(actorIDs...)
+	}
+}
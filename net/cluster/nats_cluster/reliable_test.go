@@ -0,0 +1,265 @@
+package cherryNatsCluster
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"google.golang.org/protobuf/proto"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cfacade "github.com/cherry-game/cherry/facade"
+	cnats "github.com/cherry-game/cherry/net/nats"
+	cproto "github.com/cherry-game/cherry/net/proto"
+)
+
+type (
+	testApp struct {
+		cfacade.IApplication
+		nodeID string
+		system *testSystem
+	}
+
+	testSystem struct {
+		cfacade.IActorSystem
+		messages chan *cfacade.Message
+		reject   int32 // 非0时模拟投递失败(先通过死信回复错误码)
+	}
+)
+
+func (p *testApp) NodeId() string                    { return p.nodeID }
+func (p *testApp) NodeType() string                  { return strings.Split(p.nodeID, "-")[0] }
+func (p *testApp) Running() bool                     { return true }
+func (p *testApp) ActorSystem() cfacade.IActorSystem { return p.system }
+
+func (p *testSystem) PostRemote(m *cfacade.Message) int32 {
+	p.messages <- m
+
+	if code := atomic.LoadInt32(&p.reject); code != ccode.OK {
+		respond(m, code)
+		return code
+	}
+
+	return ccode.OK
+}
+
+// runServer 启动开启JetStream的内嵌nats-server
+func runServer(t *testing.T) string {
+	s, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go s.Start()
+	if !s.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats-server is not ready")
+	}
+
+	t.Cleanup(s.Shutdown)
+	return s.ClientURL()
+}
+
+func newTestCluster(t *testing.T, url, nodeID string, options ...OptionFunc) *Cluster {
+	cluster := newCluster(&testApp{
+		nodeID: nodeID,
+		system: &testSystem{messages: make(chan *cfacade.Message, 16)},
+	})
+
+	for _, option := range options {
+		option(cluster)
+	}
+
+	cluster.conn = cnats.New(cnats.WithAddress(url))
+	cluster.prefix = "test"
+	cluster.reliable.nakDelay = 10 * time.Millisecond
+	cluster.initSubjects()
+	cluster.Init()
+
+	return cluster
+}
+
+func receive(t *testing.T, cluster *Cluster) *cfacade.Message {
+	select {
+	case m := <-cluster.app.(*testApp).system.messages:
+		return m
+	case <-time.After(5 * time.Second):
+		t.Fatal("receive timeout")
+	}
+	return nil
+}
+
+func respond(m *cfacade.Message, code int32) {
+	data, _ := proto.Marshal(&cproto.Response{Code: code})
+	m.ClusterReply.Respond(data)
+}
+
+func TestReliableRedeliver(t *testing.T) {
+	url := runServer(t)
+
+	// 创建consumer后停止节点,模拟目标节点重启
+	game := newTestCluster(t, url, "game-1", WithReliableFunc("addGold"))
+	game.Stop()
+
+	center := newTestCluster(t, url, "center-1", WithReliableFunc("addGold"))
+	defer center.Stop()
+
+	err := center.PublishRemote("game-1", &cproto.ClusterPacket{
+		SourcePath: "center-1.gm",
+		TargetPath: "game-1.player",
+		FuncName:   "addGold",
+		ArgBytes:   []byte("100"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	game = newTestCluster(t, url, "game-1", WithReliableFunc("addGold"))
+	defer game.Stop()
+
+	m := receive(t, game)
+	if m.FuncName != "addGold" || string(m.Args.([]byte)) != "100" || m.MessageID == "" {
+		t.Fatalf("message = %+v", m)
+	}
+
+	// 执行panic时重新投递,幂等键不变
+	respond(m, ccode.RPCRemoteExecuteError)
+
+	redelivered := receive(t, game)
+	if redelivered.MessageID != m.MessageID {
+		t.Fatalf("messageID = %s, want %s", redelivered.MessageID, m.MessageID)
+	}
+
+	respond(redelivered, ccode.OK)
+
+	select {
+	case m = <-game.app.(*testApp).system.messages:
+		t.Fatalf("acked message redelivered. [message = %+v]", m)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestReliableRetryCodes(t *testing.T) {
+	url := runServer(t)
+
+	game := newTestCluster(t, url, "game-1", WithReliableFunc("addGold"))
+	defer game.Stop()
+
+	center := newTestCluster(t, url, "center-1", WithReliableFunc("addGold"))
+	defer center.Stop()
+
+	publish := func() {
+		err := center.PublishRemote("game-1", &cproto.ClusterPacket{
+			TargetPath: "game-1.player",
+			FuncName:   "addGold",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 函数未执行的错误码重新投递
+	codes := []int32{ccode.ActorCallFail, ccode.ActorMailboxFull, ccode.ActorChildIDNotFound, ccode.ActorFuncNameError}
+	for _, code := range codes {
+		publish()
+
+		m := receive(t, game)
+		respond(m, code)
+
+		if redelivered := receive(t, game); redelivered.MessageID != m.MessageID {
+			t.Fatalf("code = %d, messageID = %s, want %s", code, redelivered.MessageID, m.MessageID)
+		} else {
+			respond(redelivered, ccode.OK)
+		}
+	}
+
+	// 投递失败时死信先回复了错误码,仍重新投递
+	system := game.app.(*testApp).system
+	atomic.StoreInt32(&system.reject, ccode.ActorCallFail)
+	publish()
+
+	m := receive(t, game)
+	atomic.StoreInt32(&system.reject, ccode.OK)
+
+	if redelivered := receive(t, game); redelivered.MessageID != m.MessageID {
+		t.Fatalf("messageID = %s, want %s", redelivered.MessageID, m.MessageID)
+	} else {
+		respond(redelivered, ccode.OK)
+	}
+
+	// 业务错误码视为已执行,不再投递
+	publish()
+	respond(receive(t, game), 1001)
+
+	select {
+	case m = <-system.messages:
+		t.Fatalf("acked message redelivered. [message = %+v]", m)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestReliableNodeType(t *testing.T) {
+	url := runServer(t)
+
+	center := newTestCluster(t, url, "center-1", WithReliableActor("player"))
+	defer center.Stop()
+
+	games := []*Cluster{
+		newTestCluster(t, url, "game-1", WithReliableActor("player")),
+		newTestCluster(t, url, "game-2", WithReliableActor("player")),
+	}
+	defer games[0].Stop()
+	defer games[1].Stop()
+
+	for i := 0; i < 10; i++ {
+		err := center.PublishRemote("@game", &cproto.ClusterPacket{
+			TargetPath: cfacade.NewTypePath("game", "player"),
+			FuncName:   "addGold",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	received := make(map[string]struct{})
+	for len(received) < 10 {
+		select {
+		case m := <-games[0].app.(*testApp).system.messages:
+			received[m.MessageID] = struct{}{}
+			respond(m, ccode.OK)
+		case m := <-games[1].app.(*testApp).system.messages:
+			received[m.MessageID] = struct{}{}
+			respond(m, ccode.OK)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received = %d", len(received))
+		}
+	}
+}
+
+func TestIsReliable(t *testing.T) {
+	cluster := newCluster(&testApp{nodeID: "center-1"})
+	WithReliableFunc("addGold")(cluster)
+	WithReliableActor("player")(cluster)
+
+	tests := map[string]bool{
+		"game-1.room:addGold":      true,
+		"game-1.player.1001:login": true,
+		"game-1.room:enter":        false,
+	}
+
+	for key, want := range tests {
+		target, funcName, _ := strings.Cut(key, ":")
+		packet := &cproto.ClusterPacket{TargetPath: target, FuncName: funcName}
+		if got := cluster.isReliable(packet); got != want {
+			t.Errorf("isReliable(%s) = %v, want %v", key, got, want)
+		}
+	}
+}
@@ -9,6 +9,7 @@ type (
 	natsSubject struct {
 		ch           chan *nats.Msg
 		subject      string
+		group        string // 队列组名称,不为空时使用队列订阅
 		subscription *nats.Subscription
 	}
 )
@@ -0,0 +1,112 @@
+package cherryPolicy
+
+import (
+	"sync"
+	"time"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+)
+
+const (
+	closedState   breakerState = iota // 正常
+	openState                         // 熔断中
+	halfOpenState                     // 冷却结束,等待探测请求的结果
+)
+
+type (
+	breakerState int
+
+	// breaker 节点熔断器
+	breaker struct {
+		sync.Mutex
+		state    breakerState
+		failures int       // 连续失败次数
+		openedAt time.Time // 熔断开始时间
+		cooldown time.Duration
+	}
+
+	// breakers 按节点id保存熔断器
+	breakers struct {
+		sync.Map // key:nodeId, value:*breaker
+	}
+)
+
+func (p *breakers) get(nodeId string) *breaker {
+	value, _ := p.LoadOrStore(nodeId, &breaker{})
+	return value.(*breaker)
+}
+
+// allow 是否允许向节点发送请求,冷却结束后只放行一个探测请求
+func (p *breakers) allow(nodeId string, policy *cfacade.Policy) bool {
+	if policy.BreakerThreshold < 1 {
+		return true
+	}
+
+	b := p.get(nodeId)
+	b.Lock()
+	defer b.Unlock()
+
+	switch b.state {
+	case openState:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = halfOpenState
+		return true
+	case halfOpenState:
+		return false
+	default:
+		return true
+	}
+}
+
+// report 记录请求结果,只有RPCNetError计为失败
+func (p *breakers) report(nodeId string, policy *cfacade.Policy, code int32) {
+	if policy.BreakerThreshold < 1 {
+		return
+	}
+
+	b := p.get(nodeId)
+	b.Lock()
+	defer b.Unlock()
+
+	if code != ccode.RPCNetError {
+		if b.state != closedState {
+			clog.Infof("[breaker] Node recovered. [nodeId = %s]", nodeId)
+		}
+		b.state = closedState
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == halfOpenState || b.failures >= policy.BreakerThreshold {
+		if b.state == closedState {
+			clog.Warnf("[breaker] Node is unavailable. [nodeId = %s, failures = %d, cooldown = %s]",
+				nodeId,
+				b.failures,
+				policy.BreakerCooldown,
+			)
+		}
+
+		b.state = openState
+		b.openedAt = time.Now()
+		b.cooldown = policy.BreakerCooldown
+	}
+}
+
+// available 节点是否可用(未熔断或冷却已结束)
+func (p *breakers) available(nodeId string) bool {
+	value, found := p.Load(nodeId)
+	if !found {
+		return true
+	}
+
+	b := value.(*breaker)
+	b.Lock()
+	defer b.Unlock()
+
+	return b.state != openState || time.Since(b.openedAt) >= b.cooldown
+}
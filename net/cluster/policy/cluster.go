@@ -0,0 +1,315 @@
+package cherryPolicy
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cerr "github.com/cherry-game/cherry/error"
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+	cproto "github.com/cherry-game/cherry/net/proto"
+)
+
+type (
+	// Cluster 为集群的远程调用增加重试、熔断及对冲请求,按目标节点类型配置策略
+	// 未配置策略的调用直接使用被包装的集群
+	Cluster struct {
+		cfacade.ICluster
+		app      cfacade.IApplication
+		policies cfacade.Policies
+		breakers breakers
+	}
+)
+
+// New 包装集群
+func New(app cfacade.IApplication, cluster cfacade.ICluster) *Cluster {
+	return &Cluster{
+		ICluster: cluster,
+		app:      app,
+		policies: cfacade.Policies{},
+	}
+}
+
+// LoadConfig 读取profile中cluster->policy节点配置的策略
+func (p *Cluster) LoadConfig(config cfacade.ProfileJSON) {
+	for nodeType, policy := range loadPolicies(config) {
+		p.SetPolicy(nodeType, policy)
+	}
+}
+
+// SetPolicy 设置目标节点类型的调用策略(需在节点启动前设置),nodeType为AnyNodeType时匹配所有类型
+func (p *Cluster) SetPolicy(nodeType string, policy *cfacade.Policy) {
+	if policy == nil {
+		delete(p.policies, nodeType)
+		return
+	}
+	p.policies[nodeType] = policy
+}
+
+func (p *Cluster) Available(nodeId string) bool {
+	return p.breakers.available(nodeId)
+}
+
+func (p *Cluster) PublishRemote(nodeId string, packet *cproto.ClusterPacket) error {
+	return p.PublishRemoteWithPolicy(nil, nodeId, packet)
+}
+
+func (p *Cluster) RequestRemote(nodeId string, packet *cproto.ClusterPacket, timeout ...time.Duration) cproto.Response {
+	return p.RequestRemoteWithPolicy(nil, nodeId, packet, timeout...)
+}
+
+// PublishRemoteWithPolicy 节点熔断中时返回错误,发布结果计入熔断器
+// 幂等函数发布失败时按策略在后台重试(返回nil),不阻塞调用方(如actor的消息处理)
+func (p *Cluster) PublishRemoteWithPolicy(policies cfacade.Policies, nodeId string, packet *cproto.ClusterPacket) error {
+	policy, found := p.policyOf(policies, nodeId)
+	if !found {
+		return p.ICluster.PublishRemote(nodeId, packet)
+	}
+
+	defer packet.Recycle()
+
+	err := p.publish(policy, nodeId, clonePacket(packet))
+	if err == nil || errors.Is(err, cerr.ClusterCircuitOpen) || !isRetryable(policy, packet.FuncName) || policy.Retries < 1 {
+		return err
+	}
+
+	p.retryPublish(policy, nodeId, clonePacket(packet), 0)
+	return nil
+}
+
+// RequestRemoteWithPolicy 按策略执行请求,幂等函数返回RPCNetError时重试,
+// 指定timeout时为本次调用的总超时时间,重试及等待都在timeout内完成,调用方(如actor)的阻塞时间不超过timeout
+func (p *Cluster) RequestRemoteWithPolicy(policies cfacade.Policies, nodeId string, packet *cproto.ClusterPacket, timeout ...time.Duration) cproto.Response {
+	policy, found := p.policyOf(policies, nodeId)
+	if !found {
+		return p.ICluster.RequestRemote(nodeId, packet, timeout...)
+	}
+
+	defer packet.Recycle()
+
+	var deadline time.Time
+	if len(timeout) > 0 && timeout[0] > 0 {
+		deadline = time.Now().Add(timeout[0])
+	}
+
+	retryable := isRetryable(policy, packet.FuncName)
+	hedgeable := retryable && isHedgeable(policy, nodeId, packet.TargetPath)
+	for attempt := 0; ; attempt++ {
+		attemptTimeout := timeout
+		if !deadline.IsZero() {
+			attemptTimeout = []time.Duration{time.Until(deadline)}
+		}
+
+		var rsp *cproto.Response
+		if hedgeable {
+			rsp = p.hedge(policy, nodeId, packet, attemptTimeout)
+		} else {
+			rsp = p.request(policy, nodeId, clonePacket(packet), attemptTimeout)
+		}
+
+		if rsp.Code != ccode.RPCNetError || !retryable || attempt >= policy.Retries {
+			return cproto.Response{Code: rsp.Code, Data: rsp.Data}
+		}
+
+		// 剩余时间不足以等待并重试
+		backoff := backoffOf(policy, attempt)
+		if !deadline.IsZero() && time.Until(deadline) <= backoff {
+			return cproto.Response{Code: rsp.Code, Data: rsp.Data}
+		}
+
+		clog.Debugf("[policy] Retry request. [nodeId = %s, %s, attempt = %d]", nodeId, packet.PrintLog(), attempt+1)
+		time.Sleep(backoff)
+	}
+}
+
+// publish 发布一次并记录熔断器结果
+func (p *Cluster) publish(policy *cfacade.Policy, nodeId string, packet *cproto.ClusterPacket) error {
+	// 按类型寻址时由集群选择节点,不使用熔断器
+	_, isType := cfacade.NodeTypeOf(nodeId)
+
+	if !isType && !p.breakers.allow(nodeId, policy) {
+		packet.Recycle()
+		return cerr.ClusterCircuitOpen
+	}
+
+	err := p.ICluster.PublishRemote(nodeId, packet)
+	if !isType {
+		code := ccode.OK
+		if err != nil {
+			code = ccode.RPCNetError
+		}
+		p.breakers.report(nodeId, policy, code)
+	}
+
+	return err
+}
+
+// retryPublish 等待backoff后在后台重试发布,重试结束后回收packet
+func (p *Cluster) retryPublish(policy *cfacade.Policy, nodeId string, packet *cproto.ClusterPacket, attempt int) {
+	time.AfterFunc(backoffOf(policy, attempt), func() {
+		err := p.publish(policy, nodeId, clonePacket(packet))
+		if err != nil && !errors.Is(err, cerr.ClusterCircuitOpen) && attempt+1 < policy.Retries {
+			p.retryPublish(policy, nodeId, packet, attempt+1)
+			return
+		}
+
+		if err != nil {
+			clog.Warnf("[policy] Publish retry fail. [nodeId = %s, %s, attempt = %d, err = %v]", nodeId, packet.PrintLog(), attempt+1, err)
+		}
+		packet.Recycle()
+	})
+}
+
+// request 向节点发送一次请求并记录熔断器结果
+func (p *Cluster) request(policy *cfacade.Policy, nodeId string, packet *cproto.ClusterPacket, timeout []time.Duration) *cproto.Response {
+	// 按类型寻址时由集群选择节点,不使用熔断器
+	_, isType := cfacade.NodeTypeOf(nodeId)
+
+	if !isType && !p.breakers.allow(nodeId, policy) {
+		packet.Recycle()
+		return &cproto.Response{Code: ccode.RPCCircuitOpen}
+	}
+
+	rsp := p.ICluster.RequestRemote(nodeId, packet, timeout...)
+	if !isType {
+		p.breakers.report(nodeId, policy, rsp.Code)
+	}
+
+	return &cproto.Response{Code: rsp.Code, Data: rsp.Data}
+}
+
+// hedge 超过HedgeDelay未返回或请求失败时,向同类型的另一个节点发送相同的请求,返回先成功的结果
+// 只用于可对冲的请求(见isHedgeable),有状态actor的请求不会发送到其他节点
+func (p *Cluster) hedge(policy *cfacade.Policy, nodeId string, packet *cproto.ClusterPacket, timeout []time.Duration) *cproto.Response {
+	results := make(chan *cproto.Response, 2)
+
+	first := clonePacket(packet)
+	go func() {
+		results <- p.request(policy, nodeId, first, timeout)
+	}()
+
+	timer := time.NewTimer(policy.HedgeDelay)
+	defer timer.Stop()
+
+	pending, hedged := 1, false
+	sendHedge := func() bool {
+		hedgeNodeId, found := p.hedgeNode(nodeId)
+		if !found {
+			return false
+		}
+
+		second := clonePacket(packet)
+		second.TargetPath = retarget(second.TargetPath, hedgeNodeId)
+
+		pending++
+		go func() {
+			results <- p.request(policy, hedgeNodeId, second, timeout)
+		}()
+		return true
+	}
+
+	for {
+		select {
+		case rsp := <-results:
+			pending--
+			if !isRetryCode(rsp.Code) {
+				return rsp
+			}
+
+			if pending > 0 {
+				continue
+			}
+
+			if hedged || !sendHedge() {
+				return rsp
+			}
+			hedged = true
+		case <-timer.C:
+			if !hedged {
+				hedged = sendHedge()
+			}
+		}
+	}
+}
+
+// hedgeNode 选择对冲请求的节点(同类型的其他可用节点)
+func (p *Cluster) hedgeNode(nodeId string) (string, bool) {
+	if _, ok := cfacade.NodeTypeOf(nodeId); ok {
+		return nodeId, true
+	}
+
+	nodeType, err := p.app.Discovery().GetType(nodeId)
+	if err != nil {
+		return "", false
+	}
+
+	var list []string
+	for _, member := range p.app.Discovery().ListByType(nodeType, nodeId) {
+		if p.breakers.available(member.GetNodeId()) {
+			list = append(list, member.GetNodeId())
+		}
+	}
+
+	if len(list) < 1 {
+		return "", false
+	}
+
+	return list[rand.Intn(len(list))], true
+}
+
+// policyOf 获取目标节点的调用策略
+func (p *Cluster) policyOf(policies cfacade.Policies, nodeId string) (*cfacade.Policy, bool) {
+	if len(policies) < 1 && len(p.policies) < 1 {
+		return nil, false
+	}
+
+	nodeType := p.nodeType(nodeId)
+	if policy, found := policies.Get(nodeType); found {
+		return policy, true
+	}
+
+	return p.policies.Get(nodeType)
+}
+
+func (p *Cluster) nodeType(nodeId string) string {
+	if nodeType, ok := cfacade.NodeTypeOf(nodeId); ok {
+		return nodeType
+	}
+
+	if p.app == nil || p.app.Discovery() == nil {
+		return ""
+	}
+
+	nodeType, _ := p.app.Discovery().GetType(nodeId)
+	return nodeType
+}
+
+// isRetryCode 请求失败且可以向其他节点重试
+func isRetryCode(code int32) bool {
+	return code == ccode.RPCNetError || code == ccode.RPCCircuitOpen
+}
+
+// retarget 将actor path中的节点替换为nodeId
+func retarget(target, nodeId string) string {
+	path, err := cfacade.ToActorPath(target)
+	if err != nil {
+		return target
+	}
+	return cfacade.NewChildPath(nodeId, path.ActorID, path.ChildID)
+}
+
+// clonePacket 集群发送后会回收packet,每次发送使用副本
+func clonePacket(packet *cproto.ClusterPacket) *cproto.ClusterPacket {
+	clone := cproto.GetClusterPacket()
+	clone.BuildTime = packet.BuildTime
+	clone.SourcePath = packet.SourcePath
+	clone.TargetPath = packet.TargetPath
+	clone.FuncName = packet.FuncName
+	clone.ArgBytes = packet.ArgBytes
+	clone.Session = packet.Session
+	clone.TraceParent = packet.TraceParent
+	clone.MessageId = packet.MessageId
+	return clone
+}
@@ -0,0 +1,270 @@
+package cherryPolicy
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cerr "github.com/cherry-game/cherry/error"
+	cfacade "github.com/cherry-game/cherry/facade"
+	cproto "github.com/cherry-game/cherry/net/proto"
+)
+
+type (
+	testApp struct {
+		cfacade.IApplication
+		discovery *testDiscovery
+	}
+
+	testDiscovery struct {
+		cfacade.IDiscovery
+		nodeIDs []string
+	}
+
+	// testCluster 按节点执行handler,记录请求次数
+	testCluster struct {
+		cfacade.ICluster
+		sync.Mutex
+		calls       map[string]int
+		handler     func(nodeID string, packet *cproto.ClusterPacket) int32
+		publishFail int // 前n次发布返回错误
+	}
+)
+
+func (p *testApp) Discovery() cfacade.IDiscovery { return p.discovery }
+
+func (p *testDiscovery) GetType(nodeID string) (string, error) {
+	for _, id := range p.nodeIDs {
+		if id == nodeID {
+			return strings.Split(nodeID, "-")[0], nil
+		}
+	}
+	return "", errors.New("node not found")
+}
+
+func (p *testDiscovery) ListByType(nodeType string, filterNodeID ...string) []cfacade.IMember {
+	var list []cfacade.IMember
+	for _, nodeID := range p.nodeIDs {
+		if strings.HasPrefix(nodeID, nodeType+"-") && (len(filterNodeID) < 1 || filterNodeID[0] != nodeID) {
+			list = append(list, &cproto.Member{NodeId: nodeID, NodeType: nodeType})
+		}
+	}
+	return list
+}
+
+func (p *testCluster) count(nodeID string) int {
+	p.Lock()
+	defer p.Unlock()
+	return p.calls[nodeID]
+}
+
+func (p *testCluster) RequestRemote(nodeID string, packet *cproto.ClusterPacket, _ ...time.Duration) cproto.Response {
+	defer packet.Recycle()
+
+	p.Lock()
+	p.calls[nodeID]++
+	p.Unlock()
+
+	return cproto.Response{Code: p.handler(nodeID, packet), Data: []byte(packet.TargetPath)}
+}
+
+func (p *testCluster) PublishRemote(nodeID string, packet *cproto.ClusterPacket) error {
+	defer packet.Recycle()
+
+	p.Lock()
+	defer p.Unlock()
+
+	p.calls[nodeID]++
+	if p.publishFail > 0 {
+		p.publishFail--
+		return cerr.ClusterRPCClientIsStop
+	}
+	return nil
+}
+
+func newTestCluster(handler func(nodeID string, packet *cproto.ClusterPacket) int32) (*Cluster, *testCluster) {
+	inner := &testCluster{calls: map[string]int{}, handler: handler}
+	app := &testApp{discovery: &testDiscovery{nodeIDs: []string{"game-1", "game-2"}}}
+	return New(app, inner), inner
+}
+
+func request(cluster *Cluster, policies cfacade.Policies, nodeID, funcName string) cproto.Response {
+	packet := cproto.BuildClusterPacket("center-1.gm", nodeID+".player", funcName)
+	return cluster.RequestRemoteWithPolicy(policies, nodeID, packet)
+}
+
+func TestRetry(t *testing.T) {
+	failures := 2
+	cluster, inner := newTestCluster(func(_ string, _ *cproto.ClusterPacket) int32 {
+		if failures > 0 {
+			failures--
+			return ccode.RPCNetError
+		}
+		return ccode.OK
+	})
+
+	// 未配置策略时不重试
+	if rsp := request(cluster, nil, "game-1", "get"); rsp.Code != ccode.RPCNetError {
+		t.Fatalf("code = %d", rsp.Code)
+	}
+
+	policies := cfacade.Policies{"game": {Retries: 2, Backoff: time.Millisecond, Idempotent: []string{"get"}}}
+
+	// 非幂等函数不重试
+	if rsp := request(cluster, policies, "game-1", "set"); rsp.Code != ccode.RPCNetError {
+		t.Fatalf("set code = %d", rsp.Code)
+	}
+
+	failures = 2
+	if rsp := request(cluster, policies, "game-1", "get"); rsp.Code != ccode.OK {
+		t.Fatalf("get code = %d", rsp.Code)
+	}
+
+	if count := inner.count("game-1"); count != 5 {
+		t.Fatalf("count = %d", count)
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	healthy := false
+	cluster, inner := newTestCluster(func(_ string, _ *cproto.ClusterPacket) int32 {
+		if healthy {
+			return ccode.OK
+		}
+		return ccode.RPCNetError
+	})
+	cluster.SetPolicy(cfacade.AnyNodeType, &cfacade.Policy{BreakerThreshold: 2, BreakerCooldown: 50 * time.Millisecond})
+
+	for i := 0; i < 2; i++ {
+		if rsp := request(cluster, nil, "game-1", "get"); rsp.Code != ccode.RPCNetError {
+			t.Fatalf("code = %d", rsp.Code)
+		}
+	}
+
+	// 熔断后不再请求该节点
+	if rsp := request(cluster, nil, "game-1", "get"); rsp.Code != ccode.RPCCircuitOpen {
+		t.Fatalf("code = %d", rsp.Code)
+	}
+
+	packet := cproto.BuildClusterPacket("center-1.gm", "game-1.player", "notify")
+	if err := cluster.PublishRemote("game-1", packet); !errors.Is(err, cerr.ClusterCircuitOpen) {
+		t.Fatalf("err = %v", err)
+	}
+
+	if cluster.Available("game-1") || !cluster.Available("game-2") || inner.count("game-1") != 2 {
+		t.Fatalf("available = %v, count = %d", cluster.Available("game-1"), inner.count("game-1"))
+	}
+
+	// 冷却结束后探测成功,恢复正常
+	time.Sleep(60 * time.Millisecond)
+	healthy = true
+
+	if rsp := request(cluster, nil, "game-1", "get"); rsp.Code != ccode.OK {
+		t.Fatalf("code = %d", rsp.Code)
+	}
+
+	if !cluster.Available("game-1") {
+		t.Fatal("game-1 is unavailable")
+	}
+}
+
+func TestHedge(t *testing.T) {
+	cluster, inner := newTestCluster(func(nodeID string, _ *cproto.ClusterPacket) int32 {
+		if nodeID == "game-1" {
+			time.Sleep(300 * time.Millisecond)
+		}
+		return ccode.OK
+	})
+	cluster.SetPolicy("game", &cfacade.Policy{HedgeDelay: 20 * time.Millisecond, Idempotent: []string{cfacade.AnyFunc}})
+
+	// 有状态的actor不会发送到其他节点
+	rsp := request(cluster, nil, "game-1", "get")
+	if rsp.Code != ccode.OK || string(rsp.Data) != "game-1.player" || inner.count("game-2") != 0 {
+		t.Fatalf("code = %d, data = %s, count = %d", rsp.Code, rsp.Data, inner.count("game-2"))
+	}
+
+	cluster.SetPolicy("game", &cfacade.Policy{HedgeDelay: 20 * time.Millisecond, Idempotent: []string{cfacade.AnyFunc}, Stateless: []string{"player"}})
+
+	begin := time.Now()
+	rsp = request(cluster, nil, "game-1", "get")
+	if rsp.Code != ccode.OK || string(rsp.Data) != "game-2.player" {
+		t.Fatalf("code = %d, data = %s", rsp.Code, rsp.Data)
+	}
+
+	if elapsed := time.Since(begin); elapsed > 200*time.Millisecond {
+		t.Fatalf("elapsed = %s", elapsed)
+	}
+}
+
+func TestRequestDeadline(t *testing.T) {
+	cluster, inner := newTestCluster(func(_ string, _ *cproto.ClusterPacket) int32 {
+		return ccode.RPCNetError
+	})
+	cluster.SetPolicy("game", &cfacade.Policy{Retries: 10, Backoff: 40 * time.Millisecond, Idempotent: []string{"get"}})
+
+	// 重试及等待不超过调用方的timeout
+	begin := time.Now()
+	packet := cproto.BuildClusterPacket("center-1.gm", "game-1.player", "get")
+	if rsp := cluster.RequestRemoteWithPolicy(nil, "game-1", packet, 100*time.Millisecond); rsp.Code != ccode.RPCNetError {
+		t.Fatalf("code = %d", rsp.Code)
+	}
+
+	if elapsed := time.Since(begin); elapsed > 100*time.Millisecond {
+		t.Fatalf("elapsed = %s", elapsed)
+	}
+
+	if count := inner.count("game-1"); count != 2 {
+		t.Fatalf("count = %d", count)
+	}
+}
+
+func TestPublishRetry(t *testing.T) {
+	cluster, inner := newTestCluster(nil)
+	inner.publishFail = 2
+	cluster.SetPolicy("game", &cfacade.Policy{Retries: 2, Backoff: 10 * time.Millisecond, Idempotent: []string{"notify"}})
+
+	// 失败后在后台重试,不阻塞调用方
+	packet := cproto.BuildClusterPacket("center-1.gm", "game-1.player", "notify")
+	if err := cluster.PublishRemote("game-1", packet); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for inner.count("game-1") < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("count = %d", inner.count("game-1"))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// 发布失败计入熔断器
+	inner.Lock()
+	inner.publishFail = 2
+	inner.Unlock()
+	cluster.SetPolicy("game", &cfacade.Policy{BreakerThreshold: 2, BreakerCooldown: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		packet = cproto.BuildClusterPacket("center-1.gm", "game-1.player", "notify")
+		if err := cluster.PublishRemote("game-1", packet); err == nil {
+			t.Fatal("publish should fail")
+		}
+	}
+
+	if cluster.Available("game-1") {
+		t.Fatal("game-1 should be unavailable")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := &cfacade.Policy{Backoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+
+	want := []time.Duration{10, 20, 40, 50, 50}
+	for attempt, backoff := range want {
+		if got := backoffOf(policy, attempt); got != backoff*time.Millisecond {
+			t.Errorf("backoffOf(%d) = %s", attempt, got)
+		}
+	}
+}
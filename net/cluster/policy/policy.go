@@ -0,0 +1,101 @@
+package cherryPolicy
+
+import (
+	"time"
+
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+)
+
+type (
+	// policyConfig profile中cluster->policy->{nodeType}节点,时间单位为毫秒
+	policyConfig struct {
+		Retries          int      `json:"retries"`
+		Backoff          int64    `json:"backoff"`
+		MaxBackoff       int64    `json:"max_backoff"`
+		Idempotent       []string `json:"idempotent"`
+		BreakerThreshold int      `json:"breaker_threshold"`
+		BreakerCooldown  int64    `json:"breaker_cooldown"`
+		HedgeDelay       int64    `json:"hedge_delay"`
+		Stateless        []string `json:"stateless"`
+	}
+)
+
+// isRetryable 函数是否可重试
+func isRetryable(policy *cfacade.Policy, funcName string) bool {
+	for _, name := range policy.Idempotent {
+		if name == funcName || name == cfacade.AnyFunc {
+			return true
+		}
+	}
+	return false
+}
+
+// isHedgeable 请求是否可以对冲
+// 按类型寻址时由集群选择节点;指定节点时只有无状态的actor可以发送到其他节点
+func isHedgeable(policy *cfacade.Policy, nodeId, targetPath string) bool {
+	if policy.HedgeDelay <= 0 {
+		return false
+	}
+
+	if _, ok := cfacade.NodeTypeOf(nodeId); ok {
+		return true
+	}
+
+	path, err := cfacade.ToActorPath(targetPath)
+	if err != nil {
+		return false
+	}
+
+	for _, actorID := range policy.Stateless {
+		if actorID == path.ActorID {
+			return true
+		}
+	}
+	return false
+}
+
+// backoffOf 第attempt次(从0开始)重试前的等待时间
+func backoffOf(policy *cfacade.Policy, attempt int) time.Duration {
+	backoff := policy.Backoff
+	for i := 0; i < attempt && backoff > 0; i++ {
+		backoff *= 2
+		if policy.MaxBackoff > 0 && backoff >= policy.MaxBackoff {
+			return policy.MaxBackoff
+		}
+	}
+
+	if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+		return policy.MaxBackoff
+	}
+	return backoff
+}
+
+// loadPolicies 读取profile中的调用策略
+func loadPolicies(config cfacade.ProfileJSON) cfacade.Policies {
+	policies := cfacade.Policies{}
+	if config == nil || config.LastError() != nil {
+		return policies
+	}
+
+	configs := map[string]policyConfig{}
+	if err := config.Unmarshal(&configs); err != nil {
+		clog.Warnf("[policy] Unmarshal config fail. [err = %v]", err)
+		return policies
+	}
+
+	for nodeType, cfg := range configs {
+		policies[nodeType] = &cfacade.Policy{
+			Retries:          cfg.Retries,
+			Backoff:          time.Duration(cfg.Backoff) * time.Millisecond,
+			MaxBackoff:       time.Duration(cfg.MaxBackoff) * time.Millisecond,
+			Idempotent:       cfg.Idempotent,
+			BreakerThreshold: cfg.BreakerThreshold,
+			BreakerCooldown:  time.Duration(cfg.BreakerCooldown) * time.Millisecond,
+			HedgeDelay:       time.Duration(cfg.HedgeDelay) * time.Millisecond,
+			Stateless:        cfg.Stateless,
+		}
+	}
+
+	return policies
+}
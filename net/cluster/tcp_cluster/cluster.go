@@ -0,0 +1,424 @@
+package cherryTCPCluster
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cerr "github.com/cherry-game/cherry/error"
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+	cproto "github.com/cherry-game/cherry/net/proto"
+	cprofile "github.com/cherry-game/cherry/profile"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	Mode = "tcp" // profile中cluster->mode的值
+)
+
+var (
+	errNodeNotFound = cerr.Error("cluster node not found")
+)
+
+type (
+	// Cluster 节点之间直接通过tcp连接通信,不依赖nats
+	// 当前节点监听RpcAddress(),通过发现服务获取目标节点的地址(IMember.GetAddress())后建立连接池
+	// 发现服务需使用default或etcd模式(nats发现服务依赖nats集群)
+	Cluster struct {
+		sync.RWMutex
+		app            cfacade.IApplication
+		listener       net.Listener
+		peers          map[string]*peer // key:nodeID
+		accepted       sync.Map         // key:*conn, 其他节点发起的连接
+		poolSize       int              // 每个节点的连接数
+		dialTimeout    time.Duration    // 连接超时时间
+		requestTimeout time.Duration    // 请求超时时间
+		writeTimeout   time.Duration    // 写超时时间,超时后关闭连接
+		reconnectDelay time.Duration    // 连接失败后的重连间隔
+		listening      sync.Once
+		stopped        bool
+	}
+
+	OptionFunc func(o *Cluster)
+)
+
+// New 创建tcp集群,读取profile中cluster->tcp的配置,options优先于配置
+func New(app cfacade.IApplication, options ...OptionFunc) cfacade.ICluster {
+	cluster := newCluster(app)
+	cluster.loadConfig()
+
+	for _, option := range options {
+		option(cluster)
+	}
+
+	return cluster
+}
+
+func newCluster(app cfacade.IApplication) *Cluster {
+	return &Cluster{
+		app:            app,
+		peers:          make(map[string]*peer),
+		poolSize:       1,
+		dialTimeout:    3 * time.Second,
+		requestTimeout: 3 * time.Second,
+		writeTimeout:   3 * time.Second,
+		reconnectDelay: 1 * time.Second,
+	}
+}
+
+func (p *Cluster) loadConfig() {
+	tcpConfig := cprofile.GetConfig("cluster").GetConfig(Mode)
+	if tcpConfig.LastError() != nil {
+		return
+	}
+
+	p.poolSize = tcpConfig.GetInt("pool_size", p.poolSize)
+	p.dialTimeout = tcpConfig.GetDuration("dial_timeout", 3) * time.Second
+	p.requestTimeout = tcpConfig.GetDuration("request_timeout", 3) * time.Second
+	p.writeTimeout = tcpConfig.GetDuration("write_timeout", 3) * time.Second
+	p.reconnectDelay = tcpConfig.GetDuration("reconnect_delay", 1) * time.Second
+}
+
+func (p *Cluster) Init() {
+	if p.poolSize < 1 {
+		p.poolSize = 1
+	}
+
+	address := p.app.RpcAddress()
+	if address == "" {
+		panic("tcp cluster rpc address is empty.")
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		panic(err)
+	}
+
+	p.listener = listener
+	go p.accept()
+
+	clog.Infof("tcp cluster execute OnInit(). [address = %s]", listener.Addr())
+}
+
+func (p *Cluster) Stop() {
+	p.Lock()
+	p.stopped = true
+	peers := p.peers
+	p.peers = make(map[string]*peer)
+	p.Unlock()
+
+	if p.listener != nil {
+		p.listener.Close()
+	}
+
+	for _, item := range peers {
+		item.close()
+	}
+
+	p.accepted.Range(func(key, _ any) bool {
+		key.(*conn).close()
+		return true
+	})
+
+	clog.Info("tcp cluster execute OnStop().")
+}
+
+// Addr 监听的地址
+func (p *Cluster) Addr() net.Addr {
+	if p.listener == nil {
+		return nil
+	}
+	return p.listener.Addr()
+}
+
+func (p *Cluster) accept() {
+	for {
+		netConn, err := p.listener.Accept()
+		if err != nil {
+			if p.isStopped() {
+				return
+			}
+
+			clog.Warnf("[accept] Accept fail. [err = %v]", err)
+			continue
+		}
+
+		c := newConn(p, netConn, func(c *conn) {
+			p.accepted.Delete(c)
+		})
+		p.accepted.Store(c, struct{}{})
+
+		go c.readLoop()
+	}
+}
+
+// dispatch 处理其他节点发送的消息
+func (p *Cluster) dispatch(c *conn, f *frame) {
+	packet := cproto.GetClusterPacket()
+	defer packet.Recycle()
+
+	if err := proto.Unmarshal(f.payload, packet); err != nil {
+		clog.Warnf("[dispatch] Unmarshal fail. [remote = %s, type = %d, err = %v]", c.RemoteAddr(), f.typ, err)
+		return
+	}
+
+	message := cfacade.GetMessage()
+	message.BuildTime = packet.BuildTime
+	message.Source = packet.SourcePath
+	message.Target = packet.TargetPath
+	message.FuncName = packet.FuncName
+	message.IsCluster = true
+	message.TraceParent = packet.TraceParent
+	message.MessageID = packet.MessageId
+	if packet.ArgBytes != nil {
+		message.Args = packet.ArgBytes
+	}
+
+	switch f.typ {
+	case localFrame:
+		message.Session = packet.Session
+		p.app.ActorSystem().PostLocal(&message)
+	case remoteFrame:
+		p.app.ActorSystem().PostRemote(&message)
+	case requestFrame:
+		r := &reply{conn: c, seq: f.seq}
+		message.ClusterReply = r
+
+		// 投递失败(邮箱已满、actor不存在等)时立即返回错误码,避免调用方等待超时
+		if code := p.app.ActorSystem().PostRemote(&message); ccode.IsFail(code) {
+			data, _ := proto.Marshal(&cproto.Response{Code: code})
+			if err := r.Respond(data); err != nil {
+				clog.Warnf("[dispatch] Reply fail. [remote = %s, code = %d, err = %v]", c.RemoteAddr(), code, err)
+			}
+		}
+	default:
+		clog.Warnf("[dispatch] Unknown frame type. [remote = %s, type = %d]", c.RemoteAddr(), f.typ)
+	}
+}
+
+func (p *Cluster) PublishLocal(nodeId string, request *cproto.ClusterPacket) error {
+	defer request.Recycle()
+
+	err := p.publish(localFrame, nodeId, request)
+
+	if clog.PrintLevel(zapcore.DebugLevel) {
+		clog.Debugf("[PublishLocal] [nodeId = %s, %s, err = %v]",
+			nodeId,
+			request.PrintLog(),
+			err,
+		)
+	}
+
+	return err
+}
+
+func (p *Cluster) PublishRemote(nodeId string, request *cproto.ClusterPacket) error {
+	defer request.Recycle()
+
+	err := p.publish(remoteFrame, nodeId, request)
+	if err != nil {
+		clog.Debugf("[PublishRemote] Publish fail. [nodeId = %s, %s, err = %v]",
+			nodeId,
+			request.PrintLog(),
+			err,
+		)
+	}
+
+	return err
+}
+
+func (p *Cluster) RequestRemote(nodeId string, request *cproto.ClusterPacket, timeout ...time.Duration) cproto.Response {
+	defer request.Recycle()
+
+	c, err := p.getConn(nodeId)
+	if err != nil {
+		clog.Debugf("[RequestRemote] Get conn fail. [nodeId = %s, %s, err = %v]",
+			nodeId,
+			request.PrintLog(),
+			err,
+		)
+
+		if err == errNodeNotFound {
+			return cproto.Response{Code: ccode.DiscoveryNotFoundNode}
+		}
+		return cproto.Response{Code: ccode.RPCNetError}
+	}
+
+	bytes, err := proto.Marshal(request)
+	if err != nil {
+		return cproto.Response{Code: ccode.RPCMarshalError}
+	}
+
+	seq, ch, err := c.request(bytes)
+	if err != nil {
+		clog.Warnf("[RequestRemote] Request fail. [nodeId = %s, %s, err = %v]",
+			nodeId,
+			request.PrintLog(),
+			err,
+		)
+
+		return cproto.Response{Code: ccode.RPCNetError}
+	}
+
+	requestTimeout := p.requestTimeout
+	if len(timeout) > 0 && timeout[0] > 0 {
+		requestTimeout = timeout[0]
+	}
+
+	timer := time.NewTimer(requestTimeout)
+	defer timer.Stop()
+
+	select {
+	case data := <-ch:
+		if data == nil {
+			return cproto.Response{Code: ccode.RPCNetError}
+		}
+
+		rsp := &cproto.Response{}
+		if err = proto.Unmarshal(data, rsp); err != nil {
+			clog.Warnf("[RequestRemote] Unmarshal fail. [nodeId = %s, %s, err = %v]",
+				nodeId,
+				request.PrintLog(),
+				err,
+			)
+
+			return cproto.Response{Code: ccode.RPCUnmarshalError}
+		}
+
+		return cproto.Response{Code: rsp.Code, Data: rsp.Data}
+	case <-timer.C:
+		c.pending.Delete(seq)
+		clog.Warnf("[RequestRemote] Request timeout. [nodeId = %s, %s, timeout = %s]",
+			nodeId,
+			request.PrintLog(),
+			requestTimeout,
+		)
+
+		return cproto.Response{Code: ccode.RPCNetError}
+	}
+}
+
+func (p *Cluster) publish(typ byte, nodeId string, request *cproto.ClusterPacket) error {
+	if !p.app.Running() {
+		return cerr.ClusterRPCClientIsStop
+	}
+
+	c, err := p.getConn(nodeId)
+	if err != nil {
+		return err
+	}
+
+	bytes, err := proto.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	return c.write(typ, 0, bytes)
+}
+
+// getConn 获取到目标节点的连接,节点地址变化时重建连接池
+// nodeId为 @nodeType 时随机选择该类型的一个节点
+func (p *Cluster) getConn(nodeId string) (*conn, error) {
+	member, found := p.getMember(nodeId)
+	if !found || member.GetAddress() == "" {
+		return nil, errNodeNotFound
+	}
+
+	nodeId = member.GetNodeId()
+
+	p.listenMember()
+
+	p.RLock()
+	item, found := p.peers[nodeId]
+	p.RUnlock()
+
+	if !found || item.address != member.GetAddress() {
+		p.Lock()
+		if p.stopped {
+			p.Unlock()
+			return nil, cerr.ClusterRPCClientIsStop
+		}
+
+		item, found = p.peers[nodeId]
+		if !found || item.address != member.GetAddress() {
+			if found {
+				item.close()
+			}
+
+			item = newPeer(p, nodeId, member.GetAddress())
+			p.peers[nodeId] = item
+		}
+		p.Unlock()
+	}
+
+	return item.get()
+}
+
+func (p *Cluster) getMember(nodeId string) (cfacade.IMember, bool) {
+	if nodeType, ok := cfacade.NodeTypeOf(nodeId); ok {
+		return p.app.Discovery().Random(nodeType)
+	}
+
+	return p.app.Discovery().GetMember(nodeId)
+}
+
+// listenMember 节点移除时关闭连接池
+func (p *Cluster) listenMember() {
+	p.listening.Do(func() {
+		p.app.Discovery().OnRemoveMember(func(member cfacade.IMember) {
+			p.Lock()
+			item, found := p.peers[member.GetNodeId()]
+			delete(p.peers, member.GetNodeId())
+			p.Unlock()
+
+			if found {
+				item.close()
+			}
+		})
+	})
+}
+
+func (p *Cluster) isStopped() bool {
+	p.RLock()
+	defer p.RUnlock()
+	return p.stopped
+}
+
+// WithPoolSize 每个节点的连接数
+func WithPoolSize(size int) OptionFunc {
+	return func(o *Cluster) {
+		o.poolSize = size
+	}
+}
+
+// WithDialTimeout 连接超时时间
+func WithDialTimeout(timeout time.Duration) OptionFunc {
+	return func(o *Cluster) {
+		o.dialTimeout = timeout
+	}
+}
+
+// WithRequestTimeout 请求超时时间
+func WithRequestTimeout(timeout time.Duration) OptionFunc {
+	return func(o *Cluster) {
+		o.requestTimeout = timeout
+	}
+}
+
+// WithWriteTimeout 写超时时间,超时后关闭连接
+func WithWriteTimeout(timeout time.Duration) OptionFunc {
+	return func(o *Cluster) {
+		o.writeTimeout = timeout
+	}
+}
+
+// WithReconnectDelay 连接失败后的重连间隔
+func WithReconnectDelay(delay time.Duration) OptionFunc {
+	return func(o *Cluster) {
+		o.reconnectDelay = delay
+	}
+}
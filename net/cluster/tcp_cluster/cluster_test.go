@@ -0,0 +1,234 @@
+package cherryTCPCluster
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	ccode "github.com/cherry-game/cherry/code"
+	cfacade "github.com/cherry-game/cherry/facade"
+	cproto "github.com/cherry-game/cherry/net/proto"
+)
+
+type (
+	testApp struct {
+		cfacade.IApplication
+		nodeID    string
+		discovery *testDiscovery
+		system    *testSystem
+	}
+
+	testDiscovery struct {
+		cfacade.IDiscovery
+		sync.Mutex
+		members map[string]cfacade.IMember
+	}
+
+	testSystem struct {
+		cfacade.IActorSystem
+		messages chan *cfacade.Message
+	}
+)
+
+func (p *testApp) NodeId() string                                { return p.nodeID }
+func (p *testApp) RpcAddress() string                            { return "127.0.0.1:0" }
+func (p *testApp) Running() bool                                 { return true }
+func (p *testApp) Discovery() cfacade.IDiscovery                 { return p.discovery }
+func (p *testApp) ActorSystem() cfacade.IActorSystem             { return p.system }
+func (p *testDiscovery) OnRemoveMember(_ cfacade.MemberListener) {}
+
+func (p *testDiscovery) GetMember(nodeID string) (cfacade.IMember, bool) {
+	p.Lock()
+	defer p.Unlock()
+	member, found := p.members[nodeID]
+	return member, found
+}
+
+func (p *testSystem) PostLocal(m *cfacade.Message) int32 {
+	p.messages <- m
+	return ccode.OK
+}
+
+func (p *testSystem) PostRemote(m *cfacade.Message) int32 {
+	// 模拟投递失败(邮箱已满等),不回复
+	if m.FuncName == "full" {
+		return ccode.ActorMailboxFull
+	}
+
+	if m.ClusterReply != nil {
+		data, _ := proto.Marshal(&cproto.Response{Code: ccode.OK, Data: []byte(m.FuncName)})
+		m.ClusterReply.Respond(data)
+		return ccode.OK
+	}
+
+	p.messages <- m
+	return ccode.OK
+}
+
+func newTestClusters(t *testing.T, nodeIDs ...string) map[string]*Cluster {
+	discovery := &testDiscovery{members: make(map[string]cfacade.IMember)}
+	clusters := make(map[string]*Cluster)
+
+	for _, nodeID := range nodeIDs {
+		app := &testApp{
+			nodeID:    nodeID,
+			discovery: discovery,
+			system:    &testSystem{messages: make(chan *cfacade.Message, 16)},
+		}
+
+		cluster := newCluster(app)
+		WithPoolSize(2)(cluster)
+		cluster.Init()
+		t.Cleanup(cluster.Stop)
+
+		discovery.members[nodeID] = &cproto.Member{
+			NodeId:  nodeID,
+			Address: cluster.Addr().String(),
+		}
+		clusters[nodeID] = cluster
+	}
+
+	return clusters
+}
+
+func receive(t *testing.T, cluster *Cluster) *cfacade.Message {
+	select {
+	case m := <-cluster.app.(*testApp).system.messages:
+		return m
+	case <-time.After(3 * time.Second):
+		t.Fatal("receive timeout")
+	}
+	return nil
+}
+
+func TestPublish(t *testing.T) {
+	clusters := newTestClusters(t, "n1", "n2")
+
+	err := clusters["n1"].PublishRemote("n2", &cproto.ClusterPacket{
+		SourcePath:  "n1.a",
+		TargetPath:  "n2.b",
+		FuncName:    "remote",
+		ArgBytes:    []byte("arg"),
+		TraceParent: "tp",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := receive(t, clusters["n2"])
+	if m.FuncName != "remote" || string(m.Args.([]byte)) != "arg" || m.TraceParent != "tp" || !m.IsCluster {
+		t.Fatalf("message = %+v", m)
+	}
+
+	err = clusters["n1"].PublishLocal("n2", &cproto.ClusterPacket{
+		TargetPath: "n2.b",
+		FuncName:   "local",
+		Session:    &cproto.Session{Sid: "sid"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m = receive(t, clusters["n2"])
+	if m.FuncName != "local" || m.Session.GetSid() != "sid" {
+		t.Fatalf("message = %+v", m)
+	}
+}
+
+func TestRequestRemote(t *testing.T) {
+	clusters := newTestClusters(t, "n1", "n2")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			rsp := clusters["n1"].RequestRemote("n2", &cproto.ClusterPacket{FuncName: "echo"})
+			if rsp.Code != ccode.OK || string(rsp.Data) != "echo" {
+				t.Errorf("rsp = %+v", &rsp)
+			}
+		}()
+	}
+	wg.Wait()
+
+	rsp := clusters["n1"].RequestRemote("n3", &cproto.ClusterPacket{FuncName: "echo"})
+	if rsp.Code != ccode.DiscoveryNotFoundNode {
+		t.Fatalf("code = %d", rsp.Code)
+	}
+
+	// 目标节点投递失败时立即返回错误码
+	begin := time.Now()
+	rsp = clusters["n1"].RequestRemote("n2", &cproto.ClusterPacket{FuncName: "full"})
+	if rsp.Code != ccode.ActorMailboxFull || time.Since(begin) > time.Second {
+		t.Fatalf("code = %d, elapsed = %s", rsp.Code, time.Since(begin))
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	clusters := newTestClusters(t, "n1", "n2")
+	WithReconnectDelay(0)(clusters["n1"])
+
+	if rsp := clusters["n1"].RequestRemote("n2", &cproto.ClusterPacket{FuncName: "echo"}); rsp.Code != ccode.OK {
+		t.Fatalf("code = %d", rsp.Code)
+	}
+
+	// 服务端断开所有连接
+	clusters["n2"].accepted.Range(func(key, _ any) bool {
+		key.(*conn).close()
+		return true
+	})
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		rsp := clusters["n1"].RequestRemote("n2", &cproto.ClusterPacket{FuncName: "echo"}, time.Second)
+		if rsp.Code == ccode.OK {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("reconnect fail. code = %d", rsp.Code)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWriteTimeout(t *testing.T) {
+	// 对端不读取数据,写缓冲区满后写超时并关闭连接
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go func() {
+		if c, acceptErr := listener.Accept(); acceptErr == nil {
+			defer c.Close()
+			time.Sleep(3 * time.Second)
+		}
+	}()
+
+	netConn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cluster := newCluster(&testApp{})
+	WithWriteTimeout(50 * time.Millisecond)(cluster)
+	c := newConn(cluster, netConn, nil)
+
+	payload := make([]byte, 64*1024)
+	deadline := time.Now().Add(2 * time.Second)
+	for c.write(remoteFrame, 0, payload) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("write should time out")
+		}
+	}
+
+	if !c.isClosed() {
+		t.Fatal("connection should be closed after write timeout")
+	}
+}
@@ -0,0 +1,138 @@
+package cherryTCPCluster
+
+import (
+	"bufio"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cerr "github.com/cherry-game/cherry/error"
+	clog "github.com/cherry-game/cherry/logger"
+)
+
+var (
+	errConnClosed = cerr.Error("cluster connection closed")
+)
+
+type (
+	// conn 节点间的连接,同一连接上可同时发送多个请求,通过seq关联返回
+	conn struct {
+		net.Conn
+		cluster *Cluster
+		writeMu sync.Mutex
+		seq     uint64
+		pending sync.Map // key:seq, value:chan []byte
+		closed  int32
+		closeFn func(c *conn) // 连接关闭时触发
+		reader  *bufio.Reader
+	}
+
+	// reply 返回请求结果(实现cfacade.IRespond),只返回一次
+	reply struct {
+		conn    *conn
+		seq     uint64
+		replied int32
+	}
+)
+
+func newConn(cluster *Cluster, netConn net.Conn, closeFn func(c *conn)) *conn {
+	return &conn{
+		Conn:    netConn,
+		cluster: cluster,
+		closeFn: closeFn,
+		reader:  bufio.NewReader(netConn),
+	}
+}
+
+// readLoop 读取数据帧,返回帧交给等待的请求,其他帧投递到actor system
+func (p *conn) readLoop() {
+	defer p.close()
+
+	for {
+		f, err := readFrame(p.reader)
+		if err != nil {
+			if !p.isClosed() {
+				clog.Debugf("[readLoop] Read frame fail. [remote = %s, err = %v]", p.RemoteAddr(), err)
+			}
+			return
+		}
+
+		if f.typ == responseFrame {
+			if value, found := p.pending.LoadAndDelete(f.seq); found {
+				value.(chan []byte) <- f.payload
+			}
+			continue
+		}
+
+		p.cluster.dispatch(p, f)
+	}
+}
+
+func (p *conn) write(typ byte, seq uint64, payload []byte) error {
+	if p.isClosed() {
+		return errConnClosed
+	}
+
+	data := encodeFrame(typ, seq, payload)
+
+	// 设置写超时,避免对端阻塞时所有发送方一直等待
+	p.writeMu.Lock()
+	if p.cluster.writeTimeout > 0 {
+		_ = p.SetWriteDeadline(time.Now().Add(p.cluster.writeTimeout))
+	}
+	_, err := p.Write(data)
+	p.writeMu.Unlock()
+
+	if err != nil {
+		clog.Warnf("[conn] Write fail, connection will be closed. [remote = %s, err = %v]", p.RemoteAddr(), err)
+		p.close()
+	}
+
+	return err
+}
+
+// request 发送请求,返回等待结果的chan及seq
+func (p *conn) request(payload []byte) (uint64, chan []byte, error) {
+	seq := atomic.AddUint64(&p.seq, 1)
+	ch := make(chan []byte, 1)
+	p.pending.Store(seq, ch)
+
+	if err := p.write(requestFrame, seq, payload); err != nil {
+		p.pending.Delete(seq)
+		return seq, nil, err
+	}
+
+	return seq, ch, nil
+}
+
+func (p *conn) isClosed() bool {
+	return atomic.LoadInt32(&p.closed) == 1
+}
+
+// close 关闭连接,等待中的请求返回nil
+func (p *conn) close() {
+	if !atomic.CompareAndSwapInt32(&p.closed, 0, 1) {
+		return
+	}
+
+	p.Conn.Close()
+
+	p.pending.Range(func(key, _ any) bool {
+		if value, found := p.pending.LoadAndDelete(key); found {
+			value.(chan []byte) <- nil
+		}
+		return true
+	})
+
+	if p.closeFn != nil {
+		p.closeFn(p)
+	}
+}
+
+func (p *reply) Respond(data []byte) error {
+	if !atomic.CompareAndSwapInt32(&p.replied, 0, 1) {
+		return nil
+	}
+	return p.conn.write(responseFrame, p.seq, data)
+}
@@ -0,0 +1,70 @@
+package cherryTCPCluster
+
+import (
+	"encoding/binary"
+	"io"
+
+	cerr "github.com/cherry-game/cherry/error"
+)
+
+const (
+	localFrame    byte = 1 // 本地消息(PublishLocal)
+	remoteFrame   byte = 2 // 远程消息(PublishRemote)
+	requestFrame  byte = 3 // 远程请求(RequestRemote)
+	responseFrame byte = 4 // 远程请求的返回
+)
+
+const (
+	frameHeadSize = 4 + 1 + 8    // length + type + seq
+	maxFrameSize  = 16 * 1 << 20 // 单帧最大16MB
+)
+
+var (
+	errInvalidFrame = cerr.Error("invalid cluster frame length")
+)
+
+type (
+	// frame 节点间传输的数据帧
+	// | length(4) | type(1) | seq(8) | payload |, length = type + seq + payload
+	// payload为ClusterPacket(local/remote/request)或Response(response)的protobuf数据
+	frame struct {
+		typ     byte
+		seq     uint64
+		payload []byte
+	}
+)
+
+func encodeFrame(typ byte, seq uint64, payload []byte) []byte {
+	buf := make([]byte, frameHeadSize+len(payload))
+	binary.BigEndian.PutUint32(buf, uint32(1+8+len(payload)))
+	buf[4] = typ
+	binary.BigEndian.PutUint64(buf[5:], seq)
+	copy(buf[frameHeadSize:], payload)
+	return buf
+}
+
+func readFrame(reader io.Reader) (*frame, error) {
+	head := make([]byte, frameHeadSize)
+	if _, err := io.ReadFull(reader, head); err != nil {
+		return nil, err
+	}
+
+	length := binary.BigEndian.Uint32(head)
+	if length < 1+8 || length > maxFrameSize {
+		return nil, errInvalidFrame
+	}
+
+	f := &frame{
+		typ: head[4],
+		seq: binary.BigEndian.Uint64(head[5:]),
+	}
+
+	if size := int(length) - 1 - 8; size > 0 {
+		f.payload = make([]byte, size)
+		if _, err := io.ReadFull(reader, f.payload); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
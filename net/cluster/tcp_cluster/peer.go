@@ -0,0 +1,167 @@
+package cherryTCPCluster
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	cerr "github.com/cherry-game/cherry/error"
+	clog "github.com/cherry-game/cherry/logger"
+)
+
+var (
+	errReconnectWaiting = cerr.Error("cluster peer is waiting to reconnect")
+)
+
+type (
+	// peer 到某个节点的连接池,连接断开后在下次使用时重连
+	// 重连在锁外执行,同一位置同时只有一个goroutine重连,其他调用方使用池中可用的连接或等待重连结果
+	peer struct {
+		sync.Mutex
+		cluster *Cluster
+		nodeID  string
+		address string
+		conns   []*conn         // 连接池
+		dialing []chan struct{} // 正在重连的位置,重连结束后关闭
+		index   int             // 轮询索引
+		retryAt time.Time       // 连接失败后,到达该时间前不再重连
+		closed  bool
+	}
+)
+
+func newPeer(cluster *Cluster, nodeID, address string) *peer {
+	return &peer{
+		cluster: cluster,
+		nodeID:  nodeID,
+		address: address,
+		conns:   make([]*conn, cluster.poolSize),
+		dialing: make([]chan struct{}, cluster.poolSize),
+	}
+}
+
+// get 轮询获取一个可用的连接,断开的连接在轮询到时重连,重连失败则使用池中其他可用的连接
+func (p *peer) get() (*conn, error) {
+	p.Lock()
+
+	if p.closed {
+		p.Unlock()
+		return nil, errConnClosed
+	}
+
+	p.index = (p.index + 1) % len(p.conns)
+	index := p.index
+	if c := p.conns[index]; c != nil && !c.isClosed() {
+		p.Unlock()
+		return c, nil
+	}
+
+	// 其他goroutine正在重连该位置
+	if done := p.dialing[index]; done != nil {
+		c := p.available()
+		p.Unlock()
+
+		if c != nil {
+			return c, nil
+		}
+
+		<-done
+		return p.connAt(index)
+	}
+
+	if time.Now().Before(p.retryAt) {
+		c := p.available()
+		p.Unlock()
+
+		if c != nil {
+			return c, nil
+		}
+		return nil, errReconnectWaiting
+	}
+
+	p.dialing[index] = make(chan struct{})
+	p.Unlock()
+
+	c, err := p.dial(index)
+	if err == nil {
+		return c, nil
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	if c = p.available(); c != nil {
+		return c, nil
+	}
+	return nil, err
+}
+
+// connAt 获取指定位置的连接,不可用时使用池中其他可用的连接
+func (p *peer) connAt(index int) (*conn, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.closed {
+		return nil, errConnClosed
+	}
+
+	if c := p.conns[index]; c != nil && !c.isClosed() {
+		return c, nil
+	}
+
+	if c := p.available(); c != nil {
+		return c, nil
+	}
+	return nil, errReconnectWaiting
+}
+
+// available 池中任意一个可用的连接,调用方需持有锁
+func (p *peer) available() *conn {
+	for _, c := range p.conns {
+		if c != nil && !c.isClosed() {
+			return c
+		}
+	}
+	return nil
+}
+
+// dial 在锁外建立连接,结束后通知等待该位置的调用方
+func (p *peer) dial(index int) (*conn, error) {
+	netConn, err := net.DialTimeout("tcp", p.address, p.cluster.dialTimeout)
+
+	p.Lock()
+	defer p.Unlock()
+
+	close(p.dialing[index])
+	p.dialing[index] = nil
+
+	if err != nil {
+		p.retryAt = time.Now().Add(p.cluster.reconnectDelay)
+		clog.Warnf("[peer] Dial fail. [nodeID = %s, address = %s, err = %v]", p.nodeID, p.address, err)
+		return nil, err
+	}
+
+	if p.closed {
+		netConn.Close()
+		return nil, errConnClosed
+	}
+
+	c := newConn(p.cluster, netConn, nil)
+	p.conns[index] = c
+	go c.readLoop()
+
+	clog.Infof("[peer] Connected. [nodeID = %s, address = %s, index = %d]", p.nodeID, p.address, index)
+
+	return c, nil
+}
+
+func (p *peer) close() {
+	p.Lock()
+	defer p.Unlock()
+
+	p.closed = true
+	for _, c := range p.conns {
+		if c != nil {
+			c.close()
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package cherryDiscovery
 
 import (
+	"math/rand"
+
 	cfacade "github.com/cherry-game/cherry/facade"
 	clog "github.com/cherry-game/cherry/logger"
 	cprofile "github.com/cherry-game/cherry/profile"
@@ -42,11 +44,36 @@ func (p *Component) Init() {
 		return
 	}
 
+	if factory, ok := discovery.(INewDiscovery); ok {
+		discovery = factory.NewDiscovery()
+	}
+
 	clog.Infof("Select discovery [mode = %s].", mode)
 	p.IDiscovery = discovery
 	p.IDiscovery.Load(p.App())
 }
 
+// Random 根据节点类型随机一个,跳过集群熔断中的节点(全部熔断时不过滤)
+func (p *Component) Random(nodeType string) (cfacade.IMember, bool) {
+	checker, ok := p.App().Cluster().(cfacade.IAvailable)
+	if !ok {
+		return p.IDiscovery.Random(nodeType)
+	}
+
+	var memberList []cfacade.IMember
+	for _, member := range p.ListByType(nodeType) {
+		if checker.Available(member.GetNodeId()) {
+			memberList = append(memberList, member)
+		}
+	}
+
+	if len(memberList) < 1 {
+		return p.IDiscovery.Random(nodeType)
+	}
+
+	return memberList[rand.Intn(len(memberList))], true
+}
+
 func (p *Component) OnStop() {
 	p.IDiscovery.Stop()
 }
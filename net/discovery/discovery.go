@@ -9,6 +9,13 @@ var (
 	discoveryMap = make(map[string]cfacade.IDiscovery)
 )
 
+type (
+	// INewDiscovery 每个application需要独立实例的发现服务(如进程内的loopback),Init时创建新实例
+	INewDiscovery interface {
+		NewDiscovery() cfacade.IDiscovery
+	}
+)
+
 func init() {
 	Register(&DiscoveryDefault{})
 	Register(&DiscoveryNATS{})
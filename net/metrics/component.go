@@ -0,0 +1,90 @@
+package cherryMetrics
+
+import (
+	"errors"
+	"net/http"
+
+	cfacade "github.com/cherry-game/cherry/facade"
+	clog "github.com/cherry-game/cherry/logger"
+)
+
+const (
+	Name        = "metrics_component"
+	DefaultPath = "/metrics"
+)
+
+type (
+	// Component 通过http输出Prometheus格式的指标
+	Component struct {
+		cfacade.Component
+		address  string
+		path     string
+		registry *Registry
+		server   *http.Server
+	}
+
+	// IMetricsEnabler 实现该接口的actor系统会在组件初始化时开启指标采集
+	IMetricsEnabler interface {
+		EnableMetrics(registry *Registry)
+	}
+)
+
+// NewComponent 创建指标组件,address为http监听地址(如 :9100),registry为空时使用DefaultRegistry
+func NewComponent(address string, registry ...*Registry) *Component {
+	component := &Component{
+		address:  address,
+		path:     DefaultPath,
+		registry: DefaultRegistry,
+	}
+
+	if len(registry) > 0 && registry[0] != nil {
+		component.registry = registry[0]
+	}
+
+	return component
+}
+
+func (c *Component) Name() string {
+	return Name
+}
+
+// SetPath 设置输出指标的http路径,默认为 /metrics
+func (c *Component) SetPath(path string) {
+	if path != "" {
+		c.path = path
+	}
+}
+
+// Registry 指标注册表
+func (c *Component) Registry() *Registry {
+	return c.registry
+}
+
+func (c *Component) Init() {
+	if enabler, ok := c.App().ActorSystem().(IMetricsEnabler); ok {
+		enabler.EnableMetrics(c.registry)
+	}
+}
+
+func (c *Component) OnAfterInit() {
+	mux := http.NewServeMux()
+	mux.Handle(c.path, c.registry.Handler())
+
+	c.server = &http.Server{
+		Addr:    c.address,
+		Handler: mux,
+	}
+
+	go func() {
+		clog.Infof("[metrics] Listen on %s%s", c.address, c.path)
+		if err := c.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			clog.Warnf("[metrics] Listen error. [address = %s, err = %v]", c.address, err)
+		}
+	}()
+}
+
+func (c *Component) OnStop() {
+	if c.server != nil {
+		c.server.Close()
+	}
+}
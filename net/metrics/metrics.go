@@ -0,0 +1,419 @@
+package cherryMetrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	labelSeparator = "\xff"
+	contentType    = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+var (
+	// DefaultRegistry 默认的指标注册表
+	DefaultRegistry = NewRegistry()
+
+	// DefaultBuckets 默认的直方图区间(秒)
+	DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+)
+
+type (
+	// ICollector 指标收集器,以Prometheus文本格式输出
+	ICollector interface {
+		Name() string
+		Write(w io.Writer)
+	}
+
+	// Registry 指标注册表
+	Registry struct {
+		sync.RWMutex
+		collectors map[string]ICollector
+	}
+
+	meta struct {
+		name   string
+		help   string
+		typ    string
+		labels []string
+	}
+
+	// CounterVec 计数器
+	CounterVec struct {
+		meta
+		series sync.Map // key:label values, value:*counter
+	}
+
+	counter struct {
+		labels []string
+		bits   uint64
+	}
+
+	// GaugeVec 仪表盘
+	GaugeVec struct {
+		meta
+		series sync.Map // key:label values, value:*counter
+	}
+
+	// GaugeFunc 在输出时通过函数采集的仪表盘
+	GaugeFunc struct {
+		meta
+		fn func(report func(value float64, labelValues ...string))
+	}
+
+	// HistogramVec 直方图
+	HistogramVec struct {
+		meta
+		buckets []float64
+		series  sync.Map // key:label values, value:*histogram
+	}
+
+	histogram struct {
+		sync.Mutex
+		labels []string
+		counts []uint64 // 每个区间的数量(不累加)
+		sum    float64
+		count  uint64
+	}
+)
+
+func NewRegistry() *Registry {
+	return &Registry{
+		collectors: make(map[string]ICollector),
+	}
+}
+
+// Register 注册收集器,同名的收集器会被替换
+func (p *Registry) Register(collectors ...ICollector) {
+	p.Lock()
+	defer p.Unlock()
+
+	for _, collector := range collectors {
+		p.collectors[collector.Name()] = collector
+	}
+}
+
+// Unregister 注销收集器
+func (p *Registry) Unregister(name string) {
+	p.Lock()
+	defer p.Unlock()
+
+	delete(p.collectors, name)
+}
+
+// WriteText 以Prometheus文本格式输出所有指标
+func (p *Registry) WriteText(w io.Writer) error {
+	p.RLock()
+	names := make([]string, 0, len(p.collectors))
+	for name := range p.collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	collectors := make([]ICollector, 0, len(names))
+	for _, name := range names {
+		collectors = append(collectors, p.collectors[name])
+	}
+	p.RUnlock()
+
+	writer := bufio.NewWriter(w)
+	for _, collector := range collectors {
+		collector.Write(writer)
+	}
+
+	return writer.Flush()
+}
+
+// Handler 返回输出指标的http handler
+func (p *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		p.WriteText(w)
+	})
+}
+
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{
+		meta: meta{name: name, help: help, typ: "counter", labels: labels},
+	}
+}
+
+// Inc 计数+1
+func (p *CounterVec) Inc(labelValues ...string) {
+	p.Add(1, labelValues...)
+}
+
+// Add 计数增加v(v需大于0)
+func (p *CounterVec) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+	loadCounter(&p.series, labelValues).add(v)
+}
+
+// Value 获取当前计数
+func (p *CounterVec) Value(labelValues ...string) float64 {
+	return loadCounter(&p.series, labelValues).value()
+}
+
+func (p *CounterVec) Write(w io.Writer) {
+	p.writeHeader(w)
+	writeCounters(w, p.name, p.labels, &p.series)
+}
+
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{
+		meta: meta{name: name, help: help, typ: "gauge", labels: labels},
+	}
+}
+
+// Set 设置当前值
+func (p *GaugeVec) Set(v float64, labelValues ...string) {
+	atomic.StoreUint64(&loadCounter(&p.series, labelValues).bits, math.Float64bits(v))
+}
+
+// Add 增加v(可为负数)
+func (p *GaugeVec) Add(v float64, labelValues ...string) {
+	loadCounter(&p.series, labelValues).add(v)
+}
+
+// Value 获取当前值
+func (p *GaugeVec) Value(labelValues ...string) float64 {
+	return loadCounter(&p.series, labelValues).value()
+}
+
+func (p *GaugeVec) Write(w io.Writer) {
+	p.writeHeader(w)
+	writeCounters(w, p.name, p.labels, &p.series)
+}
+
+// NewGaugeFunc 创建在输出时采集的仪表盘,fn中调用report上报每组label的值
+func NewGaugeFunc(name, help string, fn func(report func(value float64, labelValues ...string)), labels ...string) *GaugeFunc {
+	return &GaugeFunc{
+		meta: meta{name: name, help: help, typ: "gauge", labels: labels},
+		fn:   fn,
+	}
+}
+
+func (p *GaugeFunc) Write(w io.Writer) {
+	p.writeHeader(w)
+
+	values := make(map[string]float64)
+	p.fn(func(value float64, labelValues ...string) {
+		values[strings.Join(labelValues, labelSeparator)] += value
+	})
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		writeSample(w, p.name, p.labels, splitKey(key), "", "", values[key])
+	}
+}
+
+// NewHistogramVec 创建直方图,buckets为空时使用DefaultBuckets
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) < 1 {
+		buckets = DefaultBuckets
+	}
+
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	return &HistogramVec{
+		meta:    meta{name: name, help: help, typ: "histogram", labels: labels},
+		buckets: sorted,
+	}
+}
+
+// Observe 记录一个观测值
+func (p *HistogramVec) Observe(v float64, labelValues ...string) {
+	key := strings.Join(labelValues, labelSeparator)
+	value, found := p.series.Load(key)
+	if !found {
+		value, _ = p.series.LoadOrStore(key, &histogram{
+			labels: append([]string(nil), labelValues...),
+			counts: make([]uint64, len(p.buckets)+1),
+		})
+	}
+
+	h := value.(*histogram)
+	index := sort.SearchFloat64s(p.buckets, v)
+
+	h.Lock()
+	h.counts[index]++
+	h.sum += v
+	h.count++
+	h.Unlock()
+}
+
+// Count 获取观测值的数量
+func (p *HistogramVec) Count(labelValues ...string) uint64 {
+	value, found := p.series.Load(strings.Join(labelValues, labelSeparator))
+	if !found {
+		return 0
+	}
+
+	h := value.(*histogram)
+	h.Lock()
+	defer h.Unlock()
+
+	return h.count
+}
+
+func (p *HistogramVec) Write(w io.Writer) {
+	p.writeHeader(w)
+
+	for _, key := range sortedKeys(&p.series) {
+		value, _ := p.series.Load(key)
+		h := value.(*histogram)
+
+		h.Lock()
+		counts := append([]uint64(nil), h.counts...)
+		sum, count := h.sum, h.count
+		h.Unlock()
+
+		var cumulative uint64
+		for i, bound := range p.buckets {
+			cumulative += counts[i]
+			writeSample(w, p.name+"_bucket", p.labels, h.labels, "le", formatFloat(bound), float64(cumulative))
+		}
+
+		writeSample(w, p.name+"_bucket", p.labels, h.labels, "le", "+Inf", float64(count))
+		writeSample(w, p.name+"_sum", p.labels, h.labels, "", "", sum)
+		writeSample(w, p.name+"_count", p.labels, h.labels, "", "", float64(count))
+	}
+}
+
+func (p *meta) Name() string {
+	return p.name
+}
+
+func (p *meta) writeHeader(w io.Writer) {
+	io.WriteString(w, "# HELP "+p.name+" "+strings.ReplaceAll(p.help, "\n", " ")+"\n")
+	io.WriteString(w, "# TYPE "+p.name+" "+p.typ+"\n")
+}
+
+func (p *counter) add(v float64) {
+	for {
+		old := atomic.LoadUint64(&p.bits)
+		value := math.Float64bits(math.Float64frombits(old) + v)
+		if atomic.CompareAndSwapUint64(&p.bits, old, value) {
+			return
+		}
+	}
+}
+
+func (p *counter) value() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&p.bits))
+}
+
+func loadCounter(series *sync.Map, labelValues []string) *counter {
+	key := strings.Join(labelValues, labelSeparator)
+	if value, found := series.Load(key); found {
+		return value.(*counter)
+	}
+
+	value, _ := series.LoadOrStore(key, &counter{
+		labels: append([]string(nil), labelValues...),
+	})
+	return value.(*counter)
+}
+
+func writeCounters(w io.Writer, name string, labels []string, series *sync.Map) {
+	for _, key := range sortedKeys(series) {
+		value, _ := series.Load(key)
+		c := value.(*counter)
+		writeSample(w, name, labels, c.labels, "", "", c.value())
+	}
+}
+
+func writeSample(w io.Writer, name string, labels, labelValues []string, extraLabel, extraValue string, value float64) {
+	var builder strings.Builder
+	builder.WriteString(name)
+
+	pairs := 0
+	writePair := func(label, labelValue string) {
+		if pairs == 0 {
+			builder.WriteByte('{')
+		} else {
+			builder.WriteByte(',')
+		}
+		builder.WriteString(label)
+		builder.WriteString(`="`)
+		builder.WriteString(escapeLabel(labelValue))
+		builder.WriteByte('"')
+		pairs++
+	}
+
+	for i, label := range labels {
+		labelValue := ""
+		if i < len(labelValues) {
+			labelValue = labelValues[i]
+		}
+		writePair(label, labelValue)
+	}
+
+	if extraLabel != "" {
+		writePair(extraLabel, extraValue)
+	}
+
+	if pairs > 0 {
+		builder.WriteByte('}')
+	}
+
+	builder.WriteByte(' ')
+	builder.WriteString(formatFloat(value))
+	builder.WriteByte('\n')
+
+	io.WriteString(w, builder.String())
+}
+
+func sortedKeys(series *sync.Map) []string {
+	var keys []string
+	series.Range(func(key, _ any) bool {
+		keys = append(keys, key.(string))
+		return true
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+func splitKey(key string) []string {
+	if key == "" {
+		return nil
+	}
+	return strings.Split(key, labelSeparator)
+}
+
+func escapeLabel(value string) string {
+	if !strings.ContainsAny(value, "\\\"\n") {
+		return value
+	}
+
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return strings.ReplaceAll(value, "\n", `\n`)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
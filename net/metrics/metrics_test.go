@@ -0,0 +1,47 @@
+package cherryMetrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	registry := NewRegistry()
+
+	counter := NewCounterVec("test_messages_total", "Messages.", "mailbox")
+	counter.Inc("local")
+	counter.Add(2, "local")
+
+	histogram := NewHistogramVec("test_latency_seconds", "Latency.", []float64{0.1, 1}, "func")
+	histogram.Observe(0.05, "login")
+	histogram.Observe(0.5, "login")
+	histogram.Observe(5, "login")
+
+	gauge := NewGaugeFunc("test_actors", "Actors.", func(report func(value float64, labelValues ...string)) {
+		report(1, "worker")
+		report(1, "worker")
+	}, "state")
+
+	registry.Register(counter, histogram, gauge)
+
+	buf := &bytes.Buffer{}
+	if err := registry.WriteText(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	text := buf.String()
+	for _, line := range []string{
+		"# TYPE test_messages_total counter",
+		`test_messages_total{mailbox="local"} 3`,
+		`test_latency_seconds_bucket{func="login",le="0.1"} 1`,
+		`test_latency_seconds_bucket{func="login",le="1"} 2`,
+		`test_latency_seconds_bucket{func="login",le="+Inf"} 3`,
+		`test_latency_seconds_count{func="login"} 3`,
+		`test_actors{state="worker"} 2`,
+	} {
+		if !strings.Contains(text, line+"\n") {
+			t.Fatalf("missing line %q in:\n%s", line, text)
+		}
+	}
+}
@@ -5,12 +5,19 @@ import (
 	clog "github.com/cherry-game/cherry/logger"
 	pmessage "github.com/cherry-game/cherry/net/parser/pomelo/message"
 	cproto "github.com/cherry-game/cherry/net/proto"
+	ctrace "github.com/cherry-game/cherry/net/trace"
 )
 
 // DefaultDataRoute 默认的消息路由
 func DefaultDataRoute(agent *Agent, route *pmessage.Route, msg *pmessage.Message) {
 	session := BuildSession(agent, msg)
 
+	// 每个客户端请求作为trace的起点
+	span := ctrace.StartSpan("agent "+msg.Route, ctrace.ServerSpan, "")
+	span.SetAttribute("sid", session.Sid)
+	defer span.End()
+	session.TraceParent = span.TraceParent()
+
 	// current node
 	if agent.NodeType() == route.NodeType() {
 		targetPath := cfacade.NewChildPath(agent.NodeId(), route.HandleName(), session.Sid)
@@ -27,7 +34,12 @@ func DefaultDataRoute(agent *Agent, route *pmessage.Route, msg *pmessage.Message
 		return
 	}
 
-	member, found := agent.Discovery().Random(route.NodeType())
+	// 优先转发到session绑定的节点
+	member, found := agent.Discovery().GetMember(session.GetRoute(route.NodeType()))
+	if !found {
+		member, found = agent.Discovery().Random(route.NodeType())
+	}
+
 	if !found {
 		return
 	}
@@ -51,6 +63,7 @@ func LocalDataRoute(agent *Agent, session *cproto.Session, route *pmessage.Route
 	message.FuncName = route.Method()
 	message.Session = session
 	message.Args = msg.Data
+	message.TraceParent = session.TraceParent
 
 	agent.ActorSystem().PostLocal(&message)
 }
@@ -62,6 +75,7 @@ func ClusterLocalDataRoute(agent *Agent, session *cproto.Session, route *pmessag
 	clusterPacket.FuncName = route.Method()
 	clusterPacket.Session = session   // agent session
 	clusterPacket.ArgBytes = msg.Data // packet -> message -> data
+	clusterPacket.TraceParent = session.TraceParent
 
 	return agent.Cluster().PublishLocal(nodeID, clusterPacket)
 }
@@ -4,6 +4,7 @@ import (
 	cfacade "github.com/cherry-game/cherry/facade"
 	clog "github.com/cherry-game/cherry/logger"
 	cproto "github.com/cherry-game/cherry/net/proto"
+	ctrace "github.com/cherry-game/cherry/net/trace"
 )
 
 var (
@@ -38,6 +39,12 @@ func DefaultDataRoute(agent *Agent, msg *Message, route *NodeRoute) {
 	session := agent.session
 	session.Mid = msg.MID
 
+	// 每个客户端请求作为trace的起点
+	span := ctrace.StartSpan("agent "+route.ActorID+"."+route.FuncName, ctrace.ServerSpan, "")
+	span.SetAttribute("sid", session.Sid)
+	defer span.End()
+	session.TraceParent = span.TraceParent()
+
 	// current node
 	if agent.NodeType() == route.NodeType {
 		targetPath := cfacade.NewChildPath(agent.NodeId(), route.ActorID, session.Sid)
@@ -54,7 +61,12 @@ func DefaultDataRoute(agent *Agent, msg *Message, route *NodeRoute) {
 		return
 	}
 
-	member, found := agent.Discovery().Random(route.NodeType)
+	// 优先转发到session绑定的节点
+	member, found := agent.Discovery().GetMember(session.GetRoute(route.NodeType))
+	if !found {
+		member, found = agent.Discovery().Random(route.NodeType)
+	}
+
 	if !found {
 		return
 	}
@@ -70,6 +82,7 @@ func LocalDataRoute(agent *Agent, session *cproto.Session, msg *Message, nodeRou
 	message.FuncName = nodeRoute.FuncName
 	message.Session = session
 	message.Args = msg.Data
+	message.TraceParent = session.TraceParent
 
 	agent.ActorSystem().PostLocal(&message)
 }
@@ -81,6 +94,7 @@ func ClusterLocalDataRoute(agent *Agent, session *cproto.Session, msg *Message,
 	clusterPacket.FuncName = nodeRoute.FuncName
 	clusterPacket.Session = session   // agent session
 	clusterPacket.ArgBytes = msg.Data // packet -> message -> data
+	clusterPacket.TraceParent = session.TraceParent
 
 	return agent.Cluster().PublishLocal(nodeID, clusterPacket)
 }
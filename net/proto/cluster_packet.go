@@ -35,6 +35,8 @@ func (x *ClusterPacket) Recycle() {
 	x.FuncName = ""
 	x.ArgBytes = nil
 	x.Session = nil
+	x.TraceParent = ""
+	x.MessageId = ""
 	clusterPacketPool.Put(x)
 }
 
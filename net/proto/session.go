@@ -5,6 +5,10 @@ import (
 	cstring "github.com/cherry-game/cherry/extend/string"
 )
 
+const (
+	routeKeyPrefix = "route:" // 保存session在某个节点类型下绑定的节点id
+)
+
 func (x *Session) IsBind() bool {
 	return x.Uid > 0
 }
@@ -118,3 +122,13 @@ func (x *Session) GetString(key string) string {
 
 	return v
 }
+
+// GetRoute 获取session在nodeType下绑定的节点id
+func (x *Session) GetRoute(nodeType string) string {
+	return x.GetString(routeKeyPrefix + nodeType)
+}
+
+// SetRoute 设置session在nodeType下绑定的节点id,网关优先转发到该节点
+func (x *Session) SetRoute(nodeType, nodeID string) {
+	x.Set(routeKeyPrefix+nodeType, nodeID)
+}
@@ -0,0 +1,232 @@
+package cherryTrace
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+const (
+	ServiceName = "cherry" // OTLP resource的service.name
+)
+
+type (
+	// StdoutExporter 以文本行的形式输出span
+	StdoutExporter struct {
+		sync.Mutex
+		writer io.Writer
+	}
+
+	// OTLPFileExporter 以OTLP/JSON格式(每行一个ExportTraceServiceRequest)输出span到文件
+	// 可由OpenTelemetry Collector的otlpjsonfile receiver读取
+	OTLPFileExporter struct {
+		sync.Mutex
+		file        *os.File
+		writer      *bufio.Writer
+		serviceName string
+	}
+
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		Kind              SpanKind        `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		Status            otlpStatus      `json:"status"`
+	}
+
+	otlpAttribute struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+
+	otlpValue struct {
+		StringValue string `json:"stringValue"`
+	}
+
+	otlpStatus struct {
+		Code    StatusCode `json:"code,omitempty"`
+		Message string     `json:"message,omitempty"`
+	}
+)
+
+// NewStdoutExporter 创建文本输出的导出器,w为空时输出到os.Stdout
+func NewStdoutExporter(w io.Writer) *StdoutExporter {
+	if w == nil {
+		w = os.Stdout
+	}
+
+	return &StdoutExporter{
+		writer: w,
+	}
+}
+
+func (p *StdoutExporter) Export(span *Span) {
+	span.Lock()
+	line := fmt.Sprintf("[trace] %s trace = %s, span = %s, parent = %s, duration = %s, status = %d%s\n",
+		span.Name,
+		span.Context.TraceIDString(),
+		span.Context.SpanIDString(),
+		parentSpanID(span),
+		span.Duration(),
+		span.StatusCode,
+		formatAttributes(span.Attributes),
+	)
+	span.Unlock()
+
+	p.Lock()
+	defer p.Unlock()
+
+	io.WriteString(p.writer, line)
+}
+
+func (p *StdoutExporter) Close() error {
+	return nil
+}
+
+// NewOTLPFileExporter 创建OTLP/JSON文件导出器,文件以追加方式打开
+func NewOTLPFileExporter(path string, serviceName ...string) (*OTLPFileExporter, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	exporter := &OTLPFileExporter{
+		file:        file,
+		writer:      bufio.NewWriter(file),
+		serviceName: ServiceName,
+	}
+
+	if len(serviceName) > 0 && serviceName[0] != "" {
+		exporter.serviceName = serviceName[0]
+	}
+
+	return exporter, nil
+}
+
+func (p *OTLPFileExporter) Export(span *Span) {
+	span.Lock()
+	request := otlpRequest{
+		ResourceSpans: []otlpResourceSpans{
+			{
+				Resource: otlpResource{
+					Attributes: []otlpAttribute{stringAttribute("service.name", p.serviceName)},
+				},
+				ScopeSpans: []otlpScopeSpans{
+					{
+						Scope: otlpScope{Name: "github.com/cherry-game/cherry"},
+						Spans: []otlpSpan{toOTLPSpan(span)},
+					},
+				},
+			},
+		},
+	}
+	span.Unlock()
+
+	bytes, err := json.Marshal(&request)
+	if err != nil {
+		return
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	p.writer.Write(bytes)
+	p.writer.WriteByte('\n')
+	p.writer.Flush()
+}
+
+func (p *OTLPFileExporter) Close() error {
+	p.Lock()
+	defer p.Unlock()
+
+	p.writer.Flush()
+	return p.file.Close()
+}
+
+func toOTLPSpan(span *Span) otlpSpan {
+	value := otlpSpan{
+		TraceID:           span.Context.TraceIDString(),
+		SpanID:            span.Context.SpanIDString(),
+		ParentSpanID:      parentSpanID(span),
+		Name:              span.Name,
+		Kind:              span.Kind,
+		StartTimeUnixNano: strconv.FormatInt(span.StartTime.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+		Status: otlpStatus{
+			Code:    span.StatusCode,
+			Message: span.StatusMessage,
+		},
+	}
+
+	for _, key := range sortedAttributeKeys(span.Attributes) {
+		value.Attributes = append(value.Attributes, stringAttribute(key, span.Attributes[key]))
+	}
+
+	return value
+}
+
+func stringAttribute(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{StringValue: value}}
+}
+
+func parentSpanID(span *Span) string {
+	if span.ParentSpanID == [8]byte{} {
+		return ""
+	}
+	return SpanContext{SpanID: span.ParentSpanID}.SpanIDString()
+}
+
+func formatAttributes(attributes map[string]string) string {
+	var text string
+	for _, key := range sortedAttributeKeys(attributes) {
+		text += ", " + key + " = " + attributes[key]
+	}
+	return text
+}
+
+func sortedAttributeKeys(attributes map[string]string) []string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,303 @@
+package cherryTrace
+
+import (
+	"context"
+	"encoding/hex"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	HeaderName  = "traceparent" // W3C trace context header
+	version     = "00"
+	sampledFlag = 0x01
+)
+
+const (
+	InternalSpan SpanKind = 1
+	ServerSpan   SpanKind = 2
+	ClientSpan   SpanKind = 3
+	ProducerSpan SpanKind = 4
+	ConsumerSpan SpanKind = 5
+)
+
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+var (
+	exporter   atomic.Value // IExporter
+	sampleRate atomic.Value // float64
+)
+
+type (
+	SpanKind   int
+	StatusCode int
+
+	// SpanContext W3C traceparent: version-traceId-spanId-flags
+	SpanContext struct {
+		TraceID [16]byte
+		SpanID  [8]byte
+		Flags   byte
+	}
+
+	// Span 一次调用的耗时记录
+	Span struct {
+		sync.Mutex
+		Name          string
+		Kind          SpanKind
+		Context       SpanContext
+		ParentSpanID  [8]byte
+		StartTime     time.Time
+		EndTime       time.Time
+		Attributes    map[string]string
+		StatusCode    StatusCode
+		StatusMessage string
+		ended         bool
+	}
+
+	// IExporter span导出
+	IExporter interface {
+		Export(span *Span)
+		Close() error
+	}
+
+	exporterHolder struct {
+		IExporter
+	}
+
+	traceParentKey struct{}
+)
+
+func init() {
+	sampleRate.Store(1.0)
+	exporter.Store(exporterHolder{})
+}
+
+// SetExporter 设置span导出器,为nil时关闭追踪(trace上下文仍会传递)
+func SetExporter(e IExporter) {
+	exporter.Store(exporterHolder{e})
+}
+
+// SetSampleRate 设置新trace的采样率(0~1),子span跟随父span的采样结果
+func SetSampleRate(rate float64) {
+	if rate < 0 {
+		rate = 0
+	}
+	if rate > 1 {
+		rate = 1
+	}
+	sampleRate.Store(rate)
+}
+
+// Enabled 是否已设置导出器
+func Enabled() bool {
+	return getExporter() != nil
+}
+
+func getExporter() IExporter {
+	return exporter.Load().(exporterHolder).IExporter
+}
+
+// StartSpan 创建span.parent为空时创建新的trace(按采样率采样)
+// 未设置导出器或父span未采样时返回nil,nil span的方法均可安全调用
+func StartSpan(name string, kind SpanKind, parent string) *Span {
+	if !Enabled() {
+		return nil
+	}
+
+	span := &Span{
+		Name:      name,
+		Kind:      kind,
+		StartTime: time.Now(),
+	}
+
+	if parentContext, ok := ParseTraceParent(parent); ok {
+		if !parentContext.IsSampled() {
+			return nil
+		}
+
+		span.Context.TraceID = parentContext.TraceID
+		span.Context.Flags = parentContext.Flags
+		span.ParentSpanID = parentContext.SpanID
+	} else {
+		if rand.Float64() >= sampleRate.Load().(float64) {
+			return nil
+		}
+
+		putUint64(span.Context.TraceID[:8], nonZeroUint64())
+		putUint64(span.Context.TraceID[8:], rand.Uint64())
+		span.Context.Flags = sampledFlag
+	}
+
+	putUint64(span.Context.SpanID[:], nonZeroUint64())
+
+	return span
+}
+
+// ChildTraceParent span不为nil时返回span的traceparent,否则返回parent(继续传递上游的trace上下文)
+func ChildTraceParent(span *Span, parent string) string {
+	if span == nil {
+		return parent
+	}
+	return span.TraceParent()
+}
+
+// TraceParent 返回span的W3C traceparent
+func (s *Span) TraceParent() string {
+	if s == nil {
+		return ""
+	}
+	return s.Context.String()
+}
+
+// SetAttribute 设置span的属性
+func (s *Span) SetAttribute(key, value string) {
+	if s == nil {
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.Attributes == nil {
+		s.Attributes = make(map[string]string)
+	}
+	s.Attributes[key] = value
+}
+
+// SetError 标记span执行失败
+func (s *Span) SetError(message string) {
+	if s == nil {
+		return
+	}
+
+	s.Lock()
+	s.StatusCode = StatusError
+	s.StatusMessage = message
+	s.Unlock()
+}
+
+// End 结束span并导出,重复调用无效
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.Lock()
+	if s.ended {
+		s.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	s.Unlock()
+
+	if e := getExporter(); e != nil {
+		e.Export(s)
+	}
+}
+
+// Duration span的耗时
+func (s *Span) Duration() time.Duration {
+	return s.EndTime.Sub(s.StartTime)
+}
+
+// ParseTraceParent 解析W3C traceparent
+func ParseTraceParent(traceParent string) (SpanContext, bool) {
+	var ctx SpanContext
+
+	// 00-{32}-{16}-{2}
+	if len(traceParent) != 55 || traceParent[2] != '-' || traceParent[35] != '-' || traceParent[52] != '-' {
+		return ctx, false
+	}
+
+	if traceParent[:2] == "ff" {
+		return ctx, false
+	}
+
+	if _, err := hex.Decode(ctx.TraceID[:], []byte(traceParent[3:35])); err != nil {
+		return ctx, false
+	}
+
+	if _, err := hex.Decode(ctx.SpanID[:], []byte(traceParent[36:52])); err != nil {
+		return ctx, false
+	}
+
+	var flags [1]byte
+	if _, err := hex.Decode(flags[:], []byte(traceParent[53:55])); err != nil {
+		return ctx, false
+	}
+	ctx.Flags = flags[0]
+
+	if !ctx.IsValid() {
+		return ctx, false
+	}
+
+	return ctx, true
+}
+
+func (c SpanContext) String() string {
+	return version + "-" + c.TraceIDString() + "-" + c.SpanIDString() + "-" + hex.EncodeToString([]byte{c.Flags})
+}
+
+func (c SpanContext) TraceIDString() string {
+	return hex.EncodeToString(c.TraceID[:])
+}
+
+func (c SpanContext) SpanIDString() string {
+	return hex.EncodeToString(c.SpanID[:])
+}
+
+func (c SpanContext) IsSampled() bool {
+	return c.Flags&sampledFlag == sampledFlag
+}
+
+func (c SpanContext) IsValid() bool {
+	return c.TraceID != [16]byte{} && c.SpanID != [8]byte{}
+}
+
+// TraceID 返回traceparent中的trace id,用于日志关联
+func TraceID(traceParent string) string {
+	if ctx, ok := ParseTraceParent(traceParent); ok {
+		return ctx.TraceIDString()
+	}
+	return ""
+}
+
+// WithTraceParent 将traceparent保存到ctx
+func WithTraceParent(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, traceParentKey{}, traceParent)
+}
+
+// FromContext 从ctx中获取traceparent
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	traceParent, _ := ctx.Value(traceParentKey{}).(string)
+	return traceParent
+}
+
+func nonZeroUint64() uint64 {
+	for {
+		if v := rand.Uint64(); v != 0 {
+			return v
+		}
+	}
+}
+
+func putUint64(b []byte, v uint64) {
+	for i := 7; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+}
@@ -0,0 +1,125 @@
+package cherryTrace
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testExporter struct {
+	spans []*Span
+}
+
+func (p *testExporter) Export(span *Span) {
+	p.spans = append(p.spans, span)
+}
+
+func (p *testExporter) Close() error {
+	return nil
+}
+
+func TestParseTraceParent(t *testing.T) {
+	value := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	ctx, ok := ParseTraceParent(value)
+	if !ok || !ctx.IsSampled() {
+		t.Fatalf("parse fail. [ctx = %+v]", ctx)
+	}
+
+	if ctx.String() != value || TraceID(value) != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("format = %s", ctx.String())
+	}
+
+	invalid := []string{
+		"",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01",
+	}
+
+	for _, v := range invalid {
+		if _, ok := ParseTraceParent(v); ok {
+			t.Fatalf("expect invalid. [value = %s]", v)
+		}
+	}
+}
+
+func TestStartSpan(t *testing.T) {
+	if span := StartSpan("disabled", InternalSpan, ""); span != nil {
+		t.Fatal("span should be nil without exporter")
+	}
+
+	exporter := &testExporter{}
+	SetExporter(exporter)
+	defer SetExporter(nil)
+
+	root := StartSpan("root", ServerSpan, "")
+	child := StartSpan("child", ClientSpan, root.TraceParent())
+	child.SetError("fail")
+	child.End()
+	child.End()
+	root.End()
+
+	if len(exporter.spans) != 2 {
+		t.Fatalf("exported spans = %d", len(exporter.spans))
+	}
+
+	if child.Context.TraceID != root.Context.TraceID || child.ParentSpanID != root.Context.SpanID {
+		t.Fatalf("child = %s, root = %s", child.TraceParent(), root.TraceParent())
+	}
+
+	// 未采样的父span不再创建子span,但trace上下文继续传递
+	unsampled := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"
+	span := StartSpan("unsampled", ServerSpan, unsampled)
+	if span != nil || ChildTraceParent(span, unsampled) != unsampled {
+		t.Fatal("unsampled parent should not create span")
+	}
+}
+
+func TestOTLPFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace", "spans.json")
+
+	exporter, err := NewOTLPFileExporter(path, "game")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	SetExporter(exporter)
+	defer SetExporter(nil)
+
+	span := StartSpan("invoke login", ServerSpan, "")
+	span.SetAttribute("actor.path", "game-1.player")
+	span.End()
+
+	if err = exporter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		t.Fatal("no span exported")
+	}
+
+	var request otlpRequest
+	if err = json.Unmarshal(scanner.Bytes(), &request); err != nil {
+		t.Fatal(err)
+	}
+
+	resourceSpans := request.ResourceSpans[0]
+	exported := resourceSpans.ScopeSpans[0].Spans[0]
+	if resourceSpans.Resource.Attributes[0].Value.StringValue != "game" ||
+		exported.TraceID != span.Context.TraceIDString() ||
+		exported.Name != "invoke login" ||
+		exported.Attributes[0].Value.StringValue != "game-1.player" {
+		t.Fatalf("exported = %s", scanner.Text())
+	}
+}